@@ -4,10 +4,16 @@ type CreateGroup struct {
 	Name        string `json:"name" form:"name"`
 	Description string `json:"description" form:"description"`
 	OnlyAdmin   bool   `json:"onlyAdmin" form:"onlyAdmin"`
+	// ISO 4217 code, defaults to EUR
+	Currency string `json:"currency" form:"currency"`
+	// IANA time zone of payment plans (e.g. "Europe/Berlin"), defaults to UTC
+	TimeZone string `json:"timeZone" form:"timeZone"`
 }
 
 type UpdateGroup struct {
 	Description string `json:"description" from:"description"`
+	// IANA time zone of payment plans, unchanged if empty
+	TimeZone string `json:"timeZone" form:"timeZone"`
 }
 
 type CreateTransaction struct {
@@ -18,6 +24,19 @@ type CreateTransaction struct {
 	FromBank    bool   `json:"fromBank" form:"fromBank"`
 }
 
+type CreateCashTransaction struct {
+	// "payout" (member → bank) or "deposit" (bank → member)
+	Type        string `json:"type" form:"type"`
+	Title       string `json:"title" form:"title"`
+	Description string `json:"description" form:"description"`
+	// Member whose balance and cash change, defaults to the authenticated user. Only admins can specify other members.
+	UserId string `json:"userId" form:"userId"`
+	// Cash wallet of the member, empty for the default wallet
+	Wallet string `json:"wallet" form:"wallet"`
+	// Counts by denomination key of the currency of the group
+	Denominations map[string]uint `json:"denominations" form:"denominations"`
+}
+
 type CreatePaymentPlan struct {
 	Name         string `json:"name" form:"name"`
 	Description  string `json:"description" form:"description"`
@@ -26,23 +45,90 @@ type CreatePaymentPlan struct {
 	FromBank     bool   `json:"fromBank" form:"fromBank"`
 	Schedule     uint   `json:"schedule" form:"schedule"`
 	ScheduleUnit string `json:"scheduleUnit" form:"scheduleUnit"`
-	// UTC date of first payment with format "YYYY-MM-DD"
+	// Date of first payment in the time zone of the group with format "YYYY-MM-DD"
 	FirstPayment string `json:"firstPayment"`
+	// Local time of the payments with format "HH:MM", defaults to "00:00"
+	TimeOfDay string `json:"timeOfDay"`
 	// negative payment count for unlimited payments
 	PaymentCount int `json:"paymentCount"`
+	// retry (default), skip or pause
+	FailurePolicy string `json:"failurePolicy" form:"failurePolicy"`
+	// date (default), lastDay, weekday or lastWeekday (only for monthly and yearly plans)
+	ScheduleRule string `json:"scheduleRule" form:"scheduleRule"`
+	WeekdaysOnly bool   `json:"weekdaysOnly" form:"weekdaysOnly"`
+	// Date of last possible payment with format "YYYY-MM-DD", empty for no end date
+	EndDate string `json:"endDate"`
 }
 
 type UpdatePaymentPlan struct {
 	Name        string `json:"name" form:"name"`
 	Description string `json:"description" form:"description"`
 	Amount      uint   `json:"amount" form:"amount"`
-	// UTC date of next payment with format "YYYY-MM-DD"
-	NextPayment  string `json:"nextPayment"`
+	// Date of next payment in the time zone of the payment plan with format "YYYY-MM-DD"
+	NextPayment string `json:"nextPayment"`
+	// Local time of the payments with format "HH:MM", defaults to "00:00"
+	TimeOfDay    string `json:"timeOfDay"`
 	Schedule     uint   `json:"schedule" form:"schedule"`
 	ScheduleUnit string `json:"scheduleUnit" form:"scheduleUnit"`
+	// retry, skip or pause, unchanged if empty
+	FailurePolicy string `json:"failurePolicy" form:"failurePolicy"`
+	// date (default), lastDay, weekday or lastWeekday (only for monthly and yearly plans)
+	ScheduleRule string `json:"scheduleRule" form:"scheduleRule"`
+	WeekdaysOnly bool   `json:"weekdaysOnly" form:"weekdaysOnly"`
+	// Date of last possible payment with format "YYYY-MM-DD", empty for no end date
+	EndDate string `json:"endDate"`
+}
+
+type ResumePaymentPlan struct {
+	// Pay all payments which were missed while the plan was paused instead of continuing with the next future payment
+	CatchUp bool `json:"catchUp" form:"catchUp"`
+}
+
+type SkipPaymentPlan struct {
+	// Number of payments to skip, defaults to 1
+	Count uint `json:"count" form:"count"`
 }
 
 type CreateInvitation struct {
 	Message string `json:"message" form:"message"`
 	UserId  string `json:"userId" form:"userId"`
 }
+
+type CreatePaymentRequest struct {
+	Title       string `json:"title" form:"title"`
+	Description string `json:"description" form:"description"`
+	Amount      uint   `json:"amount" form:"amount"`
+	PayerId     string `json:"payerId" form:"payerId"`
+}
+
+type ApprovalPolicy struct {
+	Threshold     uint `json:"threshold" form:"threshold"`
+	BankTransfers bool `json:"bankTransfers" form:"bankTransfers"`
+}
+
+type SpendingLimits struct {
+	MaxPerTransaction uint `json:"maxPerTransaction" form:"maxPerTransaction"`
+	MaxPerDay         uint `json:"maxPerDay" form:"maxPerDay"`
+	MaxPerWeek        uint `json:"maxPerWeek" form:"maxPerWeek"`
+	MaxPerMonth       uint `json:"maxPerMonth" form:"maxPerMonth"`
+	Overdraft         uint `json:"overdraft" form:"overdraft"`
+}
+
+type Interest struct {
+	// Annual interest rate in percent
+	AnnualRate float64 `json:"annualRate" form:"annualRate"`
+	// "day", "week", "month" or "year"
+	Period     string `json:"period" form:"period"`
+	MinBalance uint   `json:"minBalance" form:"minBalance"`
+}
+
+type SavingsGoal struct {
+	Name   string `json:"name" form:"name"`
+	Target uint   `json:"target" form:"target"`
+	// UTC date with format "YYYY-MM-DD", empty for goals without a deadline
+	Deadline string `json:"deadline" form:"deadline"`
+}
+
+type SavingsTransfer struct {
+	Amount uint `json:"amount" form:"amount"`
+}
@@ -1,19 +1,17 @@
 package bindings
 
-type DeleteUser struct {
-	Password   string `json:"password" form:"password"`
-	TwoFAToken string `json:"twoFAToken" form:"twoFAToken"`
-}
-
 type UpdateUser struct {
 	PubliclyVisible         bool `json:"publiclyVisible" form:"publiclyVisible"`
 	DontSendInvitationEmail bool `json:"dontSendInvitationEmail" form:"dontSendInvitationEmail"`
+	// ISO 4217 code, empty to keep the current currency
+	CashCurrency string `json:"cashCurrency" form:"cashCurrency"`
 }
 
 type AddCashLogEntry struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 
+	// EUR counts of the v1 API, added to the counts in Denominations
 	Ct1    uint `json:"ct1"`
 	Ct2    uint `json:"ct2"`
 	Ct5    uint `json:"ct5"`
@@ -29,6 +27,30 @@ type AddCashLogEntry struct {
 	Eur100 uint `json:"eur100"`
 	Eur200 uint `json:"eur200"`
 	Eur500 uint `json:"eur500"`
+
+	// Counts by denomination key (e.g. "chf5") of the currency of the cash wallet
+	Denominations map[string]uint `json:"denominations"`
+
+	// Id of an entry of the same wallet which is too old to be changed and is corrected by the new entry.
+	// Ignored when updating an entry.
+	CorrectionOfId string `json:"correctionOfId"`
+}
+
+type CashWallet struct {
+	Name string `json:"name"`
+	// ISO 4217 code, defaults to the currency of the default wallet. Ignored on update.
+	Currency string `json:"currency"`
+}
+
+type MoveCash struct {
+	// Wallet ids, empty for the default wallet
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	// Counts by denomination key of the currency of both wallets
+	Denominations map[string]uint `json:"denominations"`
 }
 
 type Id struct {
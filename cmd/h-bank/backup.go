@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/juho05/h-bank/db"
+)
+
+// h-bank backup <file|->
+func runBackup(args []string) error {
+	if len(args) != 1 {
+		return errors.New("Usage: h-bank backup <file|->")
+	}
+
+	database, sqlDB, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	var out io.Writer = os.Stdout
+	if args[0] != "-" {
+		// O_EXCL: never overwrite an existing backup
+		file, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return fmt.Errorf("Couldn't create backup file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	log.Println("[backup] Creating backup...")
+	manifest, err := db.Backup(database, out, func(table string, rows int64) {
+		log.Printf("[backup] %s: %d rows", table, rows)
+	})
+	if err != nil {
+		if args[0] != "-" {
+			os.Remove(args[0])
+		}
+		return fmt.Errorf("Couldn't create backup: %w", err)
+	}
+
+	var total int64
+	for _, t := range manifest.Tables {
+		total += t.Rows
+	}
+	log.Printf("[backup] Done. Saved %d rows of %d tables.", total, len(manifest.Tables))
+	return nil
+}
+
+// h-bank restore <file|->
+func runRestore(args []string) error {
+	if len(args) != 1 {
+		return errors.New("Usage: h-bank restore <file|->")
+	}
+
+	var in io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("Couldn't open backup file: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	database, sqlDB, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	log.Println("[restore] Restoring backup...")
+	manifest, err := db.Restore(database, in, func(table string, rows int64) {
+		log.Printf("[restore] %s: %d rows", table, rows)
+	})
+	if err != nil {
+		return fmt.Errorf("Couldn't restore backup: %w", err)
+	}
+
+	var total int64
+	for _, t := range manifest.Tables {
+		total += t.Rows
+	}
+	log.Printf("[restore] Done. Restored %d rows of %d tables.", total, len(manifest.Tables))
+	return nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+
+	"github.com/juho05/h-bank/models"
+)
+
+func checkBalances(gs models.GroupStore) {
+	log.Println("[balances] Checking member balances...")
+	drifts, err := gs.GetBalanceDrifts(nil)
+	if err != nil {
+		log.Println("[balances] ERROR: Couldn't check member balances:", err)
+		return
+	}
+
+	for _, d := range drifts {
+		log.Printf("[balances] WARNING: Balance of user '%s' in group '%s' is %d but the transaction log adds up to %d", d.UserId, d.GroupId, d.Balance, d.LogBalance)
+	}
+
+	log.Printf("[balances] Done. Found %d inconsistent balances.", len(drifts))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/juho05/h-bank/models"
+)
+
+var StopInterestTicker = make(chan struct{})
+
+func StartInterestTicker(gs models.GroupStore) {
+	log.Println("[interest] Starting ticker...")
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		for {
+			payInterest(gs)
+			select {
+			case <-ticker.C:
+				continue
+			case <-StopInterestTicker:
+				log.Println("[interest] Stopping ticker...")
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func payInterest(gs models.GroupStore) {
+	groups, err := gs.GetGroupsWithDueInterest()
+	if err != nil {
+		log.Println("[interest] ERROR: Couldn't retrieve groups:", err)
+		return
+	}
+	if len(groups) == 0 {
+		return
+	}
+
+	log.Printf("[interest] Paying interest in %d groups...", len(groups))
+
+	for _, g := range groups {
+		periods, err := gs.PayInterest(&g)
+		if err != nil {
+			log.Printf("[interest] ERROR: Couldn't pay interest in group with id '%s': %s", g.Id, err)
+			continue
+		}
+		if periods > 1 {
+			log.Printf("[interest] Caught up on %d periods in group with id '%s'", periods, g.Id)
+		}
+	}
+
+	log.Println("[interest] Done.")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,6 +10,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// payment plans are scheduled in IANA time zones, which are missing in the alpine image
+	_ "time/tzdata"
 
 	"github.com/adrg/xdg"
 	"github.com/juho05/oidc-client/oidc"
@@ -24,41 +27,71 @@ import (
 	"github.com/juho05/h-bank/services"
 )
 
-func run(r *echo.Echo) error {
+// Connects to the configured database and applies all pending migrations.
+// The returned database needs to be closed by the caller.
+func openDatabase() (*gorm.DB, *sql.DB, error) {
+	return openDatabaseEngine(config.Data.DBEngine, true)
+}
+
+// Connects to the database of the engine with the connection details in the config.
+// Databases with a newer schema than this binary knows are rejected even if migrate is false.
+func openDatabaseEngine(engine config.DBEngine, migrate bool) (*gorm.DB, *sql.DB, error) {
 	var database *gorm.DB
 	var err error
-	switch config.Data.DBEngine {
+	switch engine {
 	case config.DBSqlite:
 		database, err = db.NewSqlite(config.Data.DBPath)
 	case config.DBPostgres:
 		database, err = db.NewPostgres(fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s", config.Data.DBHost, config.Data.DBPort, config.Data.DBUser, config.Data.DBPassword, config.Data.DBName))
+	default:
+		err = fmt.Errorf("unknown engine '%s'", engine)
 	}
 	if err != nil {
-		return fmt.Errorf("Couldn't connect to database: %w", err)
+		return nil, nil, fmt.Errorf("Couldn't connect to database: %w", err)
 	}
 	sqlDB, err := database.DB()
 	if err != nil {
-		return fmt.Errorf("Failed to get generic SQL interface: %w", err)
+		return nil, nil, fmt.Errorf("Failed to get generic SQL interface: %w", err)
 	}
-	defer sqlDB.Close()
-	if config.Data.DBEngine == config.DBSqlite {
+	if engine == config.DBSqlite {
 		_, err = sqlDB.Exec("PRAGMA journal_mode = WAL")
 		if err != nil {
-			return fmt.Errorf("Failed to enable WAL mode: %w", err)
+			sqlDB.Close()
+			return nil, nil, fmt.Errorf("Failed to enable WAL mode: %w", err)
 		}
 		_, err = sqlDB.Exec("PRAGMA foreign_keys = 1")
 		if err != nil {
-			return fmt.Errorf("Failed to enable foreign keys: %w", err)
+			sqlDB.Close()
+			return nil, nil, fmt.Errorf("Failed to enable foreign keys: %w", err)
+		}
+	}
+	err = db.CheckSchemaVersion(database)
+	if err != nil {
+		sqlDB.Close()
+		return nil, nil, err
+	}
+	if migrate {
+		err = db.Migrate(database)
+		if err != nil {
+			sqlDB.Close()
+			return nil, nil, fmt.Errorf("Couldn't migrate database: %w", err)
 		}
 	}
-	err = db.AutoMigrate(database)
+	return database, sqlDB, nil
+}
+
+func run(r *echo.Echo) error {
+	database, sqlDB, err := openDatabase()
 	if err != nil {
-		return fmt.Errorf("Couldn't auto migrate database: %w", err)
+		return err
 	}
+	defer sqlDB.Close()
 
 	us := db.NewUserStore(database)
 	gs := db.NewGroupStore(database)
 
+	go checkBalances(gs)
+
 	oidcClient, err := oidc.NewClient(config.Data.InternalIDProvider, oidc.ClientConfig{
 		ClientID:     config.Data.ClientID,
 		ClientSecret: config.Data.ClientSecret,
@@ -91,11 +124,13 @@ func run(r *echo.Echo) error {
 	log.Printf("Listening on port %d", config.Data.ServerPort)
 
 	StartPaymentPlanTicker(us, gs)
+	StartInterestTicker(gs)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	close(StopPaymentPlanTicker)
+	close(StopInterestTicker)
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := r.Shutdown(ctx); err != nil {
@@ -109,6 +144,26 @@ func main() {
 	config.Load([]string{"config.json", xdg.ConfigHome + "/h-bank/config.json"})
 	services.LoadTranslations()
 
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "backup":
+			err = runBackup(os.Args[2:])
+		case "restore":
+			err = runRestore(os.Args[2:])
+		case "sqlite-to-postgres":
+			err = runSqliteToPostgres(os.Args[2:])
+		case "migrate":
+			err = runMigrate(os.Args[2:])
+		default:
+			err = fmt.Errorf("Unknown command '%s'. Available commands: backup, restore, sqlite-to-postgres, migrate", os.Args[1])
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	services.EmailAuthenticate()
 
 	r := router.New()
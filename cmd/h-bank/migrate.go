@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/juho05/h-bank/config"
+	"github.com/juho05/h-bank/db"
+)
+
+const migrateUsage = "Usage: h-bank migrate status|up [version]|down <version>"
+
+// h-bank migrate status|up [version]|down <version>
+func runMigrate(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New(migrateUsage)
+	}
+
+	database, sqlDB, err := openDatabaseEngine(config.Data.DBEngine, false)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	current, err := db.SchemaVersion(database)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		status, err := db.GetMigrationStatus(database)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Schema version: %d (latest: %d)\n", current, db.LatestSchemaVersion())
+		for _, s := range status {
+			applied := "pending"
+			if s.Applied != 0 {
+				applied = "applied " + time.Unix(s.Applied, 0).Format(time.RFC3339)
+			}
+			fmt.Printf("%4d  %-40s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	case "up":
+		version := db.LatestSchemaVersion()
+		if len(args) == 2 {
+			version, err = strconv.Atoi(args[1])
+			if err != nil {
+				return errors.New(migrateUsage)
+			}
+			if version < current {
+				return fmt.Errorf("Schema version %d is already applied, use 'h-bank migrate down %d' to revert", current, version)
+			}
+		}
+		err = db.MigrateTo(database, version)
+	case "down":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		var version int
+		version, err = strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New(migrateUsage)
+		}
+		if version > current {
+			return fmt.Errorf("Schema version %d is lower than %d, use 'h-bank migrate up %d' to apply migrations", current, version, version)
+		}
+		err = db.MigrateTo(database, version)
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err = db.SchemaVersion(database)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema version: %d\n", current)
+	return nil
+}
@@ -1,27 +1,61 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"os"
 	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/juho05/h-bank/config"
 	"github.com/juho05/h-bank/models"
 	"github.com/juho05/h-bank/services"
 )
 
 var StopPaymentPlanTicker = make(chan struct{})
 
+const (
+	paymentPlanLease = "payment-plans"
+	// Only the holder of the lease executes payment plans. It is renewed on every tick,
+	// so another instance takes over once the holder has been gone for paymentPlanLeaseDuration.
+	paymentPlanLeaseDuration = 15 * time.Minute
+	paymentPlanTickInterval  = 5 * time.Minute
+)
+
+// Identifies this process as the holder of leases.
+var leaseHolder = newLeaseHolder()
+
+func newLeaseHolder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString())
+}
+
 func StartPaymentPlanTicker(us models.UserStore, gs models.GroupStore) {
 	log.Println("[payment-plans] Starting ticker...")
-	ticker := time.NewTicker(time.Hour)
+	ticker := time.NewTicker(paymentPlanTickInterval)
 	go func() {
 		for {
-			executePaymentPlans(us, gs)
+			acquired, err := gs.AcquireLease(paymentPlanLease, leaseHolder, paymentPlanLeaseDuration)
+			if err != nil {
+				log.Println("[payment-plans] ERROR: Couldn't acquire lease:", err)
+			} else if acquired {
+				executePaymentPlans(us, gs)
+			}
 			select {
 			case <-ticker.C:
 				continue
 			case <-StopPaymentPlanTicker:
 				log.Println("[payment-plans] Stopping ticker...")
 				ticker.Stop()
+				err = gs.ReleaseLease(paymentPlanLease, leaseHolder)
+				if err != nil {
+					log.Println("[payment-plans] ERROR: Couldn't release lease:", err)
+				}
 				return
 			}
 		}
@@ -34,6 +68,9 @@ func executePaymentPlans(us models.UserStore, gs models.GroupStore) {
 		log.Println("[payment-plans] ERROR: Couldn't retrieve payment plans:", err)
 		return
 	}
+	if len(paymentPlans) == 0 {
+		return
+	}
 
 	log.Printf("[payment-plans] Executing %d payment plans...", len(paymentPlans))
 
@@ -47,56 +84,113 @@ func executePaymentPlans(us models.UserStore, gs models.GroupStore) {
 	log.Println("[payment-plans] Done.")
 }
 
+// Pays all due occurrences of the payment plan. Every occurrence is paid in its own database transaction
+// together with the update of the schedule, so occurrences are never paid twice.
+// Failed occurrences are recorded and handled according to the failure policy of the payment plan.
 func executePaymentPlan(userStore models.UserStore, groupStore models.GroupStore, paymentPlan *models.PaymentPlan) error {
-	for paymentPlan.NextExecute <= time.Now().Unix() {
-		group, err := groupStore.GetById(paymentPlan.GroupId)
-		if err != nil {
-			return err
+	for !paymentPlan.Paused && !paymentPlan.Finished() && paymentPlan.NextExecute <= time.Now().Unix() {
+		_, err := groupStore.ExecutePaymentPlan(paymentPlan)
+		if errors.Is(err, models.ErrGroupArchived) || errors.Is(err, models.ErrPaymentPlanAlreadyExecuted) || errors.Is(err, models.ErrPaymentPlanPaused) {
+			return nil
 		}
-		if group == nil {
-			return groupStore.Delete(group)
+		if err == nil {
+			continue
 		}
 
-		sender, err := userStore.GetById(paymentPlan.SenderId)
-		if err != nil {
-			return err
+		status := models.PaymentPlanExecutionError
+		if errors.Is(err, models.ErrNotEnoughMoney) {
+			status = models.PaymentPlanExecutionInsufficientFunds
 		}
-
-		receiver, err := userStore.GetById(paymentPlan.ReceiverId)
-		if err != nil {
-			return err
+		execution, recordErr := groupStore.RecordPaymentPlanFailure(paymentPlan, status, err.Error())
+		if errors.Is(recordErr, models.ErrPaymentPlanAlreadyExecuted) {
+			return nil
+		}
+		if recordErr != nil {
+			return fmt.Errorf("record failure (%s): %w", err, recordErr)
 		}
+		log.Printf("[payment-plans] Payment plan with id '%s' failed (attempt %d, policy %s): %s", paymentPlan.Id, execution.Attempts, paymentPlan.FailurePolicy, err)
 
-		if !paymentPlan.SenderIsBank {
-			balance, err := groupStore.GetUserBalance(group, sender)
+		// retries don't send the same notification every tick
+		if execution.Attempts == 1 {
+			err = notifyPaymentPlanFailed(userStore, groupStore, paymentPlan, err)
 			if err != nil {
-				return err
-			}
-			if balance-paymentPlan.Amount < 0 {
-				break
+				log.Printf("[payment-plans] ERROR: Couldn't send failure notification for payment plan with id '%s': %s", paymentPlan.Id, err)
 			}
 		}
 
-		_, err = groupStore.CreateTransactionFromPaymentPlan(group, paymentPlan.SenderIsBank, paymentPlan.ReceiverIsBank, sender, receiver, paymentPlan.Name, paymentPlan.Description, paymentPlan.Amount, paymentPlan.Id)
-		if err != nil {
-			return err
+		if paymentPlan.FailurePolicy != models.PaymentPlanFailureSkip {
+			return nil
 		}
+	}
 
-		paymentPlan.NextExecute = services.AddTime(paymentPlan.NextExecute, paymentPlan.Schedule, paymentPlan.ScheduleUnit)
+	return nil
+}
 
-		if paymentPlan.PaymentCount >= 0 {
-			paymentPlan.PaymentCount -= 1
+// Sends an email to the sender of the payment plan and all admins of the group.
+func notifyPaymentPlanFailed(userStore models.UserStore, groupStore models.GroupStore, paymentPlan *models.PaymentPlan, reason error) error {
+	if !config.Data.EmailEnabled {
+		return nil
+	}
 
-			if paymentPlan.PaymentCount <= 0 {
-				return groupStore.DeletePaymentPlan(paymentPlan)
-			}
-		}
+	group, err := groupStore.GetById(paymentPlan.GroupId)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return nil
+	}
 
-		err = groupStore.UpdatePaymentPlan(paymentPlan)
+	recipients, err := groupStore.GetAdmins(nil, "", group, -1, -1, false)
+	if err != nil {
+		return err
+	}
+	if !paymentPlan.SenderIsBank {
+		sender, err := userStore.GetById(paymentPlan.SenderId)
 		if err != nil {
 			return err
 		}
+		if sender != nil {
+			recipients = append(recipients, *sender)
+		}
 	}
 
+	lang := config.Data.EmailLanguage
+	message := services.Tr("An unexpected error occured", lang)
+	var limitErr *models.SpendingLimitError
+	if errors.Is(reason, models.ErrNotEnoughMoney) {
+		message = services.Tr("Not enough money", lang)
+	} else if errors.As(reason, &limitErr) {
+		message = fmt.Sprintf(services.Tr("The spending limit of %s was exceeded", lang), services.FormatMoney(limitErr.Max, group.Currency, lang))
+	} else if errors.Is(reason, models.ErrSpendingLimitExceeded) {
+		message = services.Tr("The spending limit was exceeded", lang)
+	}
+
+	type templateData struct {
+		Name           string
+		PlanName       string
+		GroupName      string
+		Amount         string
+		Reason         string
+		PaymentPlanUrl string
+	}
+	notified := make(map[string]bool, len(recipients))
+	for _, r := range recipients {
+		if notified[r.Id] {
+			continue
+		}
+		notified[r.Id] = true
+		body, err := services.ParseEmailTemplate("paymentPlanFailed", lang, templateData{
+			Name:           r.Name,
+			PlanName:       paymentPlan.Name,
+			GroupName:      group.Name,
+			Amount:         services.FormatMoney(paymentPlan.Amount, group.Currency, lang),
+			Reason:         message,
+			PaymentPlanUrl: fmt.Sprintf("%s/group/%s/payment-plan/%s", config.Data.BaseURL, group.Id, paymentPlan.Id),
+		})
+		if err != nil {
+			return err
+		}
+		go services.SendEmail([]string{r.Email}, services.Tr("Payment plan failed", lang), body)
+	}
 	return nil
 }
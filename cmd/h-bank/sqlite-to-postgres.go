@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/juho05/h-bank/config"
+	"github.com/juho05/h-bank/db"
+)
+
+// Copies all data from the SQLite database at dbPath into the Postgres database configured with dbHost, dbPort, etc.
+// h-bank sqlite-to-postgres
+func runSqliteToPostgres(args []string) error {
+	if len(args) != 0 {
+		return errors.New("Usage: h-bank sqlite-to-postgres")
+	}
+
+	log.Printf("[sqlite-to-postgres] Opening SQLite database '%s'...", config.Data.DBPath)
+	src, srcSQL, err := openDatabaseEngine(config.DBSqlite, true)
+	if err != nil {
+		return err
+	}
+	defer srcSQL.Close()
+
+	log.Printf("[sqlite-to-postgres] Opening Postgres database '%s' on %s:%d...", config.Data.DBName, config.Data.DBHost, config.Data.DBPort)
+	dst, dstSQL, err := openDatabaseEngine(config.DBPostgres, true)
+	if err != nil {
+		return err
+	}
+	defer dstSQL.Close()
+
+	log.Println("[sqlite-to-postgres] Copying tables...")
+	err = db.CopyDatabase(src, dst, func(table string, rows, total int64) {
+		log.Printf("[sqlite-to-postgres] %s: %d/%d rows", table, rows, total)
+	})
+	if err != nil {
+		return fmt.Errorf("Couldn't copy database: %w", err)
+	}
+
+	log.Println("[sqlite-to-postgres] Verifying row counts and balances...")
+	err = db.VerifyCopy(src, dst)
+	if err != nil {
+		return fmt.Errorf("Verification failed: %w", err)
+	}
+
+	log.Println("[sqlite-to-postgres] Done. Set 'dbEngine' to 'postgres' in your config to use the new database.")
+	return nil
+}
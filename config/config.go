@@ -36,12 +36,15 @@ type ConfigData struct {
 	EmailPort                 int      `json:"emailPort"`
 	EmailUsername             string   `json:"emailUsername"`
 	EmailPassword             string   `json:"emailPassword"`
+	EmailLanguage             string   `json:"emailLanguage"`
 	MinNameLength             int      `json:"minNameLength"`
 	MaxNameLength             int      `json:"maxNameLength"`
 	MinDescriptionLength      int      `json:"minDescriptionLength"`
 	MaxDescriptionLength      int      `json:"maxDescriptionLength"`
 	MaxProfilePictureFileSize int64    `json:"maxProfilePictureFileSize"`
 	MaxPageSize               int      `json:"maxPageSize"`
+	EditableCashLogEntries    int      `json:"editableCashLogEntries"`
+	MaxImportFileSize         int64    `json:"maxImportFileSize"`
 	IDProvider                string   `json:"idProvider"`
 	InternalIDProvider        string `json:"internalIDProvider"`
 	ClientID                  string   `json:"clientID"`
@@ -52,6 +55,7 @@ type ConfigData struct {
 
 var defaultData = ConfigData{
 	ServerPort:                80,
+	EmailLanguage:             "en",
 	BaseURL:                   "",
 	DBPath:                    "database.sqlite",
 	MinNameLength:             3,
@@ -60,6 +64,8 @@ var defaultData = ConfigData{
 	MaxDescriptionLength:      256,
 	MaxProfilePictureFileSize: 10000000, // 10 MB
 	MaxPageSize:               100,
+	EditableCashLogEntries:    5,
+	MaxImportFileSize:         1000000, // 1 MB
 	IDProvider:                "",
 }
 
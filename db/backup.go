@@ -0,0 +1,413 @@
+package db
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/juho05/h-bank/models"
+)
+
+// Version of the backup archive format. Archives with a newer version cannot be restored.
+const BackupVersion = 1
+
+const backupFormat = "h-bank-backup"
+
+// All models in an order in which rows can be inserted without violating foreign keys.
+var backupModels = []interface{}{
+	&models.User{},
+	&models.CashWallet{},
+	&models.CashLogEntry{},
+	&models.CashDenominationCount{},
+	&models.CashLogEntryRevision{},
+
+	&models.Group{},
+	&models.GroupPicture{},
+	&models.GroupMembership{},
+	&models.GroupInvitation{},
+	&models.SavingsGoal{},
+	&models.PaymentPlan{},
+	&models.PaymentRequest{},
+	&models.PendingTransaction{},
+	&models.TransactionLogEntry{},
+	&models.PaymentPlanExecution{},
+}
+
+type BackupManifest struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	Created int64  `json:"created"`
+	// Schema version of the database the backup was created from
+	SchemaVersion int           `json:"schemaVersion"`
+	Tables        []BackupTable `json:"tables"`
+}
+
+type BackupTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// Writes all rows of all tables as a gzip compressed tar archive to w.
+// The archive contains manifest.json and one file with one JSON object per line for every table.
+func Backup(db *gorm.DB, w io.Writer, progress func(table string, rows int64)) (*BackupManifest, error) {
+	schemaVersion, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BackupManifest{
+		Format:        backupFormat,
+		Version:       BackupVersion,
+		Created:       time.Now().Unix(),
+		SchemaVersion: schemaVersion,
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	// all tables are read in one transaction to get a consistent snapshot
+	options := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	if db.Dialector.Name() != "postgres" {
+		// read-only transactions start with a deferred BEGIN instead of BEGIN IMMEDIATE, so the backup doesn't hold
+		// the write lock of the live server. Readers of a WAL database see a consistent snapshot.
+		options = &sql.TxOptions{ReadOnly: true}
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range backupModels {
+			name, err := tableName(tx, model)
+			if err != nil {
+				return err
+			}
+			var count int64
+			err = tx.Model(model).Count(&count).Error
+			if err != nil {
+				return fmt.Errorf("count rows of table '%s': %w", name, err)
+			}
+			manifest.Tables = append(manifest.Tables, BackupTable{Name: name, Rows: count})
+		}
+
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(archive, "manifest.json", data, manifest.Created)
+		if err != nil {
+			return err
+		}
+
+		for i, model := range backupModels {
+			name := manifest.Tables[i].Name
+			err = backupTable(tx, archive, model, name, manifest.Created, progress)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = gz.Close()
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Writes all rows of the table of model as name.jsonl to archive.
+// The size of a file in a tar archive must be known before it is written, so the rows are spooled
+// to a temporary file first instead of keeping the whole table in memory.
+func backupTable(tx *gorm.DB, archive *tar.Writer, model interface{}, name string, modTime int64, progress func(table string, rows int64)) error {
+	file, err := os.CreateTemp("", "h-bank-backup-*.jsonl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	var count int64
+	rows := newModelSlice(model)
+	err = tx.Model(model).FindInBatches(rows.Interface(), 500, func(tx *gorm.DB, batch int) error {
+		for i := 0; i < rows.Elem().Len(); i++ {
+			err := encoder.Encode(rows.Elem().Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+		count += int64(rows.Elem().Len())
+		if progress != nil {
+			progress(name, count)
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return fmt.Errorf("read table '%s': %w", name, err)
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = archive.WriteHeader(&tar.Header{
+		Name:    name + ".jsonl",
+		Mode:    0o600,
+		Size:    size,
+		ModTime: time.Unix(modTime, 0),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(archive, file)
+	return err
+}
+
+// Inserts all rows of a backup archive created by Backup into db.
+// The schema must be up to date and all tables must be empty.
+// Backups of older schemas are restored into the schema they were created with and migrated afterwards,
+// so that the data migrations in between are applied.
+func Restore(db *gorm.DB, r io.Reader, progress func(table string, rows int64)) (*BackupManifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer gz.Close()
+	archive := tar.NewReader(gz)
+
+	header, err := archive.Next()
+	if err != nil || header.Name != "manifest.json" {
+		return nil, errors.New("invalid backup archive: missing manifest.json")
+	}
+	var manifest BackupManifest
+	err = json.NewDecoder(archive).Decode(&manifest)
+	if err != nil || manifest.Format != backupFormat {
+		return nil, errors.New("invalid backup archive: invalid manifest.json")
+	}
+	if manifest.Version > BackupVersion {
+		return nil, fmt.Errorf("backup archive version %d is newer than the supported version %d", manifest.Version, BackupVersion)
+	}
+	if manifest.SchemaVersion > LatestSchemaVersion() {
+		return nil, fmt.Errorf("%w (backup: %d > %d)", ErrSchemaTooNew, manifest.SchemaVersion, LatestSchemaVersion())
+	}
+	// backups created before versioned migrations were introduced have the initial schema
+	schemaVersion := manifest.SchemaVersion
+	if schemaVersion < 1 {
+		schemaVersion = 1
+	}
+
+	tableModels := make(map[string]interface{}, len(backupModels))
+	for _, model := range backupModels {
+		name, err := tableName(db, model)
+		if err != nil {
+			return nil, err
+		}
+		tableModels[name] = model
+	}
+
+	// reverting migrations may drop data, so the tables are checked before
+	err = checkTablesEmpty(db)
+	if err != nil {
+		return nil, err
+	}
+	if schemaVersion < LatestSchemaVersion() {
+		err = MigrateTo(db, schemaVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := checkTablesEmpty(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range manifest.Tables {
+			header, err := archive.Next()
+			if err != nil {
+				return fmt.Errorf("invalid backup archive: %w", err)
+			}
+			if header.Name != table.Name+".jsonl" {
+				return fmt.Errorf("invalid backup archive: expected '%s.jsonl', found '%s'", table.Name, header.Name)
+			}
+			model, ok := tableModels[table.Name]
+			if !ok {
+				return fmt.Errorf("invalid backup archive: unknown table '%s'", table.Name)
+			}
+
+			count, err := restoreTable(tx, model, archive, func(rows int64) {
+				if progress != nil {
+					progress(table.Name, rows)
+				}
+			})
+			if err != nil {
+				return fmt.Errorf("restore table '%s': %w", table.Name, err)
+			}
+			if count != table.Rows {
+				return fmt.Errorf("invalid backup archive: expected %d rows in table '%s', found %d", table.Rows, table.Name, count)
+			}
+		}
+		return nil
+	})
+	// the schema is migrated back to the latest version even if the restore failed
+	migrateErr := Migrate(db)
+	if err != nil {
+		return nil, err
+	}
+	if migrateErr != nil {
+		return nil, fmt.Errorf("migrate restored data: %w", migrateErr)
+	}
+	return &manifest, nil
+}
+
+func restoreTable(tx *gorm.DB, model interface{}, r io.Reader, progress func(rows int64)) (int64, error) {
+	const batchSize = 100
+
+	stmt := &gorm.Statement{DB: tx}
+	err := stmt.Parse(model)
+	if err != nil {
+		return 0, err
+	}
+
+	// the schema of older backups doesn't contain the columns which were added later
+	columnTypes, err := tx.Migrator().ColumnTypes(model)
+	if err != nil {
+		return 0, err
+	}
+	columns := make([]string, 0, len(columnTypes))
+	for _, c := range columnTypes {
+		if _, ok := stmt.Schema.FieldsByDBName[c.Name()]; ok {
+			columns = append(columns, c.Name())
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	// group pictures are stored in the rows
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	var count int64
+	rows := newModelSlice(model).Elem()
+	insert := func() error {
+		if rows.Len() == 0 {
+			return nil
+		}
+		err := insertRows(tx, stmt.Schema, columns, rows)
+		if err != nil {
+			return err
+		}
+		count += int64(rows.Len())
+		progress(count)
+		rows.SetLen(0)
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		row := reflect.New(rows.Type().Elem())
+		err := json.Unmarshal(line, row.Interface())
+		if err != nil {
+			return count, err
+		}
+		rows.Set(reflect.Append(rows, row.Elem()))
+		if rows.Len() >= batchSize {
+			err = insert()
+			if err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return count, err
+	}
+	return count, insert()
+}
+
+// Inserts a slice of models with the given columns as they are.
+// Maps are inserted instead of the models because gorm replaces zero values with column defaults
+// and the hooks would overwrite ids.
+func insertRows(tx *gorm.DB, s *schema.Schema, columns []string, rows reflect.Value) error {
+	values := make([]map[string]interface{}, rows.Len())
+	for i := range values {
+		values[i] = make(map[string]interface{}, len(columns))
+		for _, name := range columns {
+			values[i][name], _ = s.FieldsByDBName[name].ValueOf(tx.Statement.Context, rows.Index(i))
+		}
+	}
+	return tx.Table(s.Table).Create(&values).Error
+}
+
+// Tables which don't exist in the current schema are skipped.
+func checkTablesEmpty(tx *gorm.DB) error {
+	for _, model := range backupModels {
+		if !tx.Migrator().HasTable(model) {
+			continue
+		}
+		var count int64
+		err := tx.Model(model).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			name, _ := tableName(tx, model)
+			return fmt.Errorf("table '%s' is not empty", name)
+		}
+	}
+	return nil
+}
+
+func writeArchiveFile(archive *tar.Writer, name string, data []byte, modTime int64) error {
+	err := archive.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: time.Unix(modTime, 0),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = archive.Write(data)
+	return err
+}
+
+// Returns a pointer to an empty slice of the type of model.
+func newModelSlice(model interface{}) reflect.Value {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(model).Elem()))
+}
+
+func tableName(db *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	err := stmt.Parse(model)
+	if err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juho05/h-bank/models"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+	// false is not the column default
+	user.PubliclyVisible = false
+	us.Update(user)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, user)
+	gs.UpdateGroupPicture(group, &models.GroupPicture{Tiny: []byte{1, 2, 3}})
+	gs.CreateTransaction(group, true, false, nil, user, "Pocket money", "", 300)
+
+	us.AddCashLogEntry(user, &models.CashLogEntry{
+		ChangeTitle:   "Start",
+		Currency:      "EUR",
+		Denominations: []models.CashDenominationCount{{Key: "eur2", Count: 2}},
+	})
+
+	var backup bytes.Buffer
+	manifest, err := Backup(database, &backup, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, BackupVersion, manifest.Version)
+	assert.Len(t, manifest.Tables, len(backupModels))
+
+	restored, restoredId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(restoredId)
+	err = Migrate(restored)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	_, err = Restore(restored, bytes.NewReader(backup.Bytes()), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rus := NewUserStore(restored)
+	rgs := NewGroupStore(restored)
+
+	restoredUser, err := rus.GetById(user.Id)
+	if assert.NoError(t, err) && assert.NotNil(t, restoredUser) {
+		assert.Equal(t, user.Created, restoredUser.Created)
+		assert.False(t, restoredUser.PubliclyVisible)
+	}
+
+	restoredGroup, err := rgs.GetById(group.Id)
+	if assert.NoError(t, err) && assert.NotNil(t, restoredGroup) {
+		balance, err := rgs.GetUserBalance(restoredGroup, restoredUser)
+		assert.NoError(t, err)
+		assert.Equal(t, 300, balance)
+
+		count, err := rgs.TransactionLogEntryCount(restoredGroup, restoredUser)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	}
+
+	entry, err := rus.GetLastCashLogEntry(restoredUser, models.DefaultCashWalletId)
+	if assert.NoError(t, err) && assert.NotNil(t, entry) {
+		assert.Equal(t, 400, entry.TotalAmount)
+		assert.Len(t, entry.Denominations, 1)
+	}
+
+	var picture models.GroupPicture
+	err = restored.First(&picture).Error
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{1, 2, 3}, picture.Tiny)
+	}
+
+	// the target database must be empty
+	_, err = Restore(restored, bytes.NewReader(backup.Bytes()), nil)
+	assert.Error(t, err)
+}
+
+func TestRestore_OlderSchema(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	// schema version 4 added the schedule rules and the first payment of payment plans
+	err = MigrateTo(database, 3)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database: %s", err)
+	}
+
+	nextExecute := time.Now().Add(time.Hour).Unix()
+	err = database.Table("groups").Create(map[string]interface{}{"id": "group", "name": "family"}).Error
+	if err != nil {
+		t.Fatalf("Couldn't create group: %s", err)
+	}
+	err = database.Table("payment_plans").Create(map[string]interface{}{
+		"id":             "plan",
+		"name":           "allowance",
+		"amount":         100,
+		"payment_count":  -1,
+		"next_execute":   nextExecute,
+		"schedule":       1,
+		"schedule_unit":  models.ScheduleUnitWeek,
+		"sender_is_bank": true,
+		"receiver_id":    "user",
+		"group_id":       "group",
+	}).Error
+	if err != nil {
+		t.Fatalf("Couldn't create payment plan: %s", err)
+	}
+
+	var backup bytes.Buffer
+	manifest, err := Backup(database, &backup, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 3, manifest.SchemaVersion)
+
+	restored, restoredId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(restoredId)
+	err = Migrate(restored)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	_, err = Restore(restored, bytes.NewReader(backup.Bytes()), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	version, err := SchemaVersion(restored)
+	assert.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), version)
+
+	var plan models.PaymentPlan
+	err = restored.First(&plan, "id = ?", "plan").Error
+	if assert.NoError(t, err) {
+		assert.Equal(t, nextExecute, plan.FirstPayment, "the first payment should be backfilled by the migration")
+		assert.Equal(t, models.ScheduleRuleDate, plan.ScheduleRule)
+		assert.Equal(t, 100, plan.Amount)
+	}
+}
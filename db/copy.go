@@ -0,0 +1,113 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Copies all rows of all tables from src into dst in one transaction.
+// The schema of dst must be up to date and all tables must be empty.
+// progress is called after every batch with the number of copied rows and the total row count of the table.
+func CopyDatabase(src, dst *gorm.DB, progress func(table string, rows, total int64)) error {
+	const batchSize = 500
+
+	return dst.Transaction(func(tx *gorm.DB) error {
+		err := checkTablesEmpty(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, model := range backupModels {
+			stmt := &gorm.Statement{DB: src}
+			err := stmt.Parse(model)
+			if err != nil {
+				return err
+			}
+			name := stmt.Schema.Table
+
+			var total int64
+			err = src.Model(model).Count(&total).Error
+			if err != nil {
+				return fmt.Errorf("count rows of table '%s': %w", name, err)
+			}
+
+			var count int64
+			rows := newModelSlice(model)
+			err = src.Model(model).FindInBatches(rows.Interface(), batchSize, func(_ *gorm.DB, _ int) error {
+				err := insertRows(tx, stmt.Schema, stmt.Schema.DBNames, rows.Elem())
+				if err != nil {
+					return err
+				}
+				count += int64(rows.Elem().Len())
+				if progress != nil {
+					progress(name, count, total)
+				}
+				return nil
+			}).Error
+			if err != nil {
+				return fmt.Errorf("copy table '%s': %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Compares the row counts of all tables and checks on both sides that the balance of every member
+// equals the balance recomputed from the transaction log, so that missing or modified log entries are detected.
+func VerifyCopy(src, dst *gorm.DB) error {
+	for _, model := range backupModels {
+		name, err := tableName(src, model)
+		if err != nil {
+			return err
+		}
+		var srcCount, dstCount int64
+		err = src.Model(model).Count(&srcCount).Error
+		if err != nil {
+			return err
+		}
+		err = dst.Model(model).Count(&dstCount).Error
+		if err != nil {
+			return err
+		}
+		if srcCount != dstCount {
+			return fmt.Errorf("table '%s' has %d rows in the source but %d rows in the destination", name, srcCount, dstCount)
+		}
+	}
+
+	for _, side := range []struct {
+		name string
+		db   *gorm.DB
+	}{{name: "source", db: src}, {name: "destination", db: dst}} {
+		drifts, err := NewGroupStore(side.db).GetBalanceDrifts(nil)
+		if err != nil {
+			return err
+		}
+		if len(drifts) > 0 {
+			d := drifts[0]
+			return fmt.Errorf("%d balances in the %s don't match the transaction log, e.g. user '%s' in group '%s' has %d instead of %d", len(drifts), side.name, d.UserId, d.GroupId, d.Balance, d.LogBalance)
+		}
+	}
+
+	srcBalances, err := getLogBalances(src, nil)
+	if err != nil {
+		return err
+	}
+	dstBalances, err := getLogBalances(dst, nil)
+	if err != nil {
+		return err
+	}
+	balances := make(map[logBalance]int, len(srcBalances))
+	for _, b := range srcBalances {
+		balances[b]++
+	}
+	for _, b := range dstBalances {
+		balances[b]--
+	}
+	for b, diff := range balances {
+		if diff != 0 {
+			return fmt.Errorf("the transaction log of user '%s' in group '%s' differs between the source and the destination", b.UserId, b.GroupId)
+		}
+	}
+	return nil
+}
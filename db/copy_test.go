@@ -0,0 +1,85 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juho05/h-bank/models"
+)
+
+func TestCopyDatabase(t *testing.T) {
+	t.Parallel()
+
+	src, srcId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(srcId)
+	err = Migrate(src)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(src)
+	gs := NewGroupStore(src)
+
+	users := []*models.User{{Name: "bob", Email: "bob@gmail.com"}, {Name: "peter", Email: "peter@gmail.com"}}
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	for _, u := range users {
+		us.Create(u)
+		gs.AddMember(group, u)
+	}
+	gs.CreateTransaction(group, true, false, nil, users[0], "Pocket money", "", 300)
+	gs.CreateTransaction(group, false, false, users[0], users[1], "Ice cream", "", 100)
+	gs.Create(&models.Group{Name: "empty"})
+
+	dst, dstId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dstId)
+	err = Migrate(dst)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	var copied int64
+	err = CopyDatabase(src, dst, func(table string, rows, total int64) {
+		assert.LessOrEqual(t, rows, total)
+		if rows == total {
+			copied += rows
+		}
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	// 2 users, 2 groups, 2 memberships and 2 transactions
+	assert.EqualValues(t, 8, copied)
+
+	assert.NoError(t, VerifyCopy(src, dst))
+
+	balance, err := NewGroupStore(dst).GetUserBalance(group, users[1])
+	assert.NoError(t, err)
+	assert.Equal(t, 100, balance)
+
+	// the destination must be empty
+	assert.Error(t, CopyDatabase(src, dst, nil))
+
+	// a log entry which was changed during the copy doesn't match the balances anymore
+	dst.Model(&models.TransactionLogEntry{}).Where("title = ?", "Ice cream").Update("balance_difference_receiver", 50)
+	assert.Error(t, VerifyCopy(src, dst))
+	dst.Model(&models.TransactionLogEntry{}).Where("title = ?", "Ice cream").Update("balance_difference_receiver", 100)
+	assert.NoError(t, VerifyCopy(src, dst))
+
+	dst.Model(&models.GroupMembership{}).Where("user_id = ?", users[1].Id).Update("balance", 0)
+	assert.Error(t, VerifyCopy(src, dst))
+	dst.Model(&models.GroupMembership{}).Where("user_id = ?", users[1].Id).Update("balance", 100)
+
+	// the balances of the source are checked as well
+	src.Model(&models.GroupMembership{}).Where("user_id = ?", users[0].Id).Update("balance", 0)
+	assert.Error(t, VerifyCopy(src, dst))
+}
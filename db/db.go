@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/glebarez/sqlite"
 	"github.com/google/uuid"
@@ -12,7 +13,6 @@ import (
 	"gorm.io/gorm/logger"
 
 	"github.com/juho05/h-bank/config"
-	"github.com/juho05/h-bank/models"
 )
 
 func NewPostgres(dsn string) (*gorm.DB, error) {
@@ -28,6 +28,7 @@ func NewPostgres(dsn string) (*gorm.DB, error) {
 }
 
 func NewSqlite(dsn string) (*gorm.DB, error) {
+	dsn = sqliteDSN(dsn)
 	if config.Data.DBVerbose {
 		return gorm.Open(sqlite.Open(dsn), &gorm.Config{
 			Logger: logger.Default.LogMode(logger.Info),
@@ -39,10 +40,20 @@ func NewSqlite(dsn string) (*gorm.DB, error) {
 	}
 }
 
+// Transactions are started with BEGIN IMMEDIATE so that the write lock is acquired
+// before any balance is read. Concurrent writers wait for the lock instead of failing.
+func sqliteDSN(dsn string) string {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_txlock=immediate&_pragma=busy_timeout(10000)"
+}
+
 // returns db and the id of db file
 func NewTestDB() (*gorm.DB, string, error) {
 	id := uuid.NewString()
-	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("%s.sqlite?_pragma=foreign_keys(1)", id)), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(fmt.Sprintf("%s.sqlite?_pragma=foreign_keys(1)", id))), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	return db, id, err
@@ -55,16 +66,127 @@ func DeleteTestDB(id string) {
 	}
 }
 
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&models.User{},
-		&models.CashLogEntry{},
-
-		&models.Group{},
-		&models.GroupMembership{},
-		&models.GroupPicture{},
-		&models.GroupInvitation{},
-		&models.TransactionLogEntry{},
-		&models.PaymentPlan{},
-	)
+// Tables of schema version 1. Tables which are added later are created by their own migration.
+var initialSchemaModels = []interface{}{
+	&userV1{},
+	&cashLogEntryV1{},
+	&cashDenominationCountV1{},
+	&cashWalletV1{},
+	&cashLogEntryRevisionV1{},
+
+	&groupV1{},
+	&groupMembershipV1{},
+	&groupPictureV1{},
+	&groupInvitationV1{},
+	&paymentRequestV1{},
+	&pendingTransactionV1{},
+	&savingsGoalV1{},
+	&transactionLogEntryV1{},
+	&paymentPlanV1{},
+}
+
+// Creates or updates all tables with gorm's AutoMigrate and converts data of schemas
+// which existed before versioned migrations were introduced.
+func migrateInitialSchema(db *gorm.DB) error {
+	backfillBalances := db.Migrator().HasTable(&groupMembershipV1{}) && !db.Migrator().HasColumn(&groupMembershipV1{}, "Balance")
+	migrateCashColumns := db.Migrator().HasColumn(&cashLogEntryV1{}, legacyCashColumns[0])
+
+	err := db.AutoMigrate(initialSchemaModels...)
+	if err != nil {
+		return err
+	}
+
+	if backfillBalances {
+		log.Println("Computing member balances from transaction logs...")
+		err = db.Transaction(recomputeBalances)
+		if err != nil {
+			return fmt.Errorf("compute member balances: %w", err)
+		}
+	}
+
+	if migrateCashColumns {
+		log.Println("Moving cash log denominations into cash_denomination_counts...")
+		err = db.Transaction(migrateCashDenominations)
+		if err != nil {
+			return fmt.Errorf("migrate cash log denominations: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Sets the balance of every membership to the balance computed from the transaction log.
+// It is part of the initial schema migration and therefore only uses the v1 snapshots.
+func recomputeBalances(tx *gorm.DB) error {
+	balances, err := getLogBalances(tx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&groupMembershipV1{}).Where("balance <> ?", 0).Update("balance", 0).Error
+	if err != nil {
+		return err
+	}
+
+	for _, b := range balances {
+		err = tx.Model(&groupMembershipV1{}).Where("group_id = ? AND user_id = ?", b.GroupId, b.UserId).Update("balance", b.Balance).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns of cash_log_entries which stored the EUR denomination counts before they were moved to
+// cash_denomination_counts. The names are equal to the denomination keys.
+var legacyCashColumns = []string{"ct1", "ct2", "ct5", "ct10", "ct20", "ct50", "eur1", "eur2", "eur5", "eur10", "eur20", "eur50", "eur100", "eur200", "eur500"}
+
+func migrateCashDenominations(tx *gorm.DB) error {
+	var rows []map[string]interface{}
+	err := tx.Table("cash_log_entries").Select(append([]string{"id"}, legacyCashColumns...)).Find(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	counts := make([]cashDenominationCountV1, 0)
+	for _, row := range rows {
+		for _, column := range legacyCashColumns {
+			var count int64
+			switch v := row[column].(type) {
+			case int64:
+				count = v
+			case int32:
+				count = int64(v)
+			case nil:
+				count = 0
+			default:
+				return fmt.Errorf("unexpected type %T of column '%s'", v, column)
+			}
+			if count == 0 {
+				continue
+			}
+			counts = append(counts, cashDenominationCountV1{
+				Id:             uuid.NewString(),
+				CashLogEntryId: fmt.Sprint(row["id"]),
+				Key:            column,
+				Count:          int(count),
+			})
+		}
+	}
+
+	// SQLite drops columns by recreating the table, which would cascade to already inserted counts
+	for _, column := range legacyCashColumns {
+		err = tx.Migrator().DropColumn(&cashLogEntryV1{}, column)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(counts) > 0 {
+		err = tx.CreateInBatches(counts, 500).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juho05/h-bank/models"
+)
+
+func TestMigrate_CashDenominations(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+
+	err = database.Migrator().CreateTable(&models.User{})
+	if err != nil {
+		t.Fatalf("Couldn't create user table: %s", err)
+	}
+	database.Create(&models.User{Base: models.Base{Id: "user"}, Name: "bob", Email: "bob@gmail.com"})
+
+	// schema before the denominations were moved to cash_denomination_counts
+	type legacyCashLogEntry struct {
+		models.Base
+		ChangeTitle       string
+		ChangeDescription string
+		TotalAmount       int
+		ChangeDifference  int
+
+		Ct1, Ct2, Ct5, Ct10, Ct20, Ct50                               int
+		Eur1, Eur2, Eur5, Eur10, Eur20, Eur50, Eur100, Eur200, Eur500 int
+
+		UserId string
+	}
+	legacy := database.Table("cash_log_entries")
+	err = legacy.Migrator().CreateTable(&legacyCashLogEntry{})
+	if err != nil {
+		t.Fatalf("Couldn't create legacy table: %s", err)
+	}
+	err = database.Table("cash_log_entries").Create(&legacyCashLogEntry{
+		Base:        models.Base{Id: "entry"},
+		ChangeTitle: "Test",
+		TotalAmount: 1250,
+		Ct50:        1,
+		Eur2:        2,
+		Eur10:       1,
+		UserId:      "user",
+	}).Error
+	if err != nil {
+		t.Fatalf("Couldn't insert legacy entry: %s", err)
+	}
+
+	err = Migrate(database)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, column := range legacyCashColumns {
+		assert.False(t, database.Migrator().HasColumn(&models.CashLogEntry{}, column), "column %s should be dropped", column)
+	}
+
+	us := NewUserStore(database)
+	entry, err := us.GetCashLogEntryById(&models.User{Base: models.Base{Id: "user"}}, "entry")
+	if assert.NoError(t, err) && assert.NotNil(t, entry) {
+		assert.Equal(t, "EUR", entry.Currency)
+		assert.Equal(t, 1250, entry.TotalAmount)
+		counts := make(map[string]int)
+		for _, d := range entry.Denominations {
+			counts[d.Key] = d.Count
+		}
+		assert.Equal(t, map[string]int{"ct50": 1, "eur2": 2, "eur10": 1}, counts)
+	}
+
+	// a second migration is a no-op
+	assert.NoError(t, Migrate(database))
+}
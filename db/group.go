@@ -2,9 +2,13 @@ package db
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/juho05/h-bank/models"
 	"github.com/juho05/h-bank/services"
@@ -84,6 +88,42 @@ func (gs *GroupStore) Update(group *models.Group) error {
 	return gs.db.Updates(group).Error
 }
 
+func (gs *GroupStore) SetTimeZone(group *models.Group, timeZone string) error {
+	location, err := services.LoadTimeZone(timeZone)
+	if err != nil {
+		return err
+	}
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(group).Update("time_zone", timeZone).Error
+		if err != nil {
+			return err
+		}
+		group.TimeZone = timeZone
+
+		var paymentPlans []models.PaymentPlan
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&paymentPlans, "group_id = ?", group.Id).Error
+		if err != nil {
+			return err
+		}
+		for _, p := range paymentPlans {
+			oldLocation := p.Location()
+			updates := map[string]interface{}{
+				"time_zone":     timeZone,
+				"first_payment": services.MoveToLocation(p.FirstPayment, oldLocation, location),
+				"next_execute":  services.MoveToLocation(p.NextExecute, oldLocation, location),
+			}
+			if p.EndDate > 0 {
+				updates["end_date"] = services.MoveToLocation(p.EndDate, oldLocation, location)
+			}
+			err = tx.Model(&p).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (gs *GroupStore) UpdateGroupPicture(group *models.Group, pic *models.GroupPicture) error {
 	err := gs.db.Select("group_picture_id").Updates(group).Error
 	if err != nil {
@@ -102,11 +142,201 @@ func (gs *GroupStore) UpdateGroupPicture(group *models.Group, pic *models.GroupP
 }
 
 func (gs *GroupStore) Delete(group *models.Group) error {
-	gs.db.Delete(&models.GroupInvitation{}, "group_id = ?", group.Id)
-	gs.db.Delete(&models.GroupMembership{}, "group_id = ?", group.Id)
-	gs.db.Delete(&models.TransactionLogEntry{}, "group_id = ?", group.Id)
-	gs.db.Delete(&models.PaymentPlan{}, "group_id = ?", group.Id)
-	return gs.db.Delete(group).Error
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		return deleteGroup(tx, group)
+	})
+}
+
+func deleteGroup(tx *gorm.DB, group *models.Group) error {
+	err := tx.Delete(&models.GroupInvitation{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.PaymentRequest{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.PendingTransaction{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.SavingsGoal{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.GroupMembership{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.TransactionLogEntry{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.PaymentPlan{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.PaymentPlanExecution{}, "group_id = ?", group.Id).Error
+	if err != nil {
+		return err
+	}
+	return tx.Delete(group).Error
+}
+
+func (gs *GroupStore) SetApprovalPolicy(group *models.Group, threshold int, approveBankTransfers bool) error {
+	group.ApprovalThreshold = threshold
+	group.ApproveBankTransfers = approveBankTransfers
+	return gs.db.Model(group).Select("approval_threshold", "approve_bank_transfers").Updates(group).Error
+}
+
+func (gs *GroupStore) SetInterest(group *models.Group, rate int, period string, minBalance int) error {
+	nextPayout := int64(0)
+	if rate > 0 {
+		var err error
+		nextPayout, err = services.StartOfNextPeriod(time.Now().Unix(), period, group.Location())
+		if err != nil {
+			return err
+		}
+	}
+	periodChanged := group.Interest.Period != period
+	group.Interest = models.Interest{
+		Rate:       rate,
+		Period:     period,
+		MinBalance: minBalance,
+		NextPayout: nextPayout,
+	}
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(group).Select("interest_rate", "interest_period", "interest_min_balance", "interest_next_payout").Updates(group).Error
+		if err != nil {
+			return err
+		}
+		// the remainders are stored in fractions of the payout of one period
+		if periodChanged {
+			return tx.Model(&models.GroupMembership{}).Where("group_id = ?", group.Id).Update("interest_remainder", 0).Error
+		}
+		return nil
+	})
+}
+
+func (gs *GroupStore) GetGroupsWithDueInterest() ([]models.Group, error) {
+	var groups []models.Group
+	err := gs.db.Where("interest_rate > ? AND interest_next_payout <= ? AND COALESCE(archived, ?) = ?", 0, time.Now().Unix(), false, false).Find(&groups).Error
+	return groups, err
+}
+
+func (gs *GroupStore) PayInterest(group *models.Group) (int, error) {
+	periods := 0
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		// the group is reloaded inside of the transaction so that concurrent runs don't pay the same period twice
+		var locked models.Group
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", group.Id).Error
+		if err != nil {
+			return err
+		}
+		if locked.Archived {
+			return nil
+		}
+
+		interest := locked.Interest
+		if interest.Rate <= 0 || interest.NextPayout > time.Now().Unix() {
+			return nil
+		}
+
+		periodsPerYear := map[string]int{
+			models.ScheduleUnitDay:   365,
+			models.ScheduleUnitWeek:  52,
+			models.ScheduleUnitMonth: 12,
+			models.ScheduleUnitYear:  1,
+		}[interest.Period]
+		if periodsPerYear == 0 {
+			return fmt.Errorf("invalid interest period '%s'", interest.Period)
+		}
+
+		// periods which were missed while the server was offline are paid based on the current balances
+		for interest.NextPayout <= time.Now().Unix() {
+			var memberships []models.GroupMembership
+			err = tx.Find(&memberships, "group_id = ? AND is_member = ? AND balance > 0 AND balance >= ?", locked.Id, true, interest.MinBalance).Error
+			if err != nil {
+				return err
+			}
+
+			for _, m := range memberships {
+				// fractions of a cent are carried over to the next payout so that small balances earn interest too
+				earned := m.Balance*interest.Rate + m.InterestRemainder
+				amount := earned / (10000 * periodsPerYear)
+				err = tx.Model(&m).Update("interest_remainder", earned%(10000*periodsPerYear)).Error
+				if err != nil {
+					return err
+				}
+				if amount <= 0 {
+					continue
+				}
+				_, err = createSystemTransaction(tx, &locked, true, false, nil, &models.User{Base: models.Base{Id: m.UserId}}, models.TransactionKindInterest, amount)
+				if err != nil {
+					return err
+				}
+			}
+			periods++
+
+			interest.NextPayout, err = services.StartOfNextPeriod(interest.NextPayout, interest.Period, locked.Location())
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&locked).Update("interest_next_payout", interest.NextPayout).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return periods, nil
+}
+
+func (gs *GroupStore) SetArchived(group *models.Group, archived bool) error {
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(group).Update("archived", archived).Error
+		if err != nil {
+			return err
+		}
+
+		if archived {
+			return nil
+		}
+
+		// interest and payments that were due while the group was archived are skipped
+		if group.Interest.Rate > 0 {
+			nextPayout, err := services.StartOfNextPeriod(time.Now().Unix(), group.Interest.Period, group.Location())
+			if err != nil {
+				return err
+			}
+			err = tx.Model(group).Update("interest_next_payout", nextPayout).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var paymentPlans []models.PaymentPlan
+		err = tx.Find(&paymentPlans, "group_id = ? AND next_execute <= ?", group.Id, time.Now().Unix()).Error
+		if err != nil {
+			return err
+		}
+		for _, p := range paymentPlans {
+			p.NextExecute = p.PaymentSchedule().Next(time.Now().Unix())
+			if p.Finished() {
+				err = finishPaymentPlan(tx, &p)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			err = tx.Model(&p).Update("next_execute", p.NextExecute).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (gs *GroupStore) DeleteById(id string) error {
@@ -206,12 +436,19 @@ func (gs *GroupStore) AddMember(group *models.Group, user *models.User) error {
 	var membership models.GroupMembership
 	err := gs.db.First(&membership, "group_id = ? AND user_id = ?", group.Id, user.Id).Error
 	if err == gorm.ErrRecordNotFound {
+		// users who rejoin a group keep the balance they had when they left
+		var balance int
+		balance, err = getLogBalance(gs.db, group, user)
+		if err != nil {
+			return err
+		}
 		err = gs.db.Model(group).Select("is_member").Association("Memberships").Append(&models.GroupMembership{
 			IsMember:  true,
 			GroupId:   group.Id,
 			UserId:    user.Id,
 			GroupName: group.Name,
 			UserName:  user.Name,
+			Balance:   balance,
 		})
 	} else if err == nil {
 		membership.IsMember = true
@@ -230,14 +467,32 @@ func (gs *GroupStore) RemoveMember(group *models.Group, user *models.User) error
 
 	gs.db.Where("group_id = ? AND sender_id = ?", group.Id, user.Id).Or("group_id = ? AND receiver_id = ?", group.Id, user.Id).Delete(&models.PaymentPlan{})
 
-	if membership.IsAdmin {
-		membership.IsMember = false
-		err = gs.db.Select("is_member").Updates(&membership).Error
-	} else {
-		err = gs.db.Delete(&membership).Error
-	}
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("group_id = ? AND requester_id = ?", group.Id, user.Id).Or("group_id = ? AND payer_id = ?", group.Id, user.Id).Delete(&models.PaymentRequest{}).Error
+		if err != nil {
+			return err
+		}
 
-	return err
+		err = deletePendingTransactions(tx, "group_id = ? AND (sender_id = ? OR receiver_id = ?)", group.Id, user.Id, user.Id)
+		if err != nil {
+			return err
+		}
+
+		err = emptySavingsGoals(tx, group, "group_id = ? AND user_id = ?", group.Id, user.Id)
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.SavingsGoal{}, "group_id = ? AND user_id = ?", group.Id, user.Id).Error
+		if err != nil {
+			return err
+		}
+
+		if membership.IsAdmin {
+			membership.IsMember = false
+			return tx.Select("is_member").Updates(&membership).Error
+		}
+		return tx.Delete(&membership).Error
+	})
 }
 
 func (gs *GroupStore) GetAdmins(except *models.User, searchInput string, group *models.Group, page int, pageSize int, descending bool) ([]models.User, error) {
@@ -356,6 +611,14 @@ func (gs *GroupStore) RemoveAdmin(group *models.Group, user *models.User) error
 	return err
 }
 
+func (gs *GroupStore) GetGroupsWithSoleAdmin(user *models.User) ([]models.Group, error) {
+	var groups []models.Group
+	err := gs.db.Where("id IN (?)", gs.db.Model(&models.GroupMembership{}).Select("group_id").Where("user_id = ? AND is_admin = ?", user.Id, true)).
+		Where("id NOT IN (?)", gs.db.Model(&models.GroupMembership{}).Select("group_id").Where("user_id <> ? AND is_admin = ?", user.Id, true)).
+		Order("name ASC").Find(&groups).Error
+	return groups, err
+}
+
 func (gs *GroupStore) IsInGroup(group *models.Group, user *models.User) (bool, error) {
 	err := gs.db.Where("group_id = ? AND user_id = ? AND is_member = ?", group.Id, user.Id, true).Or("group_id = ? AND user_id = ? AND is_admin = ?", group.Id, user.Id, true).First(&models.GroupMembership{}).Error
 	if err != nil {
@@ -423,6 +686,39 @@ func (gs *GroupStore) BankTransactionLogEntryCount(group *models.Group) (int64,
 	return count, err
 }
 
+// Number of transaction log entries loaded at once while exporting.
+const exportBatchSize = 500
+
+func (gs *GroupStore) ExportTransactionLog(group *models.Group, user *models.User, from, to int64, handle func([]models.TransactionLogEntry) error) error {
+	query := gs.db.Model(&models.TransactionLogEntry{}).Where("group_id = ? AND created >= ?", group.Id, from)
+	if to > 0 {
+		query = query.Where("created < ?", to)
+	}
+	if user != nil {
+		query = query.Where(gs.db.Where("sender_id = ?", user.Id).Or("receiver_id = ?", user.Id))
+	} else {
+		query = query.Where(gs.db.Where("sender_is_bank = ?", true).Or("receiver_is_bank = ?", true))
+	}
+
+	for offset := 0; ; offset += exportBatchSize {
+		var batch []models.TransactionLogEntry
+		err := query.Session(&gorm.Session{}).Order("created ASC, id ASC").Offset(offset).Limit(exportBatchSize).Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		err = handle(batch)
+		if err != nil {
+			return err
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
 func (gs *GroupStore) GetTransactionLogEntryById(group *models.Group, id string) (*models.TransactionLogEntry, error) {
 	var entry models.TransactionLogEntry
 	err := gs.db.First(&entry, "group_id = ? AND id = ?", group.Id, id).Error
@@ -453,123 +749,938 @@ func (gs *GroupStore) GetLastTransactionLogEntry(group *models.Group, user *mode
 }
 
 func (gs *GroupStore) GetUserBalance(group *models.Group, user *models.User) (int, error) {
-	lastLogEntry, err := gs.GetLastTransactionLogEntry(group, user)
+	var membership models.GroupMembership
+	err := gs.db.Select("balance").First(&membership, "group_id = ? AND user_id = ?", group.Id, user.Id).Error
 	if err != nil {
-		return 0, err
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return 0, nil
+		default:
+			return 0, err
+		}
 	}
-	if lastLogEntry == nil {
-		return 0, nil
+	return membership.Balance, nil
+}
+
+func (gs *GroupStore) GetSpendingLimits(group *models.Group, user *models.User) (*models.SpendingLimits, error) {
+	var membership models.GroupMembership
+	err := gs.db.First(&membership, "group_id = ? AND user_id = ?", group.Id, user.Id).Error
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, err
+		}
 	}
+	return &membership.SpendingLimits, nil
+}
 
-	if lastLogEntry.SenderId == user.Id {
-		return lastLogEntry.NewBalanceSender, nil
-	} else {
-		return lastLogEntry.NewBalanceReceiver, nil
+func (gs *GroupStore) SetSpendingLimits(group *models.Group, user *models.User, limits models.SpendingLimits) error {
+	return gs.db.Model(&models.GroupMembership{}).Where("group_id = ? AND user_id = ?", group.Id, user.Id).
+		Select("max_per_transaction", "max_per_day", "max_per_week", "max_per_month", "overdraft").
+		Updates(&models.GroupMembership{SpendingLimits: limits}).Error
+}
+
+func (gs *GroupStore) GetReservedAmount(group *models.Group, user *models.User) (int, error) {
+	var membership models.GroupMembership
+	err := gs.db.Select("reserved").First(&membership, "group_id = ? AND user_id = ?", group.Id, user.Id).Error
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return 0, nil
+		default:
+			return 0, err
+		}
 	}
+	return membership.Reserved, nil
 }
 
-func (gs *GroupStore) CreateTransaction(group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, title, description string, amount int) (*models.TransactionLogEntry, error) {
-	return gs.CreateTransactionFromPaymentPlan(group, senderIsBank, receiverIsBank, sender, receiver, title, description, amount, "")
+// Recomputes the balance of the user from the transaction log.
+func getLogBalance(tx *gorm.DB, group *models.Group, user *models.User) (int, error) {
+	var balance int
+	err := tx.Model(&models.TransactionLogEntry{}).
+		Select("COALESCE(SUM(CASE WHEN sender_id = ? THEN balance_difference_sender ELSE 0 END), 0) + COALESCE(SUM(CASE WHEN receiver_id = ? THEN balance_difference_receiver ELSE 0 END), 0)", user.Id, user.Id).
+		Where("group_id = ? AND sender_id = ?", group.Id, user.Id).Or("group_id = ? AND receiver_id = ?", group.Id, user.Id).
+		Scan(&balance).Error
+	return balance, err
 }
 
-func (gs *GroupStore) CreateTransactionFromPaymentPlan(group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, title, description string, amount int, paymentPlanId string) (*models.TransactionLogEntry, error) {
-	var err error
+type logBalance struct {
+	GroupId string
+	UserId  string
+	Balance int
+}
 
-	oldBalanceSender := 0
-	newBalanceSender := 0
-	if !senderIsBank {
-		oldBalanceSender, err = gs.GetUserBalance(group, sender)
-		if err != nil {
-			return nil, err
-		}
-		newBalanceSender = oldBalanceSender - amount
+// Recomputes the balances of all users from the transaction log.
+// Only the given group is considered if group is not nil.
+func getLogBalances(tx *gorm.DB, group *models.Group) ([]logBalance, error) {
+	groupFilter := ""
+	args := []interface{}{false, false}
+	if group != nil {
+		groupFilter = " AND group_id = ?"
+		args = []interface{}{false, group.Id, false, group.Id}
 	}
 
-	oldBalanceReceiver := 0
-	newBalanceReceiver := 0
-	if !receiverIsBank {
-		oldBalanceReceiver, err = gs.GetUserBalance(group, receiver)
-		if err != nil {
-			return nil, err
-		}
-		newBalanceReceiver = oldBalanceReceiver + amount
+	var balances []logBalance
+	err := tx.Raw("SELECT group_id, user_id, SUM(difference) AS balance FROM ("+
+		"SELECT group_id, sender_id AS user_id, balance_difference_sender AS difference FROM transaction_log_entries WHERE sender_is_bank = ?"+groupFilter+
+		" UNION ALL "+
+		"SELECT group_id, receiver_id AS user_id, balance_difference_receiver AS difference FROM transaction_log_entries WHERE receiver_is_bank = ?"+groupFilter+
+		") AS differences GROUP BY group_id, user_id", args...).Scan(&balances).Error
+	return balances, err
+}
+
+// Locks the memberships of the given users until the end of the transaction (Postgres).
+// SQLite ignores the locking clause and relies on the immediate write lock of the transaction instead.
+func lockMemberships(tx *gorm.DB, group *models.Group, userIds ...string) (map[string]*models.GroupMembership, error) {
+	memberships := make(map[string]*models.GroupMembership, len(userIds))
+	if len(userIds) == 0 {
+		return memberships, nil
 	}
 
-	senderId := ""
-	if !senderIsBank {
-		senderId = sender.Id
+	var list []models.GroupMembership
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id ASC").Find(&list, "group_id = ? AND user_id IN ?", group.Id, userIds).Error
+	if err != nil {
+		return nil, err
 	}
 
-	receiverId := ""
-	if !receiverIsBank {
-		receiverId = receiver.Id
+	for i := range list {
+		memberships[list[i].UserId] = &list[i]
 	}
 
-	transaction := models.TransactionLogEntry{
-		Title:       title,
-		Description: description,
-		Amount:      int(amount),
-		GroupId:     group.Id,
+	for _, id := range userIds {
+		if _, ok := memberships[id]; !ok {
+			return nil, fmt.Errorf("%w: user '%s', group '%s'", models.ErrNotInGroup, id, group.Id)
+		}
+	}
 
-		SenderIsBank:            senderIsBank,
-		SenderId:                senderId,
-		BalanceDifferenceSender: -amount,
-		NewBalanceSender:        newBalanceSender,
+	return memberships, nil
+}
 
-		ReceiverIsBank:            receiverIsBank,
-		ReceiverId:                receiverId,
-		BalanceDifferenceReceiver: amount,
-		NewBalanceReceiver:        newBalanceReceiver,
+func (gs *GroupStore) CreateTransaction(group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, title, description string, amount int) (*models.TransactionLogEntry, error) {
+	return gs.CreateTransactionFromPaymentPlan(group, senderIsBank, receiverIsBank, sender, receiver, title, description, amount, "")
+}
 
-		PaymentPlanId: paymentPlanId,
-	}
+func (gs *GroupStore) CreateTransactionFromPaymentPlan(group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, title, description string, amount int, paymentPlanId string) (*models.TransactionLogEntry, error) {
+	var transaction *models.TransactionLogEntry
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
 
-	err = gs.db.Create(&transaction).Error
+		transaction, err = createTransaction(tx, group, senderIsBank, receiverIsBank, sender, receiver, title, description, amount, paymentPlanId, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return &transaction, err
+	return transaction, nil
 }
 
-func (gs *GroupStore) CreateInvitation(group *models.Group, user *models.User, message string) (*models.GroupInvitation, error) {
-	invitation := &models.GroupInvitation{
-		Message:   message,
-		GroupName: group.Name,
-		GroupId:   group.Id,
-		UserId:    user.Id,
+// Returned from the import transaction to roll back dry runs and failed imports.
+var errRollbackImport = errors.New("rollback import")
+
+// Imports the transactions in chronological order. Rows can be older than existing transactions,
+// the new balances of all later transactions of their sender and receiver are recomputed.
+// The balance checks use the balance at the time of the import, not at the date of the row.
+func (gs *GroupStore) ImportTransactions(group *models.Group, transactions []models.TransactionImport, dryRun bool) (map[int]error, error) {
+	order := make([]int, len(transactions))
+	for i := range order {
+		order[i] = i
 	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return transactions[order[i]].Time < transactions[order[j]].Time
+	})
 
-	err := gs.db.Create(invitation).Error
+	rowErrors := make(map[int]error)
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
 
-	return invitation, err
-}
+		// time of the oldest imported transaction by member
+		oldest := make(map[string]int64)
+		for _, i := range order {
+			t := transactions[i]
+			transaction, err := createTransaction(tx, group, t.SenderIsBank, t.ReceiverIsBank, t.Sender, t.Receiver, t.Title, t.Description, t.Amount, "", false)
+			if errors.Is(err, models.ErrNotEnoughMoney) || errors.Is(err, models.ErrNotInGroup) {
+				rowErrors[i] = err
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			err = tx.Model(transaction).Update("created", t.Time).Error
+			if err != nil {
+				return err
+			}
+			for _, id := range []string{transaction.SenderId, transaction.ReceiverId} {
+				if _, ok := oldest[id]; id != "" && !ok {
+					oldest[id] = t.Time
+				}
+			}
+		}
 
-func (gs *GroupStore) GetInvitationById(id string) (*models.GroupInvitation, error) {
-	var invitation models.GroupInvitation
-	err := gs.db.First(&invitation, "id = ?", id).Error
-	if err != nil {
-		switch err {
-		case gorm.ErrRecordNotFound:
-			return nil, nil
-		default:
-			return nil, err
+		if dryRun || len(rowErrors) > 0 {
+			return errRollbackImport
 		}
+
+		for id, from := range oldest {
+			err = recomputeNewBalances(tx, group, id, from)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollbackImport) {
+		return nil, err
 	}
 
-	return &invitation, nil
+	return rowErrors, nil
 }
 
-func (gs *GroupStore) GetInvitationsByGroup(group *models.Group, page, pageSize int, oldestFirst bool) ([]models.GroupInvitation, error) {
-	order := "DESC"
-	if oldestFirst {
-		order = "ASC"
+// Recomputes the new balances of the member in all transactions since from in chronological order.
+// Must be called inside of a database transaction.
+func recomputeNewBalances(tx *gorm.DB, group *models.Group, userId string, from int64) error {
+	var balance int
+	err := tx.Model(&models.TransactionLogEntry{}).
+		Select("COALESCE(SUM(CASE WHEN sender_is_bank = ? AND sender_id = ? THEN balance_difference_sender ELSE 0 END), 0) + COALESCE(SUM(CASE WHEN receiver_is_bank = ? AND receiver_id = ? THEN balance_difference_receiver ELSE 0 END), 0)", false, userId, false, userId).
+		Where("group_id = ? AND created < ?", group.Id, from).
+		Scan(&balance).Error
+	if err != nil {
+		return err
 	}
 
-	var invitations []models.GroupInvitation
-	var err error
-	if page < 0 || pageSize < 0 {
-		err = gs.db.Order("created "+order).Find(&invitations, "group_id = ?", group.Id).Error
-	} else {
-		err = gs.db.Order("created "+order).Offset(page*pageSize).Limit(pageSize).Find(&invitations, "group_id = ?", group.Id).Error
-	}
+	// the new balances don't change the order, so the entries can be paged with offsets
+	const batchSize = 500
+	for offset := 0; ; offset += batchSize {
+		var entries []models.TransactionLogEntry
+		err = tx.Where("group_id = ? AND created >= ?", group.Id, from).
+			Where("(sender_is_bank = ? AND sender_id = ?) OR (receiver_is_bank = ? AND receiver_id = ?)", false, userId, false, userId).
+			Order("created ASC, id ASC").Offset(offset).Limit(batchSize).Find(&entries).Error
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			// savings goal transfers have the member on both sides
+			if !e.SenderIsBank && e.SenderId == userId {
+				balance += e.BalanceDifferenceSender
+			}
+			if !e.ReceiverIsBank && e.ReceiverId == userId {
+				balance += e.BalanceDifferenceReceiver
+			}
+
+			updates := make(map[string]interface{}, 2)
+			if !e.SenderIsBank && e.SenderId == userId && e.NewBalanceSender != balance {
+				updates["new_balance_sender"] = balance
+			}
+			if !e.ReceiverIsBank && e.ReceiverId == userId && e.NewBalanceReceiver != balance {
+				updates["new_balance_receiver"] = balance
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			err = tx.Model(&models.TransactionLogEntry{}).Where("id = ?", e.Id).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (gs *GroupStore) CreateCashTransaction(group *models.Group, user *models.User, payout bool, title, description, walletId string, denominations []models.CashDenominationCount, checkLimits bool) (*models.TransactionLogEntry, *models.CashLogEntry, error) {
+	amount := 0
+	moved := make(map[string]int, len(denominations))
+	for _, d := range denominations {
+		denomination, ok := services.GetDenomination(group.Currency, d.Key)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown denomination '%s' of currency '%s'", d.Key, group.Currency)
+		}
+		amount += denomination.Value * d.Count
+		moved[d.Key] += d.Count
+	}
+	if amount <= 0 {
+		return nil, nil, fmt.Errorf("cash transaction without any cash")
+	}
+
+	cashEntry := &models.CashLogEntry{
+		Base:               models.Base{Id: uuid.NewString()},
+		ChangeTitle:        title,
+		ChangeDescription:  description,
+		WalletId:           walletId,
+		TransactionGroupId: group.Id,
+	}
+
+	var transaction *models.TransactionLogEntry
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+
+		currency, err := walletCurrency(tx, user, walletId)
+		if err != nil {
+			return err
+		}
+		if currency != group.Currency {
+			return models.ErrCurrencyMismatch
+		}
+		cashEntry.Currency = currency
+
+		sign := -1
+		if payout {
+			sign = 1
+		}
+		cashEntry.Denominations, err = moveDenominations(tx, user, walletId, moved, sign)
+		if err != nil {
+			return err
+		}
+
+		if payout {
+			transaction, err = createTransaction(tx, group, false, true, user, nil, title, description, amount, "", checkLimits)
+		} else {
+			transaction, err = createTransaction(tx, group, true, false, nil, user, title, description, amount, "", checkLimits)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(transaction).Update("cash_log_entry_id", cashEntry.Id).Error
+		if err != nil {
+			return err
+		}
+		transaction.CashLogEntryId = cashEntry.Id
+
+		cashEntry.TransactionId = transaction.Id
+		return addCashLogEntry(tx, user, cashEntry)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transaction, cashEntry, nil
+}
+
+func (gs *GroupStore) CreatePendingTransaction(group *models.Group, sender *models.User, receiverIsBank bool, receiver *models.User, title, description string, amount int) (*models.PendingTransaction, error) {
+	receiverId := ""
+	if !receiverIsBank {
+		receiverId = receiver.Id
+	}
+
+	pending := &models.PendingTransaction{
+		Title:          title,
+		Description:    description,
+		Amount:         amount,
+		GroupId:        group.Id,
+		SenderId:       sender.Id,
+		ReceiverIsBank: receiverIsBank,
+		ReceiverId:     receiverId,
+	}
+
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+		return createPendingTransaction(tx, group, pending)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// Reserves the amount of the pending transaction on the balance of the sender and stores it.
+// Must be called inside of a database transaction.
+func createPendingTransaction(tx *gorm.DB, group *models.Group, pending *models.PendingTransaction) error {
+	memberships, err := lockMemberships(tx, group, pending.SenderId)
+	if err != nil {
+		return err
+	}
+	membership := memberships[pending.SenderId]
+	if membership.Balance-membership.Reserved-pending.Amount < -membership.SpendingLimits.Overdraft {
+		return models.ErrNotEnoughMoney
+	}
+	err = checkSpendingLimits(tx, group, membership, pending.Amount)
+	if err != nil {
+		return err
+	}
+	err = tx.Model(membership).Update("reserved", membership.Reserved+pending.Amount).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(pending).Error
+}
+
+func (gs *GroupStore) GetPendingTransactionById(group *models.Group, id string) (*models.PendingTransaction, error) {
+	var pending models.PendingTransaction
+	err := gs.db.First(&pending, "group_id = ? AND id = ?", group.Id, id).Error
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+
+	return &pending, nil
+}
+
+func (gs *GroupStore) GetPendingTransactions(group *models.Group, user *models.User, page, pageSize int, oldestFirst bool) ([]models.PendingTransaction, error) {
+	order := "DESC"
+	if oldestFirst {
+		order = "ASC"
+	}
+
+	query := gs.db.Where("group_id = ?", group.Id)
+	if user != nil {
+		query = query.Where("sender_id = ?", user.Id)
+	}
+	query = query.Order("created " + order)
+	if page >= 0 && pageSize >= 0 {
+		query = query.Offset(page * pageSize).Limit(pageSize)
+	}
+
+	var pendingTransactions []models.PendingTransaction
+	err := query.Find(&pendingTransactions).Error
+	return pendingTransactions, err
+}
+
+func (gs *GroupStore) PendingTransactionCount(group *models.Group, user *models.User) (int64, error) {
+	query := gs.db.Model(&models.PendingTransaction{}).Where("group_id = ?", group.Id)
+	if user != nil {
+		query = query.Where("sender_id = ?", user.Id)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (gs *GroupStore) ApprovePendingTransaction(group *models.Group, pending *models.PendingTransaction) (*models.TransactionLogEntry, error) {
+	var transaction *models.TransactionLogEntry
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+
+		err = releasePendingTransaction(tx, group, pending)
+		if err != nil {
+			return err
+		}
+
+		var receiver *models.User
+		if !pending.ReceiverIsBank {
+			receiver = &models.User{Base: models.Base{Id: pending.ReceiverId}}
+		}
+		sender := &models.User{Base: models.Base{Id: pending.SenderId}}
+
+		transaction, err = createTransaction(tx, group, false, pending.ReceiverIsBank, sender, receiver, pending.Title, pending.Description, pending.Amount, "", false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (gs *GroupStore) RejectPendingTransaction(group *models.Group, pending *models.PendingTransaction) error {
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		return releasePendingTransaction(tx, group, pending)
+	})
+}
+
+// Deletes the pending transaction and frees the reserved amount.
+// Must be called inside of a database transaction.
+func releasePendingTransaction(tx *gorm.DB, group *models.Group, pending *models.PendingTransaction) error {
+	memberships, err := lockMemberships(tx, group, pending.SenderId)
+	if err != nil {
+		return err
+	}
+
+	// prevents executing a pending transaction twice if it is approved concurrently
+	result := tx.Delete(pending)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrPendingTransactionHandled
+	}
+
+	membership := memberships[pending.SenderId]
+	return tx.Model(membership).Update("reserved", membership.Reserved-pending.Amount).Error
+}
+
+// Deletes all pending transactions matching the query and frees the reserved amounts.
+// Must be called inside of a database transaction.
+func deletePendingTransactions(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	var pendingTransactions []models.PendingTransaction
+	err := tx.Where(query, args...).Find(&pendingTransactions).Error
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pendingTransactions {
+		err = tx.Model(&models.GroupMembership{}).Where("group_id = ? AND user_id = ?", p.GroupId, p.SenderId).Update("reserved", gorm.Expr("reserved - ?", p.Amount)).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&p).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gs *GroupStore) ReverseTransaction(group *models.Group, transaction *models.TransactionLogEntry) (*models.TransactionLogEntry, error) {
+	if transaction.ReversalOfId != "" {
+		return nil, models.ErrTransactionIsReversal
+	}
+	if transaction.SavingsGoalId != "" {
+		return nil, models.ErrSavingsGoalTransfer
+	}
+	if transaction.CashLogEntryId != "" {
+		return nil, models.ErrCashTransaction
+	}
+
+	var reversal *models.TransactionLogEntry
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+
+		var sender, receiver *models.User
+		if !transaction.ReceiverIsBank {
+			sender = &models.User{Base: models.Base{Id: transaction.ReceiverId}}
+		}
+		if !transaction.SenderIsBank {
+			receiver = &models.User{Base: models.Base{Id: transaction.SenderId}}
+		}
+
+		reversal, err = createTransaction(tx, group, transaction.ReceiverIsBank, transaction.SenderIsBank, sender, receiver, transaction.Title, transaction.Description, transaction.Amount, "", false)
+		if err != nil {
+			return err
+		}
+
+		reversal.ReversalOfId = transaction.Id
+		err = tx.Model(reversal).Update("reversal_of_id", reversal.ReversalOfId).Error
+		if err != nil {
+			return err
+		}
+
+		// only succeeds for the first reversal even if several are running concurrently
+		result := tx.Model(&models.TransactionLogEntry{}).Where("id = ? AND (reversed_by_id = '' OR reversed_by_id IS NULL)", transaction.Id).Update("reversed_by_id", reversal.Id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrTransactionReversed
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.ReversedById = reversal.Id
+	return reversal, nil
+}
+
+func (gs *GroupStore) GetSavingsGoals(group *models.Group, user *models.User, page, pageSize int, oldestFirst bool) ([]models.SavingsGoal, error) {
+	order := "DESC"
+	if oldestFirst {
+		order = "ASC"
+	}
+
+	query := gs.db.Where("group_id = ? AND user_id = ?", group.Id, user.Id).Order("created " + order)
+	if page >= 0 && pageSize >= 0 {
+		query = query.Offset(page * pageSize).Limit(pageSize)
+	}
+
+	var goals []models.SavingsGoal
+	err := query.Find(&goals).Error
+	return goals, err
+}
+
+func (gs *GroupStore) SavingsGoalCount(group *models.Group, user *models.User) (int64, error) {
+	var count int64
+	err := gs.db.Model(&models.SavingsGoal{}).Where("group_id = ? AND user_id = ?", group.Id, user.Id).Count(&count).Error
+	return count, err
+}
+
+func (gs *GroupStore) GetSavingsGoalById(group *models.Group, user *models.User, id string) (*models.SavingsGoal, error) {
+	var goal models.SavingsGoal
+	err := gs.db.First(&goal, "id = ? AND group_id = ? AND user_id = ?", id, group.Id, user.Id).Error
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+	return &goal, nil
+}
+
+func (gs *GroupStore) CreateSavingsGoal(group *models.Group, user *models.User, name string, target int, deadline int64) (*models.SavingsGoal, error) {
+	goal := &models.SavingsGoal{
+		Name:     name,
+		Target:   target,
+		Deadline: deadline,
+		GroupId:  group.Id,
+		UserId:   user.Id,
+	}
+	err := gs.db.Create(goal).Error
+	return goal, err
+}
+
+func (gs *GroupStore) UpdateSavingsGoal(goal *models.SavingsGoal) error {
+	return gs.db.Model(goal).Select("name", "target", "deadline").Updates(goal).Error
+}
+
+func (gs *GroupStore) DeleteSavingsGoal(group *models.Group, goal *models.SavingsGoal) error {
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+
+		err = emptySavingsGoals(tx, group, "id = ?", goal.Id)
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(goal).Error
+	})
+}
+
+func (gs *GroupStore) DepositToSavingsGoal(group *models.Group, goal *models.SavingsGoal, amount int) (*models.TransactionLogEntry, error) {
+	var transaction *models.TransactionLogEntry
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+		transaction, err = transferSavings(tx, group, goal, amount)
+		return err
+	})
+	return transaction, err
+}
+
+func (gs *GroupStore) WithdrawFromSavingsGoal(group *models.Group, goal *models.SavingsGoal, amount int) (*models.TransactionLogEntry, error) {
+	var transaction *models.TransactionLogEntry
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+		transaction, err = transferSavings(tx, group, goal, -amount)
+		return err
+	})
+	return transaction, err
+}
+
+// Must be called inside of a database transaction.
+// Moves amount from the main balance of the member to the goal (negative amounts move money back to the main balance).
+func transferSavings(tx *gorm.DB, group *models.Group, goal *models.SavingsGoal, amount int) (*models.TransactionLogEntry, error) {
+	memberships, err := lockMemberships(tx, group, goal.UserId)
+	if err != nil {
+		return nil, err
+	}
+	membership := memberships[goal.UserId]
+
+	// the goal is reloaded after locking the membership so that concurrent transfers see the latest balance
+	var locked models.SavingsGoal
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", goal.Id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	newBalance := membership.Balance - amount
+	newGoalBalance := locked.Balance + amount
+	// savings can't be paid with the overdraft
+	if (amount > 0 && newBalance-membership.Reserved < 0) || newGoalBalance < 0 {
+		return nil, models.ErrNotEnoughMoney
+	}
+
+	err = tx.Model(membership).Update("balance", newBalance).Error
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Model(&locked).Update("balance", newGoalBalance).Error
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := models.TransactionLogEntry{
+		Title:   locked.Name,
+		Amount:  amount,
+		GroupId: group.Id,
+
+		SenderId:         goal.UserId,
+		NewBalanceSender: newBalance,
+
+		ReceiverId:         goal.UserId,
+		NewBalanceReceiver: newBalance,
+
+		SavingsGoalId: goal.Id,
+	}
+	if amount > 0 {
+		transaction.BalanceDifferenceSender = -amount
+	} else {
+		transaction.Amount = -amount
+		transaction.BalanceDifferenceReceiver = -amount
+	}
+
+	err = tx.Create(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+
+	goal.Balance = newGoalBalance
+	return &transaction, nil
+}
+
+// Must be called inside of a database transaction.
+// Moves the balances of all matching savings goals back to the main balances of their members.
+func emptySavingsGoals(tx *gorm.DB, group *models.Group, query string, args ...interface{}) error {
+	var goals []models.SavingsGoal
+	err := tx.Where(query, args...).Where("balance > ?", 0).Find(&goals).Error
+	if err != nil {
+		return err
+	}
+	for i := range goals {
+		g := group
+		if g == nil || g.Id != goals[i].GroupId {
+			g = &models.Group{Base: models.Base{Id: goals[i].GroupId}}
+		}
+		_, err = transferSavings(tx, g, &goals[i], -goals[i].Balance)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Must be called inside of a database transaction.
+func checkNotArchived(tx *gorm.DB, group *models.Group) error {
+	var archived bool
+	err := tx.Model(&models.Group{}).Select("COALESCE(archived, ?)", false).Where("id = ?", group.Id).Scan(&archived).Error
+	if err != nil {
+		return err
+	}
+	if archived {
+		return models.ErrGroupArchived
+	}
+	return nil
+}
+
+// Must be called inside of a database transaction.
+// The spending limits of the sender are only enforced if checkLimits is true.
+func createTransaction(tx *gorm.DB, group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, title, description string, amount int, paymentPlanId string, checkLimits bool) (*models.TransactionLogEntry, error) {
+	senderId := ""
+	if !senderIsBank {
+		senderId = sender.Id
+	}
+
+	receiverId := ""
+	if !receiverIsBank {
+		receiverId = receiver.Id
+	}
+
+	lockIds := make([]string, 0, 2)
+	if senderId != "" {
+		lockIds = append(lockIds, senderId)
+	}
+	if receiverId != "" {
+		lockIds = append(lockIds, receiverId)
+	}
+	memberships, err := lockMemberships(tx, group, lockIds...)
+	if err != nil {
+		return nil, err
+	}
+
+	newBalanceSender := 0
+	if !senderIsBank {
+		membership := memberships[senderId]
+		newBalanceSender = membership.Balance - amount
+		if newBalanceSender-membership.Reserved < -membership.SpendingLimits.Overdraft {
+			return nil, models.ErrNotEnoughMoney
+		}
+		if checkLimits {
+			err = checkSpendingLimits(tx, group, membership, amount)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = tx.Model(memberships[senderId]).Update("balance", newBalanceSender).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newBalanceReceiver := 0
+	if !receiverIsBank {
+		newBalanceReceiver = memberships[receiverId].Balance + amount
+		err = tx.Model(memberships[receiverId]).Update("balance", newBalanceReceiver).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transaction := models.TransactionLogEntry{
+		Title:       title,
+		Description: description,
+		Amount:      int(amount),
+		GroupId:     group.Id,
+
+		SenderIsBank:            senderIsBank,
+		SenderId:                senderId,
+		BalanceDifferenceSender: -amount,
+		NewBalanceSender:        newBalanceSender,
+
+		ReceiverIsBank:            receiverIsBank,
+		ReceiverId:                receiverId,
+		BalanceDifferenceReceiver: amount,
+		NewBalanceReceiver:        newBalanceReceiver,
+
+		PaymentPlanId: paymentPlanId,
+	}
+
+	err = tx.Create(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// Must be called inside of a database transaction.
+// Creates a transaction without a title which is labelled by kind (models.TransactionKind…).
+func createSystemTransaction(tx *gorm.DB, group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, kind string, amount int) (*models.TransactionLogEntry, error) {
+	transaction, err := createTransaction(tx, group, senderIsBank, receiverIsBank, sender, receiver, "", "", amount, "", false)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Model(transaction).Update("kind", kind).Error
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// Must be called inside of a database transaction after locking the membership.
+func checkSpendingLimits(tx *gorm.DB, group *models.Group, membership *models.GroupMembership, amount int) error {
+	limits := membership.SpendingLimits
+	if limits.MaxPerTransaction > 0 && amount > limits.MaxPerTransaction {
+		return &models.SpendingLimitError{Limit: models.SpendingLimitTransaction, Max: limits.MaxPerTransaction}
+	}
+
+	// money reserved by pending transactions counts as spent until the transactions are rejected
+	var reserved int
+	err := tx.Model(&models.PendingTransaction{}).Select("COALESCE(SUM(amount), 0)").
+		Where("group_id = ? AND sender_id = ?", membership.GroupId, membership.UserId).
+		Scan(&reserved).Error
+	if err != nil {
+		return err
+	}
+
+	periods := []struct {
+		unit string
+		max  int
+	}{
+		{unit: models.ScheduleUnitDay, max: limits.MaxPerDay},
+		{unit: models.ScheduleUnitWeek, max: limits.MaxPerWeek},
+		{unit: models.ScheduleUnitMonth, max: limits.MaxPerMonth},
+	}
+	for _, p := range periods {
+		if p.max <= 0 {
+			continue
+		}
+		if amount > p.max {
+			return &models.SpendingLimitError{Limit: p.unit, Max: p.max}
+		}
+
+		start, err := services.StartOfPeriod(time.Now().Unix(), p.unit, group.Location())
+		if err != nil {
+			return err
+		}
+
+		// reversed transactions, reversals and savings don't count as spending
+		var spent int
+		err = tx.Model(&models.TransactionLogEntry{}).Select("COALESCE(SUM(amount), 0)").
+			Where("group_id = ? AND sender_is_bank = ? AND sender_id = ? AND created >= ?", membership.GroupId, false, membership.UserId, start).
+			Where("COALESCE(reversal_of_id, '') = '' AND COALESCE(reversed_by_id, '') = '' AND COALESCE(savings_goal_id, '') = ''").
+			Scan(&spent).Error
+		if err != nil {
+			return err
+		}
+		if spent+reserved+amount > p.max {
+			return &models.SpendingLimitError{Limit: p.unit, Max: p.max}
+		}
+	}
+
+	return nil
+}
+
+func (gs *GroupStore) CreateInvitation(group *models.Group, user *models.User, message string) (*models.GroupInvitation, error) {
+	invitation := &models.GroupInvitation{
+		Message:   message,
+		GroupName: group.Name,
+		GroupId:   group.Id,
+		UserId:    user.Id,
+	}
+
+	err := gs.db.Create(invitation).Error
+
+	return invitation, err
+}
+
+func (gs *GroupStore) GetInvitationById(id string) (*models.GroupInvitation, error) {
+	var invitation models.GroupInvitation
+	err := gs.db.First(&invitation, "id = ?", id).Error
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+
+	return &invitation, nil
+}
+
+func (gs *GroupStore) GetInvitationsByGroup(group *models.Group, page, pageSize int, oldestFirst bool) ([]models.GroupInvitation, error) {
+	order := "DESC"
+	if oldestFirst {
+		order = "ASC"
+	}
+
+	var invitations []models.GroupInvitation
+	var err error
+	if page < 0 || pageSize < 0 {
+		err = gs.db.Order("created "+order).Find(&invitations, "group_id = ?", group.Id).Error
+	} else {
+		err = gs.db.Order("created "+order).Offset(page*pageSize).Limit(pageSize).Find(&invitations, "group_id = ?", group.Id).Error
+	}
 
 	return invitations, err
 }
@@ -622,6 +1733,161 @@ func (gs *GroupStore) DeleteInvitation(invitation *models.GroupInvitation) error
 	return gs.db.Delete(invitation).Error
 }
 
+func (gs *GroupStore) CreatePaymentRequest(group *models.Group, requester *models.User, payerIsBank bool, payer *models.User, title, description string, amount int) (*models.PaymentRequest, error) {
+	payerId := ""
+	if !payerIsBank {
+		payerId = payer.Id
+	}
+
+	request := &models.PaymentRequest{
+		Title:       title,
+		Description: description,
+		Amount:      amount,
+		GroupId:     group.Id,
+		RequesterId: requester.Id,
+		PayerIsBank: payerIsBank,
+		PayerId:     payerId,
+	}
+
+	err := gs.db.Create(request).Error
+
+	return request, err
+}
+
+func (gs *GroupStore) GetPaymentRequestById(id string) (*models.PaymentRequest, error) {
+	var request models.PaymentRequest
+	err := gs.db.First(&request, "id = ?", id).Error
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+
+	return &request, nil
+}
+
+func (gs *GroupStore) getPaymentRequests(page, pageSize int, oldestFirst bool, query interface{}, args ...interface{}) ([]models.PaymentRequest, error) {
+	order := "DESC"
+	if oldestFirst {
+		order = "ASC"
+	}
+
+	var requests []models.PaymentRequest
+	var err error
+	if page < 0 || pageSize < 0 {
+		err = gs.db.Where(query, args...).Order("created " + order).Find(&requests).Error
+	} else {
+		err = gs.db.Where(query, args...).Order("created " + order).Offset(page * pageSize).Limit(pageSize).Find(&requests).Error
+	}
+
+	return requests, err
+}
+
+func (gs *GroupStore) GetIncomingPaymentRequests(user *models.User, page, pageSize int, oldestFirst bool) ([]models.PaymentRequest, error) {
+	return gs.getPaymentRequests(page, pageSize, oldestFirst, "payer_is_bank = ? AND payer_id = ?", false, user.Id)
+}
+
+func (gs *GroupStore) IncomingPaymentRequestCount(user *models.User) (int64, error) {
+	var count int64
+	err := gs.db.Model(&models.PaymentRequest{}).Where("payer_is_bank = ? AND payer_id = ?", false, user.Id).Count(&count).Error
+	return count, err
+}
+
+func (gs *GroupStore) GetOutgoingPaymentRequests(user *models.User, page, pageSize int, oldestFirst bool) ([]models.PaymentRequest, error) {
+	return gs.getPaymentRequests(page, pageSize, oldestFirst, "requester_id = ?", user.Id)
+}
+
+func (gs *GroupStore) OutgoingPaymentRequestCount(user *models.User) (int64, error) {
+	var count int64
+	err := gs.db.Model(&models.PaymentRequest{}).Where("requester_id = ?", user.Id).Count(&count).Error
+	return count, err
+}
+
+func (gs *GroupStore) GetBankPaymentRequests(group *models.Group, page, pageSize int, oldestFirst bool) ([]models.PaymentRequest, error) {
+	return gs.getPaymentRequests(page, pageSize, oldestFirst, "group_id = ? AND payer_is_bank = ?", group.Id, true)
+}
+
+func (gs *GroupStore) BankPaymentRequestCount(group *models.Group) (int64, error) {
+	var count int64
+	err := gs.db.Model(&models.PaymentRequest{}).Where("group_id = ? AND payer_is_bank = ?", group.Id, true).Count(&count).Error
+	return count, err
+}
+
+func (gs *GroupStore) ApprovePaymentRequest(group *models.Group, request *models.PaymentRequest) (*models.TransactionLogEntry, error) {
+	var transaction *models.TransactionLogEntry
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+
+		// prevents paying a request twice if it is approved concurrently
+		result := tx.Delete(request)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrPaymentRequestHandled
+		}
+
+		var payer *models.User
+		if !request.PayerIsBank {
+			payer = &models.User{Base: models.Base{Id: request.PayerId}}
+		}
+		receiver := &models.User{Base: models.Base{Id: request.RequesterId}}
+
+		transaction, err = createTransaction(tx, group, request.PayerIsBank, false, payer, receiver, request.Title, request.Description, request.Amount, "", true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// Approves a payment request of a member that needs to be approved by an admin by converting it into a pending transaction.
+func (gs *GroupStore) ApprovePaymentRequestPending(group *models.Group, request *models.PaymentRequest) (*models.PendingTransaction, error) {
+	pending := &models.PendingTransaction{
+		Title:       request.Title,
+		Description: request.Description,
+		Amount:      request.Amount,
+		GroupId:     group.Id,
+		SenderId:    request.PayerId,
+		ReceiverId:  request.RequesterId,
+	}
+
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+
+		// prevents paying a request twice if it is approved concurrently
+		result := tx.Delete(request)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrPaymentRequestHandled
+		}
+
+		return createPendingTransaction(tx, group, pending)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+func (gs *GroupStore) DeletePaymentRequest(request *models.PaymentRequest) error {
+	return gs.db.Delete(request).Error
+}
+
 func (gs *GroupStore) GetPaymentPlans(group *models.Group, user *models.User, searchInput string, page, pageSize int, descending bool) ([]models.PaymentPlan, error) {
 	var paymentPlans []models.PaymentPlan
 	var err error
@@ -672,7 +1938,7 @@ func (gs *GroupStore) BankPaymentPlanCount(group *models.Group) (int64, error) {
 
 func (gs *GroupStore) GetPaymentPlansThatNeedToBeExecuted() ([]models.PaymentPlan, error) {
 	var paymentPlans []models.PaymentPlan
-	err := gs.db.Find(&paymentPlans, "next_execute <= ?", time.Now().Unix()).Error
+	err := gs.db.Where("group_id NOT IN (?)", gs.db.Model(&models.Group{}).Select("id").Where("archived = ?", true)).Find(&paymentPlans, "next_execute <= ? AND paused = ?", time.Now().Unix(), false).Error
 	return paymentPlans, err
 }
 
@@ -691,19 +1957,26 @@ func (gs *GroupStore) GetPaymentPlanById(group *models.Group, id string) (*model
 	return &paymentPlan, nil
 }
 
-func (gs *GroupStore) CreatePaymentPlan(group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, name, description string, amount, paymentCount, schedule int, scheduleUnit string, firstPayment int64) (*models.PaymentPlan, error) {
+func (gs *GroupStore) CreatePaymentPlan(group *models.Group, senderIsBank, receiverIsBank bool, sender *models.User, receiver *models.User, name, description string, amount, paymentCount, schedule int, scheduleUnit, scheduleRule string, weekdaysOnly bool, firstPayment, endDate int64, failurePolicy string) (*models.PaymentPlan, error) {
 	paymentPlan := models.PaymentPlan{
+		FailurePolicy:  failurePolicy,
 		Name:           name,
 		Description:    description,
 		Amount:         amount,
 		PaymentCount:   paymentCount,
-		NextExecute:    firstPayment,
+		FirstPayment:   firstPayment,
+		EndDate:        endDate,
 		Schedule:       schedule,
 		ScheduleUnit:   scheduleUnit,
+		ScheduleRule:   scheduleRule,
+		WeekdaysOnly:   weekdaysOnly,
+		TimeZone:       group.TimeZone,
 		SenderIsBank:   senderIsBank,
 		ReceiverIsBank: receiverIsBank,
 		GroupId:        group.Id,
 	}
+	// the first payment might be moved by the schedule rule (e.g. to the last day of the month)
+	paymentPlan.NextExecute = paymentPlan.PaymentSchedule().Occurrence(0)
 
 	if !senderIsBank {
 		paymentPlan.SenderId = sender.Id
@@ -719,30 +1992,356 @@ func (gs *GroupStore) CreatePaymentPlan(group *models.Group, senderIsBank, recei
 }
 
 func (gs *GroupStore) UpdatePaymentPlan(paymentPlan *models.PaymentPlan) error {
-	return gs.db.Updates(paymentPlan).Error
+	// zero values like WeekdaysOnly = false or EndDate = 0 have to be written too
+	return gs.db.Model(paymentPlan).Select("name", "description", "amount", "next_execute", "schedule", "schedule_unit", "schedule_rule", "weekdays_only", "first_payment", "end_date", "failure_policy").Updates(paymentPlan).Error
 }
 
 func (gs *GroupStore) DeletePaymentPlan(paymentPlan *models.PaymentPlan) error {
-	gs.db.Model(&models.TransactionLogEntry{}).Where("payment_plan_id = ?", paymentPlan.Id).Update("payment_plan_id", "")
-	return gs.db.Delete(paymentPlan).Error
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		return finishPaymentPlan(tx, paymentPlan)
+	})
+}
+
+// Deletes a payment plan together with its executions, which can't be listed without the plan.
+// Its transactions are kept. Must be called inside of a database transaction.
+func finishPaymentPlan(tx *gorm.DB, paymentPlan *models.PaymentPlan) error {
+	err := tx.Model(&models.TransactionLogEntry{}).Where("payment_plan_id = ?", paymentPlan.Id).Update("payment_plan_id", "").Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.PaymentPlanExecution{}, "payment_plan_id = ?", paymentPlan.Id).Error
+	if err != nil {
+		return err
+	}
+	return tx.Delete(paymentPlan).Error
 }
 
+func (gs *GroupStore) ExecutePaymentPlan(paymentPlan *models.PaymentPlan) (*models.TransactionLogEntry, error) {
+	var transaction *models.TransactionLogEntry
+	var locked models.PaymentPlan
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		// the plan is reloaded inside of the transaction so that concurrent schedulers don't pay the same occurrence twice
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", paymentPlan.Id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrPaymentPlanAlreadyExecuted
+		}
+		if err != nil {
+			return err
+		}
+		if locked.NextExecute != paymentPlan.NextExecute {
+			return models.ErrPaymentPlanAlreadyExecuted
+		}
+		if locked.Paused {
+			return models.ErrPaymentPlanPaused
+		}
+
+		var execution models.PaymentPlanExecution
+		err = tx.Where("payment_plan_id = ? AND scheduled = ?", locked.Id, locked.NextExecute).Limit(1).Find(&execution).Error
+		if err != nil {
+			return err
+		}
+		if execution.Status == models.PaymentPlanExecutionSuccess {
+			return models.ErrPaymentPlanAlreadyExecuted
+		}
+
+		group := &models.Group{Base: models.Base{Id: locked.GroupId}}
+		err = checkNotArchived(tx, group)
+		if err != nil {
+			return err
+		}
+
+		sender := &models.User{Base: models.Base{Id: locked.SenderId}}
+		receiver := &models.User{Base: models.Base{Id: locked.ReceiverId}}
+		transaction, err = createTransaction(tx, group, locked.SenderIsBank, locked.ReceiverIsBank, sender, receiver, locked.Name, locked.Description, locked.Amount, locked.Id, true)
+		if err != nil {
+			return err
+		}
+
+		if execution.Id == "" {
+			err = tx.Create(&models.PaymentPlanExecution{
+				PaymentPlanId: locked.Id,
+				Scheduled:     locked.NextExecute,
+				GroupId:       locked.GroupId,
+				TransactionId: transaction.Id,
+				Status:        models.PaymentPlanExecutionSuccess,
+				Attempts:      1,
+				LastAttempt:   time.Now().Unix(),
+			}).Error
+		} else {
+			// an earlier attempt failed
+			err = tx.Model(&execution).Updates(map[string]interface{}{
+				"transaction_id": transaction.Id,
+				"status":         models.PaymentPlanExecutionSuccess,
+				"message":        "",
+				"attempts":       execution.Attempts + 1,
+				"last_attempt":   time.Now().Unix(),
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		locked.NextExecute = locked.PaymentSchedule().Next(locked.NextExecute)
+		if locked.PaymentCount >= 0 {
+			locked.PaymentCount -= 1
+			if locked.PaymentCount < 0 {
+				locked.PaymentCount = 0
+			}
+		}
+		if locked.Finished() {
+			return finishPaymentPlan(tx, &locked)
+		}
+		return tx.Model(&locked).Updates(map[string]interface{}{
+			"next_execute":  locked.NextExecute,
+			"payment_count": locked.PaymentCount,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	*paymentPlan = locked
+	return transaction, nil
+}
+
+func (gs *GroupStore) RecordPaymentPlanFailure(paymentPlan *models.PaymentPlan, status, message string) (*models.PaymentPlanExecution, error) {
+	var execution models.PaymentPlanExecution
+	var locked models.PaymentPlan
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", paymentPlan.Id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrPaymentPlanAlreadyExecuted
+		}
+		if err != nil {
+			return err
+		}
+		if locked.NextExecute != paymentPlan.NextExecute {
+			return models.ErrPaymentPlanAlreadyExecuted
+		}
+
+		err = tx.Where("payment_plan_id = ? AND scheduled = ?", locked.Id, locked.NextExecute).Limit(1).Find(&execution).Error
+		if err != nil {
+			return err
+		}
+		if execution.Status == models.PaymentPlanExecutionSuccess {
+			return models.ErrPaymentPlanAlreadyExecuted
+		}
+
+		now := time.Now().Unix()
+		if execution.Id == "" {
+			execution = models.PaymentPlanExecution{
+				PaymentPlanId: locked.Id,
+				Scheduled:     locked.NextExecute,
+				GroupId:       locked.GroupId,
+				Status:        status,
+				Message:       message,
+				Attempts:      1,
+				LastAttempt:   now,
+			}
+			err = tx.Create(&execution).Error
+		} else {
+			execution.Status = status
+			execution.Message = message
+			execution.Attempts++
+			execution.LastAttempt = now
+			err = tx.Model(&execution).Updates(map[string]interface{}{
+				"status":       execution.Status,
+				"message":      execution.Message,
+				"attempts":     execution.Attempts,
+				"last_attempt": execution.LastAttempt,
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		switch locked.FailurePolicy {
+		case models.PaymentPlanFailureSkip:
+			locked.NextExecute = locked.PaymentSchedule().Next(locked.NextExecute)
+			if locked.Finished() {
+				return finishPaymentPlan(tx, &locked)
+			}
+			return tx.Model(&locked).Update("next_execute", locked.NextExecute).Error
+		case models.PaymentPlanFailurePause:
+			locked.Paused = true
+			return tx.Model(&locked).Update("paused", true).Error
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	*paymentPlan = locked
+	return &execution, nil
+}
+
+func (gs *GroupStore) SetPaymentPlanPaused(paymentPlan *models.PaymentPlan, paused, catchUp bool) error {
+	var locked models.PaymentPlan
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", paymentPlan.Id).Error
+		if err != nil {
+			return err
+		}
+
+		locked.Paused = paused
+		if !paused && !catchUp && locked.NextExecute <= time.Now().Unix() {
+			locked.NextExecute = locked.PaymentSchedule().Next(time.Now().Unix())
+			if locked.Finished() {
+				return finishPaymentPlan(tx, &locked)
+			}
+		}
+		return tx.Model(&locked).Updates(map[string]interface{}{
+			"paused":       locked.Paused,
+			"next_execute": locked.NextExecute,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	*paymentPlan = locked
+	return nil
+}
+
+func (gs *GroupStore) SkipPaymentPlanPayments(paymentPlan *models.PaymentPlan, count int) error {
+	var locked models.PaymentPlan
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", paymentPlan.Id).Error
+		if err != nil {
+			return err
+		}
+
+		schedule := locked.PaymentSchedule()
+		for i := 0; i < count; i++ {
+			locked.NextExecute = schedule.Next(locked.NextExecute)
+		}
+		if locked.Finished() {
+			return finishPaymentPlan(tx, &locked)
+		}
+		return tx.Model(&locked).Update("next_execute", locked.NextExecute).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	*paymentPlan = locked
+	return nil
+}
+
+func (gs *GroupStore) GetPaymentPlanExecutions(paymentPlan *models.PaymentPlan, page, pageSize int, oldestFirst bool) ([]models.PaymentPlanExecution, error) {
+	order := "DESC"
+	if oldestFirst {
+		order = "ASC"
+	}
+
+	var executions []models.PaymentPlanExecution
+	var err error
+	if page < 0 || pageSize < 0 {
+		err = gs.db.Where("payment_plan_id = ?", paymentPlan.Id).Order("scheduled " + order).Find(&executions).Error
+	} else {
+		err = gs.db.Where("payment_plan_id = ?", paymentPlan.Id).Order("scheduled " + order).Offset(page * pageSize).Limit(pageSize).Find(&executions).Error
+	}
+	return executions, err
+}
+
+func (gs *GroupStore) PaymentPlanExecutionCount(paymentPlan *models.PaymentPlan) (int64, error) {
+	var count int64
+	err := gs.db.Model(&models.PaymentPlanExecution{}).Where("payment_plan_id = ?", paymentPlan.Id).Count(&count).Error
+	return count, err
+}
+
+func (gs *GroupStore) AcquireLease(name, holder string, duration time.Duration) (bool, error) {
+	acquired := false
+	err := gs.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Lease{
+			Name:    name,
+			Holder:  holder,
+			Expires: now.Add(duration).Unix(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			acquired = true
+			return nil
+		}
+
+		var lease models.Lease
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lease, "name = ?", name).Error
+		if err != nil {
+			return err
+		}
+		if lease.Holder != holder && lease.Expires > now.Unix() {
+			return nil
+		}
+		acquired = true
+		return tx.Model(&lease).Updates(map[string]interface{}{
+			"holder":  holder,
+			"expires": now.Add(duration).Unix(),
+		}).Error
+	})
+	return acquired, err
+}
+
+func (gs *GroupStore) ReleaseLease(name, holder string) error {
+	return gs.db.Model(&models.Lease{}).Where("name = ? AND holder = ?", name, holder).Update("expires", 0).Error
+}
+
+// Includes the savings goals of the members.
 func (gs *GroupStore) GetTotalMoney(group *models.Group) (int, error) {
-	users, err := gs.GetMembers(nil, "", group, -1, -1, false)
+	var total int
+	err := gs.db.Model(&models.GroupMembership{}).Select("COALESCE(SUM(balance), 0)").Where("group_id = ? AND is_member = ?", group.Id, true).Scan(&total).Error
 	if err != nil {
 		return 0, err
 	}
 
-	total := 0
-	for _, u := range users {
-		balance, err := gs.GetUserBalance(group, &u)
-		if err != nil {
-			return 0, err
+	var savings int
+	err = gs.db.Model(&models.SavingsGoal{}).Select("COALESCE(SUM(balance), 0)").Where("group_id = ? AND user_id IN (?)", group.Id, gs.db.Model(&models.GroupMembership{}).Select("user_id").Where("group_id = ? AND is_member = ?", group.Id, true)).Scan(&savings).Error
+	return total + savings, err
+}
+
+func (gs *GroupStore) GetBalanceDrifts(group *models.Group) ([]models.BalanceDrift, error) {
+	logBalances, err := getLogBalances(gs.db, group)
+	if err != nil {
+		return nil, err
+	}
+
+	var memberships []models.GroupMembership
+	if group != nil {
+		err = gs.db.Select("group_id", "user_id", "balance").Find(&memberships, "group_id = ?", group.Id).Error
+	} else {
+		err = gs.db.Select("group_id", "user_id", "balance").Find(&memberships).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		groupId string
+		userId  string
+	}
+	balances := make(map[key]int, len(logBalances))
+	for _, b := range logBalances {
+		balances[key{b.GroupId, b.UserId}] = b.Balance
+	}
+
+	drifts := make([]models.BalanceDrift, 0)
+	for _, m := range memberships {
+		logBalance := balances[key{m.GroupId, m.UserId}]
+		if m.Balance != logBalance {
+			drifts = append(drifts, models.BalanceDrift{
+				GroupId:    m.GroupId,
+				UserId:     m.UserId,
+				Balance:    m.Balance,
+				LogBalance: logBalance,
+			})
 		}
-		total += balance
 	}
 
-	return total, nil
+	return drifts, nil
 }
 
 func (gs *GroupStore) AreInSameGroup(userId1, userId2 string) (bool, error) {
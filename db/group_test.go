@@ -0,0 +1,986 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juho05/h-bank/models"
+)
+
+func TestGroupStore_CreateTransaction_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	const initialBalance = 1000
+	users := make([]*models.User, 4)
+	for i := range users {
+		users[i] = &models.User{
+			Name:  fmt.Sprintf("user%d", i),
+			Email: fmt.Sprintf("user%d@gmail.com", i),
+		}
+		us.Create(users[i])
+		gs.AddMember(group, users[i])
+		_, err = gs.CreateTransaction(group, true, false, nil, users[i], "initial", "", initialBalance)
+		if err != nil {
+			t.Fatalf("Couldn't fund user: %s", err)
+		}
+	}
+
+	const transferCount = 300
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	unexpectedErrors := make([]error, 0)
+	successful := 0
+	for i := 0; i < transferCount; i++ {
+		sender := users[rand.Intn(len(users))]
+		receiver := users[rand.Intn(len(users))]
+		for receiver == sender {
+			receiver = users[rand.Intn(len(users))]
+		}
+		amount := rand.Intn(400) + 1
+		senderIsBank := i%10 == 0
+		receiverIsBank := i%10 == 5
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := gs.CreateTransaction(group, senderIsBank, receiverIsBank, sender, receiver, "transfer", "", amount)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err == nil {
+				successful++
+			} else if !errors.Is(err, models.ErrNotEnoughMoney) {
+				unexpectedErrors = append(unexpectedErrors, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, unexpectedErrors)
+
+	var entries []models.TransactionLogEntry
+	database.Find(&entries, "group_id = ?", group.Id)
+	assert.Equal(t, len(users)+successful, len(entries))
+
+	bankBalance := 0
+	for _, e := range entries {
+		if e.SenderIsBank {
+			bankBalance += e.BalanceDifferenceSender
+		}
+		if e.ReceiverIsBank {
+			bankBalance += e.BalanceDifferenceReceiver
+		}
+	}
+
+	total := 0
+	for _, u := range users {
+		balance, err := gs.GetUserBalance(group, u)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, balance, 0)
+		total += balance
+
+		// every entry must start from the balance left behind by exactly one other entry,
+		// otherwise two transactions were computed from the same old balance
+		chain := make(map[int]int)
+		chain[0]--
+		chain[balance]++
+		for _, e := range entries {
+			if !e.SenderIsBank && e.SenderId == u.Id {
+				assert.GreaterOrEqual(t, e.NewBalanceSender, 0)
+				chain[e.NewBalanceSender-e.BalanceDifferenceSender]++
+				chain[e.NewBalanceSender]--
+			}
+			if !e.ReceiverIsBank && e.ReceiverId == u.Id {
+				chain[e.NewBalanceReceiver-e.BalanceDifferenceReceiver]++
+				chain[e.NewBalanceReceiver]--
+			}
+		}
+		for b, c := range chain {
+			assert.Equal(t, 0, c, "inconsistent ledger of %s at balance %d", u.Name, b)
+		}
+	}
+
+	assert.Equal(t, 0, total+bankBalance)
+
+	totalMoney, err := gs.GetTotalMoney(group)
+	assert.NoError(t, err)
+	assert.Equal(t, total, totalMoney)
+
+	drifts, err := gs.GetBalanceDrifts(group)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestGroupStore_GetBalanceDrifts(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user1 := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user1)
+	gs.AddMember(group, user1)
+
+	user2 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user2)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 500)
+	gs.CreateTransaction(group, false, false, user1, user2, "gift", "", 200)
+
+	drifts, err := gs.GetBalanceDrifts(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	database.Model(&models.GroupMembership{}).Where("user_id = ?", user2.Id).Update("balance", 50)
+
+	drifts, err = gs.GetBalanceDrifts(group)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BalanceDrift{{GroupId: group.Id, UserId: user2.Id, Balance: 50, LogBalance: 200}}, drifts)
+
+	err = database.Transaction(recomputeBalances)
+	assert.NoError(t, err)
+
+	drifts, err = gs.GetBalanceDrifts(group)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	balance, err := gs.GetUserBalance(group, user1)
+	assert.NoError(t, err)
+	assert.Equal(t, 300, balance)
+
+	group, _ = gs.GetById(group.Id)
+	gs.RemoveMember(group, user2)
+	gs.AddMember(group, user2)
+
+	balance, err = gs.GetUserBalance(group, user2)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, balance)
+}
+
+func TestGroupStore_ReverseTransaction_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user1 := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user1)
+	gs.AddMember(group, user1)
+
+	user2 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user2)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 100)
+	gs.CreateTransaction(group, true, false, nil, user2, "pocket money", "", 1000)
+	transaction, err := gs.CreateTransaction(group, false, false, user1, user2, "gift", "", 100)
+	if err != nil {
+		t.Fatalf("Couldn't create transaction: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	unexpectedErrors := make([]error, 0)
+	successful := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry := *transaction
+			_, err := gs.ReverseTransaction(group, &entry)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err == nil {
+				successful++
+			} else if !errors.Is(err, models.ErrTransactionReversed) {
+				unexpectedErrors = append(unexpectedErrors, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, unexpectedErrors)
+	assert.Equal(t, 1, successful)
+
+	transaction, _ = gs.GetTransactionLogEntryById(group, transaction.Id)
+	reversal, _ := gs.GetTransactionLogEntryById(group, transaction.ReversedById)
+	if assert.NotNil(t, reversal) {
+		assert.Equal(t, transaction.Id, reversal.ReversalOfId)
+		assert.Equal(t, user2.Id, reversal.SenderId)
+		assert.Equal(t, user1.Id, reversal.ReceiverId)
+		assert.Equal(t, 100, reversal.Amount)
+	}
+
+	_, err = gs.ReverseTransaction(group, reversal)
+	assert.ErrorIs(t, err, models.ErrTransactionIsReversal)
+
+	balance, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 100, balance)
+	balance, _ = gs.GetUserBalance(group, user2)
+	assert.Equal(t, 1000, balance)
+}
+
+func TestGroupStore_PayInterest_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user1 := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user1)
+	gs.AddMember(group, user1)
+
+	user2 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user2)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 1000)
+	gs.CreateTransaction(group, true, false, nil, user2, "pocket money", "", 100)
+
+	err = gs.SetInterest(group, 1200, models.ScheduleUnitMonth, 500)
+	if err != nil {
+		t.Fatalf("Couldn't set interest: %s", err)
+	}
+	// three periods are due
+	err = database.Model(group).Update("interest_next_payout", time.Now().AddDate(0, -2, 0).Unix()).Error
+	if err != nil {
+		t.Fatalf("Couldn't update next payout: %s", err)
+	}
+
+	groups, err := gs.GetGroupsWithDueInterest()
+	if assert.NoError(t, err) {
+		assert.Len(t, groups, 1)
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	unexpectedErrors := make([]error, 0)
+	paidPeriods := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			periods, err := gs.PayInterest(group)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				unexpectedErrors = append(unexpectedErrors, err)
+			}
+			paidPeriods += periods
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, unexpectedErrors)
+	assert.Equal(t, 3, paidPeriods)
+
+	balance1, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 1030, balance1, "interest should be compounded every period")
+	balance2, _ := gs.GetUserBalance(group, user2)
+	assert.Equal(t, 100, balance2, "balances below the minimum balance should not earn interest")
+
+	groups, err = gs.GetGroupsWithDueInterest()
+	if assert.NoError(t, err) {
+		assert.Empty(t, groups)
+	}
+}
+
+func TestGroupStore_PayInterest_SmallBalances(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user1 := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user1)
+	gs.AddMember(group, user1)
+
+	user2 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user2)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 1000)
+	gs.CreateTransaction(group, true, false, nil, user2, "pocket money", "", 10000)
+
+	// 5 % per year paid daily: €10 earn 0.137 ct and €100 earn 1.37 ct per day
+	err = gs.SetInterest(group, 500, models.ScheduleUnitDay, 0)
+	if err != nil {
+		t.Fatalf("Couldn't set interest: %s", err)
+	}
+	// eight periods are due
+	err = database.Model(group).Update("interest_next_payout", time.Now().AddDate(0, 0, -7).Unix()).Error
+	if err != nil {
+		t.Fatalf("Couldn't update next payout: %s", err)
+	}
+
+	periods, err := gs.PayInterest(group)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, periods)
+
+	balance1, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 1001, balance1, "fractions of a cent should be carried over to the next period")
+	balance2, _ := gs.GetUserBalance(group, user2)
+	assert.Equal(t, 10010, balance2, "fractions of a cent should be carried over to the next period")
+
+	var membership models.GroupMembership
+	database.First(&membership, "group_id = ? AND user_id = ?", group.Id, user2.Id)
+	assert.Equal(t, 3517500, membership.InterestRemainder)
+
+	err = gs.SetInterest(group, 500, models.ScheduleUnitMonth, 0)
+	assert.NoError(t, err)
+	database.First(&membership, "group_id = ? AND user_id = ?", group.Id, user2.Id)
+	assert.Zero(t, membership.InterestRemainder, "remainders should be reset when the period changes")
+}
+
+func TestGroupStore_PayInterest_Yearly(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name:     "group",
+		TimeZone: "Europe/Berlin",
+	}
+	gs.Create(group)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+	gs.AddMember(group, user)
+
+	gs.CreateTransaction(group, true, false, nil, user, "pocket money", "", 10000)
+
+	err = gs.SetInterest(group, 500, models.ScheduleUnitYear, 0)
+	if err != nil {
+		t.Fatalf("Couldn't set interest: %s", err)
+	}
+	now := time.Now().In(group.Location())
+	assert.Equal(t, time.Date(now.Year()+1, time.January, 1, 0, 0, 0, 0, group.Location()).Unix(), group.Interest.NextPayout)
+
+	periods, err := gs.PayInterest(group)
+	assert.NoError(t, err)
+	assert.Zero(t, periods, "the first payout should be at the end of the current year")
+
+	// the current year is over
+	err = database.Model(group).Update("interest_next_payout", time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, group.Location()).Unix()).Error
+	if err != nil {
+		t.Fatalf("Couldn't update next payout: %s", err)
+	}
+
+	periods, err = gs.PayInterest(group)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, periods)
+
+	balance, _ := gs.GetUserBalance(group, user)
+	assert.Equal(t, 10500, balance)
+
+	var interest models.TransactionLogEntry
+	err = database.First(&interest, "group_id = ? AND amount = ?", group.Id, 500).Error
+	if assert.NoError(t, err) {
+		assert.Equal(t, models.TransactionKindInterest, interest.Kind)
+		assert.Empty(t, interest.Title, "the title of interest should be translated from its kind")
+	}
+
+	updated, _ := gs.GetById(group.Id)
+	assert.Equal(t, time.Date(now.Year()+1, time.January, 1, 0, 0, 0, 0, group.Location()).Unix(), updated.Interest.NextPayout)
+}
+
+func TestGroupStore_ExecutePaymentPlan_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+	gs.AddMember(group, user)
+
+	firstPayment := time.Now().AddDate(0, 0, -1).Unix()
+	paymentPlan, err := gs.CreatePaymentPlan(group, true, false, nil, user, "pocket money", "", 100, 2, 1, models.ScheduleUnitDay, models.ScheduleRuleDate, false, firstPayment, 0, models.PaymentPlanFailureRetry)
+	if err != nil {
+		t.Fatalf("Couldn't create payment plan: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	unexpectedErrors := make([]error, 0)
+	successful := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			plan := *paymentPlan
+			_, err := gs.ExecutePaymentPlan(&plan)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err == nil {
+				successful++
+			} else if !errors.Is(err, models.ErrPaymentPlanAlreadyExecuted) {
+				unexpectedErrors = append(unexpectedErrors, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, unexpectedErrors)
+	assert.Equal(t, 1, successful)
+
+	balance, _ := gs.GetUserBalance(group, user)
+	assert.Equal(t, 100, balance)
+
+	paymentPlan, _ = gs.GetPaymentPlanById(group, paymentPlan.Id)
+	if assert.NotNil(t, paymentPlan) {
+		assert.Equal(t, firstPayment+24*60*60, paymentPlan.NextExecute)
+		assert.Equal(t, 1, paymentPlan.PaymentCount)
+
+		var executions int64
+		database.Model(&models.PaymentPlanExecution{}).Where("payment_plan_id = ?", paymentPlan.Id).Count(&executions)
+		assert.EqualValues(t, 1, executions)
+
+		// the last payment deletes the plan and its executions
+		_, err = gs.ExecutePaymentPlan(paymentPlan)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, paymentPlan.PaymentCount)
+		deleted, _ := gs.GetPaymentPlanById(group, paymentPlan.Id)
+		assert.Nil(t, deleted)
+		database.Model(&models.PaymentPlanExecution{}).Where("payment_plan_id = ?", paymentPlan.Id).Count(&executions)
+		assert.EqualValues(t, 0, executions)
+	}
+}
+
+func TestGroupStore_ExecutePaymentPlan_Schedule(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+	gs.AddMember(group, user)
+
+	firstPayment := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC).Unix()
+	endDate := time.Date(2023, 4, 30, 23, 59, 59, 0, time.UTC).Unix()
+	paymentPlan, err := gs.CreatePaymentPlan(group, true, false, nil, user, "pocket money", "", 100, -1, 1, models.ScheduleUnitMonth, models.ScheduleRuleDate, false, firstPayment, endDate, models.PaymentPlanFailureRetry)
+	if err != nil {
+		t.Fatalf("Couldn't create payment plan: %s", err)
+	}
+
+	want := []time.Time{
+		time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 4, 30, 0, 0, 0, 0, time.UTC),
+	}
+	for _, w := range want {
+		if !assert.False(t, paymentPlan.Finished()) {
+			break
+		}
+		assert.Equal(t, w.Unix(), paymentPlan.NextExecute)
+		_, err = gs.ExecutePaymentPlan(paymentPlan)
+		assert.NoError(t, err)
+	}
+	assert.True(t, paymentPlan.Finished())
+
+	deleted, _ := gs.GetPaymentPlanById(group, paymentPlan.Id)
+	assert.Nil(t, deleted)
+
+	balance, _ := gs.GetUserBalance(group, user)
+	assert.Equal(t, 400, balance)
+}
+
+func TestGroupStore_SetTimeZone(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name:     "group",
+		TimeZone: "UTC",
+	}
+	gs.Create(group)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+	gs.AddMember(group, user)
+
+	firstPayment := time.Date(2030, 1, 1, 7, 0, 0, 0, time.UTC).Unix()
+	paymentPlan, err := gs.CreatePaymentPlan(group, true, false, nil, user, "pocket money", "", 100, -1, 1, models.ScheduleUnitMonth, models.ScheduleRuleDate, false, firstPayment, 0, models.PaymentPlanFailureRetry)
+	if err != nil {
+		t.Fatalf("Couldn't create payment plan: %s", err)
+	}
+	assert.Equal(t, "UTC", paymentPlan.TimeZone)
+
+	assert.Error(t, gs.SetTimeZone(group, "Mars/Olympus_Mons"))
+
+	err = gs.SetTimeZone(group, "Europe/Berlin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Europe/Berlin", group.TimeZone)
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	paymentPlan, _ = gs.GetPaymentPlanById(group, paymentPlan.Id)
+	if assert.NotNil(t, paymentPlan) {
+		assert.Equal(t, "Europe/Berlin", paymentPlan.TimeZone)
+		assert.Equal(t, time.Date(2030, 1, 1, 7, 0, 0, 0, berlin).Unix(), paymentPlan.FirstPayment)
+		assert.Equal(t, time.Date(2030, 1, 1, 7, 0, 0, 0, berlin).Unix(), paymentPlan.NextExecute)
+		// the local time of day is kept after the change to summer time
+		assert.Equal(t, time.Date(2030, 4, 1, 7, 0, 0, 0, berlin).Unix(), paymentPlan.PaymentSchedule().Occurrence(3))
+	}
+}
+
+func TestGroupStore_SpendingLimits_PendingTransactions(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user1 := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user1)
+	gs.AddMember(group, user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 1000)
+	gs.SetSpendingLimits(group, user1, models.SpendingLimits{MaxPerDay: 300})
+
+	pending, err := gs.CreatePendingTransaction(group, user1, false, user2, "bike", "", 200)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the reserved amount counts towards the daily limit
+	_, err = gs.CreateTransaction(group, false, false, user1, user2, "cinema", "", 150)
+	var limitErr *models.SpendingLimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, models.ScheduleUnitDay, limitErr.Limit)
+	}
+	_, err = gs.CreatePendingTransaction(group, user1, false, user2, "cinema", "", 150)
+	assert.ErrorAs(t, err, &limitErr)
+
+	_, err = gs.CreateTransaction(group, false, false, user1, user2, "cinema", "", 100)
+	assert.NoError(t, err)
+
+	// approving the pending transaction doesn't count it twice
+	_, err = gs.ApprovePendingTransaction(group, pending)
+	assert.NoError(t, err)
+
+	balance, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 700, balance)
+}
+
+func TestGroupStore_RecordPaymentPlanFailure(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+	gs.AddMember(group, user)
+
+	firstPayment := time.Now().AddDate(0, 0, -1).Unix()
+	createPlan := func(policy string) *models.PaymentPlan {
+		paymentPlan, err := gs.CreatePaymentPlan(group, false, true, user, nil, "plan", "", 100, -1, 1, models.ScheduleUnitDay, models.ScheduleRuleDate, false, firstPayment, 0, policy)
+		if err != nil {
+			t.Fatalf("Couldn't create payment plan: %s", err)
+		}
+		return paymentPlan
+	}
+
+	t.Run("retry", func(t *testing.T) {
+		paymentPlan := createPlan(models.PaymentPlanFailureRetry)
+
+		_, err := gs.ExecutePaymentPlan(paymentPlan)
+		assert.ErrorIs(t, err, models.ErrNotEnoughMoney)
+
+		execution, err := gs.RecordPaymentPlanFailure(paymentPlan, models.PaymentPlanExecutionInsufficientFunds, err.Error())
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, execution.Attempts)
+			assert.Equal(t, models.PaymentPlanExecutionInsufficientFunds, execution.Status)
+		}
+		assert.Equal(t, firstPayment, paymentPlan.NextExecute)
+
+		execution, err = gs.RecordPaymentPlanFailure(paymentPlan, models.PaymentPlanExecutionInsufficientFunds, "not enough money")
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, execution.Attempts)
+		}
+
+		// a successful retry updates the failed execution
+		gs.CreateTransaction(group, true, false, nil, user, "money", "", 100)
+		_, err = gs.ExecutePaymentPlan(paymentPlan)
+		assert.NoError(t, err)
+		assert.Equal(t, firstPayment+24*60*60, paymentPlan.NextExecute)
+
+		executions, err := gs.GetPaymentPlanExecutions(paymentPlan, 0, 10, true)
+		if assert.NoError(t, err) && assert.Len(t, executions, 1) {
+			assert.Equal(t, models.PaymentPlanExecutionSuccess, executions[0].Status)
+			assert.Equal(t, 3, executions[0].Attempts)
+			assert.Equal(t, firstPayment, executions[0].Scheduled)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		paymentPlan := createPlan(models.PaymentPlanFailureSkip)
+
+		_, err := gs.RecordPaymentPlanFailure(paymentPlan, models.PaymentPlanExecutionError, "error")
+		assert.NoError(t, err)
+		assert.Equal(t, firstPayment+24*60*60, paymentPlan.NextExecute)
+		assert.Equal(t, -1, paymentPlan.PaymentCount)
+
+		count, err := gs.PaymentPlanExecutionCount(paymentPlan)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		paymentPlan := createPlan(models.PaymentPlanFailurePause)
+
+		_, err := gs.RecordPaymentPlanFailure(paymentPlan, models.PaymentPlanExecutionError, "error")
+		assert.NoError(t, err)
+		assert.True(t, paymentPlan.Paused)
+		assert.Equal(t, firstPayment, paymentPlan.NextExecute)
+
+		due, err := gs.GetPaymentPlansThatNeedToBeExecuted()
+		assert.NoError(t, err)
+		for _, p := range due {
+			assert.NotEqual(t, paymentPlan.Id, p.Id)
+		}
+	})
+}
+
+func TestGroupStore_SetPaymentPlanPaused(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := NewUserStore(database)
+	gs := NewGroupStore(database)
+
+	group := &models.Group{
+		Name: "group",
+	}
+	gs.Create(group)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+	gs.AddMember(group, user)
+
+	firstPayment := time.Now().AddDate(0, 0, -3).Unix()
+	paymentPlan, err := gs.CreatePaymentPlan(group, true, false, nil, user, "pocket money", "", 100, -1, 1, models.ScheduleUnitDay, models.ScheduleRuleDate, false, firstPayment, 0, models.PaymentPlanFailureRetry)
+	if err != nil {
+		t.Fatalf("Couldn't create payment plan: %s", err)
+	}
+
+	isDue := func() bool {
+		due, err := gs.GetPaymentPlansThatNeedToBeExecuted()
+		assert.NoError(t, err)
+		for _, p := range due {
+			if p.Id == paymentPlan.Id {
+				return true
+			}
+		}
+		return false
+	}
+
+	err = gs.SetPaymentPlanPaused(paymentPlan, true, false)
+	assert.NoError(t, err)
+	assert.True(t, paymentPlan.Paused)
+	assert.False(t, isDue())
+	_, err = gs.ExecutePaymentPlan(paymentPlan)
+	assert.ErrorIs(t, err, models.ErrPaymentPlanPaused)
+
+	// catching up keeps the missed payments
+	err = gs.SetPaymentPlanPaused(paymentPlan, false, true)
+	assert.NoError(t, err)
+	assert.False(t, paymentPlan.Paused)
+	assert.Equal(t, firstPayment, paymentPlan.NextExecute)
+	assert.True(t, isDue())
+
+	gs.SetPaymentPlanPaused(paymentPlan, true, false)
+	err = gs.SetPaymentPlanPaused(paymentPlan, false, false)
+	assert.NoError(t, err)
+	assert.Greater(t, paymentPlan.NextExecute, time.Now().Unix())
+	assert.Equal(t, time.Unix(firstPayment, 0).AddDate(0, 0, 4).Unix(), paymentPlan.NextExecute)
+	assert.False(t, isDue())
+
+	err = gs.SkipPaymentPlanPayments(paymentPlan, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(firstPayment, 0).AddDate(0, 0, 6).Unix(), paymentPlan.NextExecute)
+	assert.Equal(t, -1, paymentPlan.PaymentCount)
+
+	// skipping all remaining payments ends the plan
+	paymentPlan.EndDate = time.Unix(firstPayment, 0).AddDate(0, 0, 7).Unix()
+	gs.UpdatePaymentPlan(paymentPlan)
+	err = gs.SkipPaymentPlanPayments(paymentPlan, 2)
+	assert.NoError(t, err)
+	assert.True(t, paymentPlan.Finished())
+	deleted, _ := gs.GetPaymentPlanById(group, paymentPlan.Id)
+	assert.Nil(t, deleted)
+}
+
+func TestGroupStore_AcquireLease(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	gs := NewGroupStore(database)
+
+	acquired, err := gs.AcquireLease("test", "a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = gs.AcquireLease("test", "b", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	// renewal
+	acquired, err = gs.AcquireLease("test", "a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	assert.NoError(t, gs.ReleaseLease("test", "a"))
+	acquired, err = gs.AcquireLease("test", "b", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	// expired leases are taken over
+	acquired, err = gs.AcquireLease("expired", "a", -time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+	acquired, err = gs.AcquireLease("expired", "b", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+}
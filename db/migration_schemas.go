@@ -0,0 +1,315 @@
+package db
+
+// Snapshots of the models at the time a migration was written.
+// Migrations only use these types, because the types in the models package always describe the latest schema
+// and would change what old migrations create.
+
+// Tables of schema version 1.
+
+type userV1 struct {
+	Id                      string `gorm:"primaryKey"`
+	Created                 int64  `gorm:"autoCreateTime"`
+	Name                    string
+	Email                   string `gorm:"unique"`
+	PubliclyVisible         bool   `gorm:"default:true"`
+	DontSendInvitationEmail bool
+	CashLog                 []cashLogEntryV1    `gorm:"foreignKey:UserId"`
+	GroupMemberships        []groupMembershipV1 `gorm:"foreignKey:UserId"`
+	GroupInvitations        []groupInvitationV1 `gorm:"foreignKey:UserId"`
+	CashCurrency            string              `gorm:"not null;default:'EUR'"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type cashLogEntryV1 struct {
+	Id                 string `gorm:"primaryKey"`
+	Created            int64  `gorm:"autoCreateTime"`
+	ChangeTitle        string
+	ChangeDescription  string
+	TotalAmount        int
+	ChangeDifference   int
+	Currency           string                    `gorm:"not null;default:'EUR'"`
+	Denominations      []cashDenominationCountV1 `gorm:"foreignKey:CashLogEntryId;constraint:OnDelete:CASCADE"`
+	UserId             string
+	WalletId           string `gorm:"not null;default:''"`
+	MoveEntryId        string `gorm:"not null;default:''"`
+	TransactionId      string `gorm:"not null;default:''"`
+	TransactionGroupId string `gorm:"not null;default:''"`
+	CorrectionOfId     string `gorm:"not null;default:''"`
+}
+
+func (cashLogEntryV1) TableName() string { return "cash_log_entries" }
+
+type cashDenominationCountV1 struct {
+	Id             string `gorm:"primaryKey"`
+	Created        int64  `gorm:"autoCreateTime"`
+	CashLogEntryId string
+	Key            string
+	Count          int
+}
+
+func (cashDenominationCountV1) TableName() string { return "cash_denomination_counts" }
+
+type cashWalletV1 struct {
+	Id       string `gorm:"primaryKey"`
+	Created  int64  `gorm:"autoCreateTime"`
+	Name     string
+	Currency string
+	UserId   string
+}
+
+func (cashWalletV1) TableName() string { return "cash_wallets" }
+
+type cashLogEntryRevisionV1 struct {
+	Id                string `gorm:"primaryKey"`
+	Created           int64  `gorm:"autoCreateTime"`
+	CashLogEntryId    string
+	UserId            string
+	WalletId          string
+	Action            string
+	ChangeTitle       string
+	ChangeDescription string
+	Currency          string
+	TotalAmount       int
+	ChangeDifference  int
+	Denominations     string
+}
+
+func (cashLogEntryRevisionV1) TableName() string { return "cash_log_entry_revisions" }
+
+type groupV1 struct {
+	Id                   string `gorm:"primaryKey"`
+	Created              int64  `gorm:"autoCreateTime"`
+	Name                 string
+	Description          string
+	GroupPicture         *groupPictureV1 `gorm:"foreignKey:GroupId;constraint:OnDelete:CASCADE"`
+	GroupPictureId       string
+	Archived             bool
+	Currency             string              `gorm:"not null;default:'EUR'"`
+	ApprovalThreshold    int                 `gorm:"not null;default:0"`
+	ApproveBankTransfers bool                `gorm:"not null;default:false"`
+	Interest             interestV1          `gorm:"embedded;embeddedPrefix:interest_"`
+	Memberships          []groupMembershipV1 `gorm:"foreignKey:GroupId"`
+	Invitations          []groupInvitationV1 `gorm:"foreignKey:GroupId"`
+}
+
+func (groupV1) TableName() string { return "groups" }
+
+type interestV1 struct {
+	Rate       int    `gorm:"not null;default:0"`
+	Period     string `gorm:"not null;default:''"`
+	MinBalance int    `gorm:"not null;default:0"`
+	NextPayout int64  `gorm:"not null;default:0"`
+}
+
+type groupPictureV1 struct {
+	Id      string `gorm:"primaryKey"`
+	Created int64  `gorm:"autoCreateTime"`
+	Tiny    []byte
+	Small   []byte
+	Medium  []byte
+	Large   []byte
+	Huge    []byte
+	GroupId string
+}
+
+func (groupPictureV1) TableName() string { return "group_pictures" }
+
+type groupMembershipV1 struct {
+	Id             string `gorm:"primaryKey"`
+	Created        int64  `gorm:"autoCreateTime"`
+	GroupId        string
+	GroupName      string
+	UserId         string
+	UserName       string
+	IsMember       bool
+	IsAdmin        bool
+	Balance        int
+	Reserved       int              `gorm:"not null;default:0"`
+	SpendingLimits spendingLimitsV1 `gorm:"embedded"`
+}
+
+func (groupMembershipV1) TableName() string { return "group_memberships" }
+
+type spendingLimitsV1 struct {
+	MaxPerTransaction int `gorm:"not null;default:0"`
+	MaxPerDay         int `gorm:"not null;default:0"`
+	MaxPerWeek        int `gorm:"not null;default:0"`
+	MaxPerMonth       int `gorm:"not null;default:0"`
+	Overdraft         int `gorm:"not null;default:0"`
+}
+
+type groupInvitationV1 struct {
+	Id        string `gorm:"primaryKey"`
+	Created   int64  `gorm:"autoCreateTime"`
+	GroupName string
+	Message   string
+	GroupId   string
+	UserId    string
+}
+
+func (groupInvitationV1) TableName() string { return "group_invitations" }
+
+type paymentRequestV1 struct {
+	Id          string `gorm:"primaryKey"`
+	Created     int64  `gorm:"autoCreateTime"`
+	Title       string
+	Description string
+	Amount      int
+	GroupId     string
+	RequesterId string
+	PayerIsBank bool
+	PayerId     string
+}
+
+func (paymentRequestV1) TableName() string { return "payment_requests" }
+
+type pendingTransactionV1 struct {
+	Id             string `gorm:"primaryKey"`
+	Created        int64  `gorm:"autoCreateTime"`
+	Title          string
+	Description    string
+	Amount         int
+	GroupId        string
+	SenderId       string
+	ReceiverIsBank bool
+	ReceiverId     string
+}
+
+func (pendingTransactionV1) TableName() string { return "pending_transactions" }
+
+type savingsGoalV1 struct {
+	Id       string `gorm:"primaryKey"`
+	Created  int64  `gorm:"autoCreateTime"`
+	Name     string
+	Target   int
+	Deadline int64
+	Balance  int
+	GroupId  string
+	UserId   string
+}
+
+func (savingsGoalV1) TableName() string { return "savings_goals" }
+
+type transactionLogEntryV1 struct {
+	Id                        string `gorm:"primaryKey"`
+	Created                   int64  `gorm:"autoCreateTime"`
+	Title                     string
+	Description               string
+	Amount                    int
+	GroupId                   string
+	SenderIsBank              bool
+	SenderId                  string
+	NewBalanceSender          int
+	BalanceDifferenceSender   int
+	ReceiverIsBank            bool
+	ReceiverId                string
+	NewBalanceReceiver        int
+	BalanceDifferenceReceiver int
+	PaymentPlanId             string
+	ReversalOfId              string
+	ReversedById              string
+	SavingsGoalId             string
+	CashLogEntryId            string `gorm:"not null;default:''"`
+}
+
+func (transactionLogEntryV1) TableName() string { return "transaction_log_entries" }
+
+type paymentPlanV1 struct {
+	Id             string `gorm:"primaryKey"`
+	Created        int64  `gorm:"autoCreateTime"`
+	Name           string
+	Description    string
+	Amount         int
+	PaymentCount   int
+	NextExecute    int64
+	Schedule       int
+	ScheduleUnit   string
+	SenderIsBank   bool
+	SenderId       string
+	ReceiverIsBank bool
+	ReceiverId     string
+	GroupId        string
+}
+
+func (paymentPlanV1) TableName() string { return "payment_plans" }
+
+// Tables added by migration 2.
+
+type paymentPlanExecutionV2 struct {
+	Id            string `gorm:"primaryKey"`
+	Created       int64  `gorm:"autoCreateTime"`
+	PaymentPlanId string `gorm:"uniqueIndex:idx_payment_plan_execution"`
+	Scheduled     int64  `gorm:"uniqueIndex:idx_payment_plan_execution"`
+	GroupId       string
+	TransactionId string
+}
+
+func (paymentPlanExecutionV2) TableName() string { return "payment_plan_executions" }
+
+type leaseV2 struct {
+	Name    string `gorm:"primaryKey"`
+	Holder  string
+	Expires int64
+}
+
+func (leaseV2) TableName() string { return "leases" }
+
+// Columns added by migration 3.
+
+type paymentPlanV3 struct {
+	FailurePolicy string `gorm:"not null;default:'retry'"`
+	Paused        bool   `gorm:"not null;default:false"`
+}
+
+func (paymentPlanV3) TableName() string { return "payment_plans" }
+
+type paymentPlanExecutionV3 struct {
+	Status      string `gorm:"not null;default:'success'"`
+	Message     string `gorm:"not null;default:''"`
+	Attempts    int    `gorm:"not null;default:1"`
+	LastAttempt int64  `gorm:"not null;default:0"`
+}
+
+func (paymentPlanExecutionV3) TableName() string { return "payment_plan_executions" }
+
+// Columns added by migration 4.
+
+type paymentPlanV4 struct {
+	ScheduleRule string `gorm:"not null;default:'date'"`
+	WeekdaysOnly bool   `gorm:"not null;default:false"`
+	FirstPayment int64  `gorm:"not null;default:0"`
+	EndDate      int64  `gorm:"not null;default:0"`
+}
+
+func (paymentPlanV4) TableName() string { return "payment_plans" }
+
+// Columns added by migration 5.
+
+type groupV5 struct {
+	TimeZone string `gorm:"not null;default:'UTC'"`
+}
+
+func (groupV5) TableName() string { return "groups" }
+
+type paymentPlanV5 struct {
+	TimeZone string `gorm:"not null;default:'UTC'"`
+}
+
+func (paymentPlanV5) TableName() string { return "payment_plans" }
+
+// Columns added by migration 6.
+
+type groupMembershipV6 struct {
+	InterestRemainder int `gorm:"not null;default:0"`
+}
+
+func (groupMembershipV6) TableName() string { return "group_memberships" }
+
+// Columns added by migration 7.
+
+type transactionLogEntryV7 struct {
+	Kind string `gorm:"not null;default:''"`
+}
+
+func (transactionLogEntryV7) TableName() string { return "transaction_log_entries" }
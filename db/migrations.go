@@ -0,0 +1,267 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrSchemaTooNew = errors.New("the database schema is newer than the schema of this version of h-bank")
+
+// A numbered change of the database schema. Applied migrations are recorded in schema_migrations.
+// Migrations only use the snapshots of the models in migration_schemas.go.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Ordered by version. Never change or remove migrations which have been released.
+// Released migrations are listed in TestMigrations_Released; changes to them need a new migration instead.
+var migrations = []Migration{
+	{Version: 1, Name: "initial schema", Up: migrateInitialSchema, Down: dropTables(initialSchemaModels...)},
+	{Version: 2, Name: "payment plan executions and leases", Up: createTables(&paymentPlanExecutionV2{}, &leaseV2{}), Down: dropTables(&paymentPlanExecutionV2{}, &leaseV2{})},
+	{
+		Version: 3,
+		Name:    "payment plan failure policies",
+		Up: combine(
+			addColumns(&paymentPlanV3{}, "FailurePolicy", "Paused"),
+			addColumns(&paymentPlanExecutionV3{}, "Status", "Message", "Attempts", "LastAttempt"),
+		),
+		Down: combine(
+			dropColumns(&paymentPlanV3{}, "FailurePolicy", "Paused"),
+			dropColumns(&paymentPlanExecutionV3{}, "Status", "Message", "Attempts", "LastAttempt"),
+		),
+	},
+	{
+		Version: 4,
+		Name:    "payment plan schedule rules",
+		Up: combine(
+			addColumns(&paymentPlanV4{}, "ScheduleRule", "WeekdaysOnly", "FirstPayment", "EndDate"),
+			// existing plans continue from their next payment
+			func(tx *gorm.DB) error {
+				return tx.Table("payment_plans").Where("first_payment = ?", 0).Update("first_payment", gorm.Expr("next_execute")).Error
+			},
+		),
+		Down: dropColumns(&paymentPlanV4{}, "ScheduleRule", "WeekdaysOnly", "FirstPayment", "EndDate"),
+	},
+	{
+		Version: 5,
+		Name:    "time zones",
+		Up: combine(
+			addColumns(&groupV5{}, "TimeZone"),
+			addColumns(&paymentPlanV5{}, "TimeZone"),
+		),
+		Down: combine(
+			dropColumns(&groupV5{}, "TimeZone"),
+			dropColumns(&paymentPlanV5{}, "TimeZone"),
+		),
+	},
+	{Version: 6, Name: "interest remainders", Up: addColumns(&groupMembershipV6{}, "InterestRemainder"), Down: dropColumns(&groupMembershipV6{}, "InterestRemainder")},
+	{Version: 7, Name: "transaction kinds", Up: addColumns(&transactionLogEntryV7{}, "Kind"), Down: dropColumns(&transactionLogEntryV7{}, "Kind")},
+}
+
+type SchemaMigration struct {
+	Version int `gorm:"primaryKey;autoIncrement:false"`
+	Name    string
+	Applied int64
+}
+
+type MigrationStatus struct {
+	Version int
+	Name    string
+	// 0 if the migration is not applied
+	Applied int64
+}
+
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Returns the highest applied migration version or 0 for new databases.
+func SchemaVersion(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return 0, nil
+	}
+	var version int
+	err := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// Returns ErrSchemaTooNew if the database was migrated by a newer version of h-bank.
+func CheckSchemaVersion(db *gorm.DB) error {
+	version, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > LatestSchemaVersion() {
+		return fmt.Errorf("%w (%d > %d)", ErrSchemaTooNew, version, LatestSchemaVersion())
+	}
+	return nil
+}
+
+// Applies all pending migrations.
+func Migrate(db *gorm.DB) error {
+	return MigrateTo(db, LatestSchemaVersion())
+}
+
+// Applies or reverts migrations until the schema is at the given version.
+// Every migration runs in its own transaction.
+func MigrateTo(db *gorm.DB, version int) error {
+	if version < 0 || version > LatestSchemaVersion() {
+		return fmt.Errorf("unknown schema version %d", version)
+	}
+
+	err := CheckSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	err = db.AutoMigrate(&SchemaMigration{})
+	if err != nil {
+		return err
+	}
+
+	status, err := GetMigrationStatus(db)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range status {
+		if s.Version > version || s.Applied != 0 {
+			continue
+		}
+		log.Printf("Applying migration %d (%s)...", s.Version, s.Name)
+		m := migrationByVersion(s.Version)
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := m.Up(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, Applied: time.Now().Unix()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+
+	for i := len(status) - 1; i >= 0; i-- {
+		s := status[i]
+		if s.Version <= version || s.Applied == 0 {
+			continue
+		}
+		log.Printf("Reverting migration %d (%s)...", s.Version, s.Name)
+		m := migrationByVersion(s.Version)
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := m.Down(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("revert migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// Returns all known migrations ordered by version.
+func GetMigrationStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	applied := make(map[int]int64)
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		var rows []SchemaMigration
+		err := db.Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			applied[r.Version] = r.Applied
+		}
+	}
+
+	status := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		status[i] = MigrationStatus{
+			Version: m.Version,
+			Name:    m.Name,
+			Applied: applied[m.Version],
+		}
+	}
+	return status, nil
+}
+
+func migrationByVersion(version int) Migration {
+	for _, m := range migrations {
+		if m.Version == version {
+			return m
+		}
+	}
+	panic(fmt.Sprintf("unknown migration %d", version))
+}
+
+func createTables(tables ...interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, m := range tables {
+			err := tx.Migrator().CreateTable(m)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Drops the tables of the models in reverse order.
+func dropTables(tables ...interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for i := len(tables) - 1; i >= 0; i-- {
+			err := tx.Migrator().DropTable(tables[i])
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Adds the columns of the fields of model.
+func addColumns(model interface{}, fields ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, f := range fields {
+			err := tx.Migrator().AddColumn(model, f)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func dropColumns(model interface{}, fields ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, f := range fields {
+			err := tx.Migrator().DropColumn(model, f)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Runs the migration steps in order.
+func combine(steps ...func(tx *gorm.DB) error) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, step := range steps {
+			err := step(tx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
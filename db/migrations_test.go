@@ -0,0 +1,103 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/juho05/h-bank/models"
+)
+
+func TestMigrateTo(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+
+	version, err := SchemaVersion(database)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	err = Migrate(database)
+	if !assert.NoError(t, err) {
+		return
+	}
+	version, err = SchemaVersion(database)
+	assert.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), version)
+	assert.True(t, database.Migrator().HasTable(&models.TransactionLogEntry{}))
+
+	status, err := GetMigrationStatus(database)
+	if assert.NoError(t, err) && assert.Len(t, status, len(migrations)) {
+		for _, s := range status {
+			assert.NotZero(t, s.Applied, "migration %d should be applied", s.Version)
+		}
+	}
+
+	err = MigrateTo(database, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	version, err = SchemaVersion(database)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+	assert.False(t, database.Migrator().HasTable(&models.TransactionLogEntry{}))
+
+	assert.Error(t, MigrateTo(database, LatestSchemaVersion()+1))
+
+	database.Create(&SchemaMigration{Version: LatestSchemaVersion() + 1, Name: "from the future"})
+	assert.ErrorIs(t, CheckSchemaVersion(database), ErrSchemaTooNew)
+	assert.ErrorIs(t, Migrate(database), ErrSchemaTooNew)
+}
+
+// The migrations use snapshots of the models, so a new field needs a new migration.
+func TestMigrate_MatchesModels(t *testing.T) {
+	t.Parallel()
+
+	database, dbId, err := NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer DeleteTestDB(dbId)
+	err = Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	for _, model := range append(backupModels, &models.Lease{}) {
+		stmt := &gorm.Statement{DB: database}
+		if !assert.NoError(t, stmt.Parse(model)) {
+			continue
+		}
+		assert.True(t, database.Migrator().HasTable(model), "table %s should exist", stmt.Schema.Table)
+		for _, name := range stmt.Schema.DBNames {
+			assert.True(t, database.Migrator().HasColumn(model, name), "column %s.%s should exist", stmt.Schema.Table, name)
+		}
+	}
+}
+
+// Released migrations are part of existing databases and must not be renumbered or replaced.
+func TestMigrations_Released(t *testing.T) {
+	t.Parallel()
+
+	released := []string{
+		"initial schema",
+		"payment plan executions and leases",
+		"payment plan failure policies",
+		"payment plan schedule rules",
+		"time zones",
+		"interest remainders",
+		"transaction kinds",
+	}
+	if !assert.GreaterOrEqual(t, len(migrations), len(released)) {
+		return
+	}
+	for i, name := range released {
+		assert.Equal(t, i+1, migrations[i].Version)
+		assert.Equal(t, name, migrations[i].Name, "migration %d was released and must not be changed", i+1)
+	}
+}
@@ -1,9 +1,18 @@
 package db
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/juho05/h-bank/config"
 	"github.com/juho05/h-bank/models"
+	"github.com/juho05/h-bank/services"
 )
 
 type UserStore struct {
@@ -83,12 +92,152 @@ func (us *UserStore) Update(user *models.User) error {
 	return us.db.Select("*").Updates(user).Error
 }
 
+// Pays out the remaining balances of the user to the bank of each group and
+// replaces the id of the user in the transaction logs with models.DeletedUserId.
 func (us *UserStore) Delete(user *models.User) error {
-	us.db.Delete(&models.CashLogEntry{}, "user_id = ?", user.Id)
-	us.db.Delete(&models.GroupInvitation{}, "user_id = ?", user.Id)
-	us.db.Delete(&models.GroupMembership{}, "user_id = ?", user.Id)
-	us.db.Where("sender_id = ?", user.Id).Or("receiver_id = ?", user.Id).Delete(&models.PaymentPlan{})
-	return us.db.Delete(user).Error
+	return us.db.Transaction(func(tx *gorm.DB) error {
+		groupIds, err := lockGroupsToDeleteWithUser(tx, user)
+		if err != nil {
+			return err
+		}
+		for _, id := range groupIds {
+			err = deleteGroup(tx, &models.Group{Base: models.Base{Id: id}})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = deletePendingTransactions(tx, "sender_id = ? OR receiver_id = ?", user.Id, user.Id)
+		if err != nil {
+			return err
+		}
+		err = emptySavingsGoals(tx, nil, "user_id = ?", user.Id)
+		if err != nil {
+			return err
+		}
+
+		var memberships []models.GroupMembership
+		err = tx.Find(&memberships, "user_id = ? AND balance <> ?", user.Id, 0).Error
+		if err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			group := &models.Group{Base: models.Base{Id: m.GroupId}}
+			if m.Balance > 0 {
+				_, err = createSystemTransaction(tx, group, false, true, user, nil, models.TransactionKindAccountDeleted, m.Balance)
+			} else {
+				_, err = createSystemTransaction(tx, group, true, false, nil, user, models.TransactionKindAccountDeleted, -m.Balance)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		var paymentPlanIds []string
+		err = tx.Model(&models.PaymentPlan{}).Where("sender_id = ?", user.Id).Or("receiver_id = ?", user.Id).Pluck("id", &paymentPlanIds).Error
+		if err != nil {
+			return err
+		}
+		if len(paymentPlanIds) > 0 {
+			err = tx.Model(&models.TransactionLogEntry{}).Where("payment_plan_id IN ?", paymentPlanIds).Update("payment_plan_id", "").Error
+			if err != nil {
+				return err
+			}
+			err = tx.Delete(&models.PaymentPlanExecution{}, "payment_plan_id IN ?", paymentPlanIds).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Delete(&models.PaymentPlan{}, "id IN ?", paymentPlanIds).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&models.TransactionLogEntry{}).Where("sender_id = ?", user.Id).Update("sender_id", models.DeletedUserId).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.TransactionLogEntry{}).Where("receiver_id = ?", user.Id).Update("receiver_id", models.DeletedUserId).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Delete(&models.SavingsGoal{}, "user_id = ?", user.Id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("cash_log_entry_id IN (?)", tx.Model(&models.CashLogEntry{}).Select("id").Where("user_id = ?", user.Id)).Delete(&models.CashDenominationCount{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.CashLogEntry{}, "user_id = ?", user.Id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.CashLogEntryRevision{}, "user_id = ?", user.Id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.CashWallet{}, "user_id = ?", user.Id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.GroupInvitation{}, "user_id = ?", user.Id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("requester_id = ?", user.Id).Or("payer_id = ?", user.Id).Delete(&models.PaymentRequest{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.GroupMembership{}, "user_id = ?", user.Id).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
+}
+
+// Locks the groups in which the user is an admin and their memberships, so that nobody can join or become admin
+// until the end of the transaction. Returns the ids of the groups in which the user is the only user.
+// Returns a *models.SoleGroupAdminError if the user is the only admin of groups with other members.
+func lockGroupsToDeleteWithUser(tx *gorm.DB, user *models.User) ([]string, error) {
+	var groups []models.Group
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id ASC").
+		Find(&groups, "id IN (?)", tx.Model(&models.GroupMembership{}).Select("group_id").Where("user_id = ? AND is_admin = ?", user.Id, true)).Error
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+	groupIds := make([]string, len(groups))
+	for i, g := range groups {
+		groupIds[i] = g.Id
+	}
+
+	var memberships []models.GroupMembership
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id ASC").Find(&memberships, "group_id IN ? AND user_id <> ?", groupIds, user.Id).Error
+	if err != nil {
+		return nil, err
+	}
+	otherAdmins := make(map[string]bool, len(groups))
+	otherUsers := make(map[string]bool, len(groups))
+	for _, m := range memberships {
+		otherAdmins[m.GroupId] = otherAdmins[m.GroupId] || m.IsAdmin
+		otherUsers[m.GroupId] = otherUsers[m.GroupId] || m.IsAdmin || m.IsMember
+	}
+
+	deleteIds := make([]string, 0)
+	soleAdminIds := make([]string, 0)
+	for _, id := range groupIds {
+		if !otherUsers[id] {
+			deleteIds = append(deleteIds, id)
+		} else if !otherAdmins[id] {
+			soleAdminIds = append(soleAdminIds, id)
+		}
+	}
+	if len(soleAdminIds) > 0 {
+		return nil, &models.SoleGroupAdminError{GroupIds: soleAdminIds}
+	}
+	return deleteIds, nil
 }
 
 func (us *UserStore) DeleteById(id string) error {
@@ -117,36 +266,40 @@ func (us *UserStore) DeleteByEmail(email string) error {
 	return nil
 }
 
-func (us *UserStore) GetCashLog(user *models.User, searchInput string, page, pageSize int, oldestFirst bool) ([]models.CashLogEntry, error) {
+func (us *UserStore) GetCashLog(user *models.User, walletId, searchInput string, page, pageSize int, oldestFirst bool) ([]models.CashLogEntry, error) {
 	var cashLog []models.CashLogEntry
 	var err error
 	if page < 0 || pageSize < 0 {
 		if oldestFirst {
-			err = us.db.Where("user_id = ? AND change_title LIKE ?", user.Id, "%"+searchInput+"%").Order("created ASC").Find(&cashLog).Error
+			err = us.db.Where("user_id = ? AND wallet_id = ? AND change_title LIKE ?", user.Id, walletId, "%"+searchInput+"%").Order("created ASC").Find(&cashLog).Error
 		} else {
-			err = us.db.Where("user_id = ? AND change_title LIKE ?", user.Id, "%"+searchInput+"%").Order("created DESC").Find(&cashLog).Error
+			err = us.db.Where("user_id = ? AND wallet_id = ? AND change_title LIKE ?", user.Id, walletId, "%"+searchInput+"%").Order("created DESC").Find(&cashLog).Error
 		}
 	} else {
 		offset := page * pageSize
 		if oldestFirst {
-			err = us.db.Where("user_id = ? AND change_title LIKE ?", user.Id, "%"+searchInput+"%").Order("created ASC").Offset(offset).Limit(pageSize).Find(&cashLog).Error
+			err = us.db.Where("user_id = ? AND wallet_id = ? AND change_title LIKE ?", user.Id, walletId, "%"+searchInput+"%").Order("created ASC").Offset(offset).Limit(pageSize).Find(&cashLog).Error
 		} else {
-			err = us.db.Where("user_id = ? AND change_title LIKE ?", user.Id, "%"+searchInput+"%").Order("created DESC").Offset(offset).Limit(pageSize).Find(&cashLog).Error
+			err = us.db.Where("user_id = ? AND wallet_id = ? AND change_title LIKE ?", user.Id, walletId, "%"+searchInput+"%").Order("created DESC").Offset(offset).Limit(pageSize).Find(&cashLog).Error
 		}
 	}
 
 	return cashLog, err
 }
 
-func (us *UserStore) CashLogEntryCount(user *models.User) (int64, error) {
+func (us *UserStore) CashLogEntryCount(user *models.User, walletId string) (int64, error) {
 	var count int64
-	err := us.db.Model(&models.CashLogEntry{}).Where("user_id = ?", user.Id).Count(&count).Error
+	err := us.db.Model(&models.CashLogEntry{}).Where("user_id = ? AND wallet_id = ?", user.Id, walletId).Count(&count).Error
 	return count, err
 }
 
-func (us *UserStore) GetLastCashLogEntry(user *models.User) (*models.CashLogEntry, error) {
+func (us *UserStore) GetLastCashLogEntry(user *models.User, walletId string) (*models.CashLogEntry, error) {
+	return getLastCashLogEntry(us.db, user, walletId)
+}
+
+func getLastCashLogEntry(tx *gorm.DB, user *models.User, walletId string) (*models.CashLogEntry, error) {
 	var cashLog []models.CashLogEntry
-	err := us.db.Where("user_id = ?", user.Id).Order("created desc").Limit(1).Find(&cashLog).Error
+	err := tx.Preload("Denominations").Where("user_id = ? AND wallet_id = ?", user.Id, walletId).Order("created desc").Limit(1).Find(&cashLog).Error
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +313,7 @@ func (us *UserStore) GetLastCashLogEntry(user *models.User) (*models.CashLogEntr
 
 func (us *UserStore) GetCashLogEntryById(user *models.User, id string) (*models.CashLogEntry, error) {
 	var cashLogEntry models.CashLogEntry
-	err := us.db.First(&cashLogEntry, "id = ? AND user_id = ?", id, user.Id).Error
+	err := us.db.Preload("Denominations").First(&cashLogEntry, "id = ? AND user_id = ?", id, user.Id).Error
 	if err != nil {
 		switch err {
 		case gorm.ErrRecordNotFound:
@@ -173,37 +326,419 @@ func (us *UserStore) GetCashLogEntryById(user *models.User, id string) (*models.
 }
 
 func (us *UserStore) AddCashLogEntry(user *models.User, entry *models.CashLogEntry) error {
-	lastEntry, err := us.GetLastCashLogEntry(user)
+	return us.db.Transaction(func(tx *gorm.DB) error {
+		return addCashLogEntry(tx, user, entry)
+	})
+}
+
+// Must be called inside of a database transaction.
+func addCashLogEntry(tx *gorm.DB, user *models.User, entry *models.CashLogEntry) error {
+	lastEntry, err := getLastCashLogEntry(tx, user, entry.WalletId)
 	if err != nil {
 		return err
 	}
 
-	totalAmount := 0
+	if entry.Currency == "" {
+		entry.Currency = services.DefaultCurrency
+	}
 
-	totalAmount += 1 * entry.Ct1
-	totalAmount += 2 * entry.Ct2
-	totalAmount += 5 * entry.Ct5
-	totalAmount += 10 * entry.Ct10
-	totalAmount += 20 * entry.Ct20
-	totalAmount += 50 * entry.Ct50
-
-	totalAmount += 100 * entry.Eur1
-	totalAmount += 200 * entry.Eur2
-	totalAmount += 500 * entry.Eur5
-	totalAmount += 1000 * entry.Eur10
-	totalAmount += 2000 * entry.Eur20
-	totalAmount += 5000 * entry.Eur50
-	totalAmount += 10000 * entry.Eur100
-	totalAmount += 20000 * entry.Eur200
-	totalAmount += 50000 * entry.Eur500
+	totalAmount := 0
+	for _, d := range entry.Denominations {
+		denomination, ok := services.GetDenomination(entry.Currency, d.Key)
+		if !ok {
+			return fmt.Errorf("unknown denomination '%s' of currency '%s'", d.Key, entry.Currency)
+		}
+		totalAmount += denomination.Value * d.Count
+	}
 
 	entry.TotalAmount = totalAmount
 
-	if lastEntry != nil {
+	// the difference between amounts of different currencies is meaningless
+	if lastEntry != nil && lastEntry.Currency == entry.Currency {
 		entry.ChangeDifference = entry.TotalAmount - lastEntry.TotalAmount
 	} else {
 		entry.ChangeDifference = entry.TotalAmount
 	}
 
-	return us.db.Model(&user).Association("CashLog").Append(entry)
+	// the latest entry is the current cash, so entries of the same wallet must not share a timestamp
+	if lastEntry != nil && lastEntry.Created >= time.Now().Unix() {
+		entry.Created = lastEntry.Created + 1
+	}
+
+	entry.UserId = user.Id
+	return tx.Create(entry).Error
+}
+
+func (us *UserStore) UpdateCashLogEntry(user *models.User, entry *models.CashLogEntry, title, description string, denominations []models.CashDenominationCount) error {
+	return us.db.Transaction(func(tx *gorm.DB) error {
+		err := checkCashLogEntryEditable(tx, user, entry)
+		if err != nil {
+			return err
+		}
+		err = createCashLogEntryRevision(tx, entry, models.CashLogEntryRevisionUpdate)
+		if err != nil {
+			return err
+		}
+
+		totalAmount := 0
+		for i, d := range denominations {
+			denomination, ok := services.GetDenomination(entry.Currency, d.Key)
+			if !ok {
+				return fmt.Errorf("unknown denomination '%s' of currency '%s'", d.Key, entry.Currency)
+			}
+			totalAmount += denomination.Value * d.Count
+			denominations[i].CashLogEntryId = entry.Id
+		}
+
+		err = tx.Delete(&models.CashDenominationCount{}, "cash_log_entry_id = ?", entry.Id).Error
+		if err != nil {
+			return err
+		}
+		if len(denominations) > 0 {
+			err = tx.Create(&denominations).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		entry.ChangeTitle = title
+		entry.ChangeDescription = description
+		entry.TotalAmount = totalAmount
+		entry.Denominations = denominations
+		err = tx.Model(entry).Select("change_title", "change_description", "total_amount").Updates(entry).Error
+		if err != nil {
+			return err
+		}
+
+		return recomputeCashDifferences(tx, user, entry.WalletId, entry.Created)
+	})
+}
+
+func (us *UserStore) DeleteCashLogEntry(user *models.User, entry *models.CashLogEntry) error {
+	return us.db.Transaction(func(tx *gorm.DB) error {
+		err := checkCashLogEntryEditable(tx, user, entry)
+		if err != nil {
+			return err
+		}
+		err = createCashLogEntryRevision(tx, entry, models.CashLogEntryRevisionDelete)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Delete(&models.CashDenominationCount{}, "cash_log_entry_id = ?", entry.Id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(entry).Error
+		if err != nil {
+			return err
+		}
+
+		return recomputeCashDifferences(tx, user, entry.WalletId, entry.Created)
+	})
+}
+
+func (us *UserStore) ImportCashLog(user *models.User, walletId string, entries []models.CashLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Created < entries[j].Created
+	})
+
+	return us.db.Transaction(func(tx *gorm.DB) error {
+		for i := range entries {
+			entry := &entries[i]
+			if entry.Currency == "" {
+				entry.Currency = services.DefaultCurrency
+			}
+
+			entry.TotalAmount = 0
+			for _, d := range entry.Denominations {
+				denomination, ok := services.GetDenomination(entry.Currency, d.Key)
+				if !ok {
+					return fmt.Errorf("unknown denomination '%s' of currency '%s'", d.Key, entry.Currency)
+				}
+				entry.TotalAmount += denomination.Value * d.Count
+			}
+
+			// rows of the same day need distinct times to keep their order
+			if i > 0 && entry.Created <= entries[i-1].Created {
+				entry.Created = entries[i-1].Created + 1
+			}
+
+			entry.UserId = user.Id
+			entry.WalletId = walletId
+			err := tx.Create(entry).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return recomputeCashDifferences(tx, user, walletId, entries[0].Created)
+	})
+}
+
+func (us *UserStore) GetCashLogEntryRevisions(user *models.User, entryId string) ([]models.CashLogEntryRevision, error) {
+	var revisions []models.CashLogEntryRevision
+	err := us.db.Where("user_id = ? AND cash_log_entry_id = ?", user.Id, entryId).Order("created ASC").Find(&revisions).Error
+	return revisions, err
+}
+
+// Must be called inside of a database transaction.
+func checkCashLogEntryEditable(tx *gorm.DB, user *models.User, entry *models.CashLogEntry) error {
+	// changing one side of a move or a cash transaction would make it inconsistent with the other side
+	if entry.MoveEntryId != "" || entry.TransactionId != "" {
+		return models.ErrCashLogEntryLinked
+	}
+
+	var newerCount int64
+	err := tx.Model(&models.CashLogEntry{}).Where("user_id = ? AND wallet_id = ? AND created > ?", user.Id, entry.WalletId, entry.Created).Count(&newerCount).Error
+	if err != nil {
+		return err
+	}
+	if newerCount >= int64(config.Data.EditableCashLogEntries) {
+		return models.ErrCashLogEntryNotEditable
+	}
+	return nil
+}
+
+// Must be called inside of a database transaction before changing the entry.
+func createCashLogEntryRevision(tx *gorm.DB, entry *models.CashLogEntry, action string) error {
+	counts := make(map[string]int, len(entry.Denominations))
+	for _, d := range entry.Denominations {
+		counts[d.Key] = d.Count
+	}
+	denominations, err := json.Marshal(counts)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.CashLogEntryRevision{
+		CashLogEntryId: entry.Id,
+		UserId:         entry.UserId,
+		WalletId:       entry.WalletId,
+		Action:         action,
+
+		ChangeTitle:       entry.ChangeTitle,
+		ChangeDescription: entry.ChangeDescription,
+		Currency:          entry.Currency,
+		TotalAmount:       entry.TotalAmount,
+		ChangeDifference:  entry.ChangeDifference,
+		Denominations:     string(denominations),
+	}).Error
+}
+
+// Recomputes the differences of all entries of the wallet created at or after since.
+// Must be called inside of a database transaction.
+func recomputeCashDifferences(tx *gorm.DB, user *models.User, walletId string, since int64) error {
+	var previous []models.CashLogEntry
+	err := tx.Where("user_id = ? AND wallet_id = ? AND created < ?", user.Id, walletId, since).Order("created DESC").Limit(1).Find(&previous).Error
+	if err != nil {
+		return err
+	}
+
+	var entries []models.CashLogEntry
+	err = tx.Where("user_id = ? AND wallet_id = ? AND created >= ?", user.Id, walletId, since).Order("created ASC").Find(&entries).Error
+	if err != nil {
+		return err
+	}
+
+	var last *models.CashLogEntry
+	if len(previous) > 0 {
+		last = &previous[0]
+	}
+	for i := range entries {
+		entry := &entries[i]
+		difference := entry.TotalAmount
+		if last != nil && last.Currency == entry.Currency {
+			difference = entry.TotalAmount - last.TotalAmount
+		}
+		if difference != entry.ChangeDifference {
+			err = tx.Model(entry).Update("change_difference", difference).Error
+			if err != nil {
+				return err
+			}
+		}
+		last = entry
+	}
+	return nil
+}
+
+func (us *UserStore) GetCashWallets(user *models.User) ([]models.CashWallet, error) {
+	var wallets []models.CashWallet
+	err := us.db.Where("user_id = ?", user.Id).Order("name ASC").Find(&wallets).Error
+	return wallets, err
+}
+
+func (us *UserStore) GetCashWalletById(user *models.User, id string) (*models.CashWallet, error) {
+	var wallet models.CashWallet
+	err := us.db.First(&wallet, "id = ? AND user_id = ?", id, user.Id).Error
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+	return &wallet, nil
+}
+
+func (us *UserStore) CreateCashWallet(user *models.User, name, currency string) (*models.CashWallet, error) {
+	wallet := &models.CashWallet{
+		Name:     name,
+		Currency: currency,
+		UserId:   user.Id,
+	}
+	err := us.db.Create(wallet).Error
+	return wallet, err
+}
+
+func (us *UserStore) UpdateCashWallet(wallet *models.CashWallet) error {
+	return us.db.Model(wallet).Select("name").Updates(wallet).Error
+}
+
+func (us *UserStore) DeleteCashWallet(wallet *models.CashWallet) error {
+	return us.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("cash_log_entry_id IN (?)", tx.Model(&models.CashLogEntry{}).Select("id").Where("user_id = ? AND wallet_id = ?", wallet.UserId, wallet.Id)).Delete(&models.CashDenominationCount{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.CashLogEntry{}, "user_id = ? AND wallet_id = ?", wallet.UserId, wallet.Id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&models.CashLogEntryRevision{}, "user_id = ? AND wallet_id = ?", wallet.UserId, wallet.Id).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(wallet).Error
+	})
+}
+
+func (us *UserStore) MoveCash(user *models.User, fromWalletId, toWalletId, title, description string, denominations []models.CashDenominationCount) (*models.CashLogEntry, *models.CashLogEntry, error) {
+	from := &models.CashLogEntry{
+		Base:              models.Base{Id: uuid.NewString()},
+		ChangeTitle:       title,
+		ChangeDescription: description,
+		WalletId:          fromWalletId,
+	}
+	to := &models.CashLogEntry{
+		Base:              models.Base{Id: uuid.NewString()},
+		ChangeTitle:       title,
+		ChangeDescription: description,
+		WalletId:          toWalletId,
+		MoveEntryId:       from.Id,
+	}
+	from.MoveEntryId = to.Id
+
+	err := us.db.Transaction(func(tx *gorm.DB) error {
+		fromCurrency, err := walletCurrency(tx, user, fromWalletId)
+		if err != nil {
+			return err
+		}
+		toCurrency, err := walletCurrency(tx, user, toWalletId)
+		if err != nil {
+			return err
+		}
+		if fromCurrency != toCurrency {
+			return models.ErrCurrencyMismatch
+		}
+		from.Currency = fromCurrency
+		to.Currency = toCurrency
+
+		moved := make(map[string]int, len(denominations))
+		for _, d := range denominations {
+			moved[d.Key] += d.Count
+		}
+
+		from.Denominations, err = moveDenominations(tx, user, fromWalletId, moved, -1)
+		if err != nil {
+			return err
+		}
+		to.Denominations, err = moveDenominations(tx, user, toWalletId, moved, 1)
+		if err != nil {
+			return err
+		}
+
+		err = addCashLogEntry(tx, user, from)
+		if err != nil {
+			return err
+		}
+		return addCashLogEntry(tx, user, to)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
+
+// Returns the denominations of the current cash of the wallet after adding sign * moved.
+func moveDenominations(tx *gorm.DB, user *models.User, walletId string, moved map[string]int, sign int) ([]models.CashDenominationCount, error) {
+	counts := make(map[string]int)
+	lastEntry, err := getLastCashLogEntry(tx, user, walletId)
+	if err != nil {
+		return nil, err
+	}
+	if lastEntry != nil {
+		for _, d := range lastEntry.Denominations {
+			counts[d.Key] = d.Count
+		}
+	}
+
+	for key, count := range moved {
+		counts[key] += sign * count
+		if counts[key] < 0 {
+			return nil, models.ErrNotEnoughCash
+		}
+	}
+
+	denominations := make([]models.CashDenominationCount, 0, len(counts))
+	for key, count := range counts {
+		if count > 0 {
+			denominations = append(denominations, models.CashDenominationCount{Key: key, Count: count})
+		}
+	}
+	return denominations, nil
+}
+
+func walletCurrency(tx *gorm.DB, user *models.User, walletId string) (string, error) {
+	var currency string
+	var err error
+	if walletId == models.DefaultCashWalletId {
+		err = tx.Model(&models.User{}).Select("cash_currency").Where("id = ?", user.Id).Scan(&currency).Error
+	} else {
+		err = tx.Model(&models.CashWallet{}).Select("currency").Where("id = ? AND user_id = ?", walletId, user.Id).Scan(&currency).Error
+	}
+	if err != nil {
+		return "", err
+	}
+	if currency == "" {
+		return "", fmt.Errorf("wallet '%s' of user '%s' not found", walletId, user.Id)
+	}
+	return currency, nil
+}
+
+func (us *UserStore) GetCashTotals(user *models.User) (map[string]int, error) {
+	walletIds := []string{models.DefaultCashWalletId}
+	var ids []string
+	err := us.db.Model(&models.CashWallet{}).Where("user_id = ?", user.Id).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	walletIds = append(walletIds, ids...)
+
+	totals := make(map[string]int)
+	for _, id := range walletIds {
+		entry, err := us.GetLastCashLogEntry(user, id)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			totals[entry.Currency] += entry.TotalAmount
+		}
+	}
+	return totals, nil
 }
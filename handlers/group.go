@@ -2,7 +2,12 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -146,10 +151,26 @@ func (h *Handler) CreateGroup(c echo.Context) error {
 		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
 	}
 
+	if body.Currency == "" {
+		body.Currency = services.DefaultCurrency
+	}
+	if !services.IsSupportedCurrency(body.Currency) {
+		return c.JSON(http.StatusOK, responses.New(false, "Unsupported currency", lang))
+	}
+
+	if body.TimeZone == "" {
+		body.TimeZone = "UTC"
+	}
+	if _, err = services.LoadTimeZone(body.TimeZone); err != nil {
+		return c.JSON(http.StatusOK, responses.New(false, "Unknown time zone", lang))
+	}
+
 	group := &models.Group{
 		Name:           body.Name,
 		Description:    body.Description,
 		GroupPictureId: uuid.NewString(),
+		Currency:       body.Currency,
+		TimeZone:       body.TimeZone,
 	}
 
 	err = h.groupStore.Create(group)
@@ -197,6 +218,10 @@ func (h *Handler) UpdateGroup(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
 	var body bindings.UpdateGroup
 	err = c.Bind(&body)
 	if err != nil {
@@ -221,6 +246,16 @@ func (h *Handler) UpdateGroup(c echo.Context) error {
 		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
 	}
 
+	if body.TimeZone != "" && body.TimeZone != group.TimeZone {
+		if _, err = services.LoadTimeZone(body.TimeZone); err != nil {
+			return c.JSON(http.StatusOK, responses.New(false, "Unknown time zone", lang))
+		}
+		err = h.groupStore.SetTimeZone(group, body.TimeZone)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+	}
+
 	group.Description = body.Description
 	h.groupStore.Update(group)
 
@@ -232,6 +267,141 @@ func (h *Handler) UpdateGroup(c echo.Context) error {
 	return c.JSON(http.StatusOK, responses.NewGroup(group, isMember, isAdmin))
 }
 
+// /api/group/:id (DELETE)
+func (h *Handler) DeleteGroup(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	err = h.groupStore.Delete(group)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.New(true, "Successfully deleted group", lang))
+}
+
+// /api/group/:id/archive (POST)
+func (h *Handler) ArchiveGroup(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	err = h.groupStore.SetArchived(group, true)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	group.Archived = true
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewGroup(group, isMember, isAdmin))
+}
+
+// /api/group/:id/archive (DELETE)
+func (h *Handler) UnarchiveGroup(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	err = h.groupStore.SetArchived(group, false)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	group.Archived = false
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewGroup(group, isMember, isAdmin))
+}
+
 // /api/group/:id/user (GET)
 func (h *Handler) GetGroupUsers(c echo.Context) error {
 	lang := c.Get("lang").(string)
@@ -452,6 +622,139 @@ func (h *Handler) LeaveGroup(c echo.Context) error {
 	return c.JSON(http.StatusOK, responses.New(true, "Successfully left group", lang))
 }
 
+// /api/group/:id/member/:userId/limits (GET)
+func (h *Handler) GetSpendingLimits(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	authUserId := c.Get("userId").(string)
+	authUser, err := h.userStore.GetById(authUserId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if authUser == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	user, err := h.userStore.GetById(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "The user doesn't exist", lang))
+	}
+
+	if authUser.Id != user.Id {
+		isAdmin, err := h.groupStore.IsAdmin(group, authUser)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+		}
+	}
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isMember {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is not a member of the group", lang))
+	}
+
+	limits, err := h.groupStore.GetSpendingLimits(group, user)
+	if err != nil || limits == nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewSpendingLimits(limits))
+}
+
+// /api/group/:id/member/:userId/limits (PUT)
+func (h *Handler) SetSpendingLimits(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	authUserId := c.Get("userId").(string)
+	authUser, err := h.userStore.GetById(authUserId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if authUser == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	user, err := h.userStore.GetById(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "The user doesn't exist", lang))
+	}
+
+	var body bindings.SpendingLimits
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, authUser)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isMember {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is not a member of the group", lang))
+	}
+
+	limits := models.SpendingLimits{
+		MaxPerTransaction: int(body.MaxPerTransaction),
+		MaxPerDay:         int(body.MaxPerDay),
+		MaxPerWeek:        int(body.MaxPerWeek),
+		MaxPerMonth:       int(body.MaxPerMonth),
+		Overdraft:         int(body.Overdraft),
+	}
+	err = h.groupStore.SetSpendingLimits(group, user, limits)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewSpendingLimits(&limits))
+}
+
 // /api/group/:id/admin (GET)
 func (h *Handler) GetGroupAdmins(c echo.Context) error {
 	lang := c.Get("lang").(string)
@@ -752,6 +1055,10 @@ func (h *Handler) SetGroupPicture(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
 	isAdmin, err := h.groupStore.IsAdmin(group, user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
@@ -834,6 +1141,10 @@ func (h *Handler) RemoveGroupPicture(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
 	isAdmin, err := h.groupStore.IsAdmin(group, user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
@@ -893,16 +1204,1304 @@ func (h *Handler) GetBalance(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
+	reserved, err := h.groupStore.GetReservedAmount(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
 	return c.JSON(http.StatusOK, responses.Balance{
 		Base: responses.Base{
 			Success: true,
 		},
-		Balance: balance,
+		Balance:  balance,
+		Reserved: reserved,
+
+		Currency:         group.Currency,
+		FormattedBalance: services.FormatMoney(balance, group.Currency, lang),
 	})
 }
 
-// /api/group/:id/transaction/:transactionId (GET)
-func (h *Handler) GetTransactionById(c echo.Context) error {
+// /api/group/:id/transaction/savingsGoal?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetSavingsGoals(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	page := 0
+	pageSize := 20
+
+	if c.QueryParam("page") != "" {
+		page, err = strconv.Atoi(c.QueryParam("page"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'page' query parameter not a number", lang))
+		}
+	}
+
+	if c.QueryParam("pageSize") != "" {
+		pageSize, err = strconv.Atoi(c.QueryParam("pageSize"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'pageSize' query parameter not a number", lang))
+		}
+		if pageSize > config.Data.MaxPageSize || pageSize < 1 {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "Unsupported page size", lang))
+		}
+	}
+
+	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isMember {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+	}
+
+	goals, err := h.groupStore.GetSavingsGoals(group, user, page, pageSize, oldestFirst)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	count, err := h.groupStore.SavingsGoalCount(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewSavingsGoals(goals, count))
+}
+
+// /api/group/:id/transaction/savingsGoal/:goalId (GET)
+func (h *Handler) GetSavingsGoalById(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	goalId := c.Param("goalId")
+	if goalId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing goalId parameter", lang))
+	}
+	goal, err := h.groupStore.GetSavingsGoalById(group, user, goalId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if goal == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Savings goal not found", lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewSavingsGoal(goal))
+}
+
+// /api/group/:id/transaction/savingsGoal (POST)
+func (h *Handler) CreateSavingsGoal(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isMember {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	var body bindings.SavingsGoal
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	name, target, deadline, resp := validateSavingsGoal(body, lang)
+	if resp != nil {
+		return c.JSON(http.StatusOK, resp)
+	}
+
+	goal, err := h.groupStore.CreateSavingsGoal(group, user, name, target, deadline)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusCreated, responses.NewSavingsGoal(goal))
+}
+
+// /api/group/:id/transaction/savingsGoal/:goalId (PUT)
+func (h *Handler) UpdateSavingsGoal(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	goalId := c.Param("goalId")
+	if goalId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing goalId parameter", lang))
+	}
+	goal, err := h.groupStore.GetSavingsGoalById(group, user, goalId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if goal == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Savings goal not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	var body bindings.SavingsGoal
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	name, target, deadline, resp := validateSavingsGoal(body, lang)
+	if resp != nil {
+		return c.JSON(http.StatusOK, resp)
+	}
+
+	goal.Name = name
+	goal.Target = target
+	goal.Deadline = deadline
+	err = h.groupStore.UpdateSavingsGoal(goal)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewSavingsGoal(goal))
+}
+
+// Returns a response if the body is invalid.
+func validateSavingsGoal(body bindings.SavingsGoal, lang string) (name string, target int, deadline int64, resp interface{}) {
+	name = strings.TrimSpace(body.Name)
+	if utf8.RuneCountInString(name) > config.Data.MaxNameLength {
+		return "", 0, 0, responses.New(false, "Name too long", lang)
+	}
+	if utf8.RuneCountInString(name) < config.Data.MinNameLength {
+		return "", 0, 0, responses.New(false, "Name too short", lang)
+	}
+
+	if body.Target <= 0 {
+		return "", 0, 0, responses.New(false, "Target must be >0", lang)
+	}
+
+	if body.Deadline != "" {
+		deadlineTime, err := time.Parse("2006-01-02", body.Deadline)
+		if err != nil {
+			return "", 0, 0, responses.New(false, "Invalid date string", lang)
+		}
+		if deadlineTime.Before(time.Now()) {
+			return "", 0, 0, responses.New(false, "Deadline can't be in the past", lang)
+		}
+		deadline = deadlineTime.Unix()
+	}
+
+	return name, int(body.Target), deadline, nil
+}
+
+// Moves the remaining balance of the goal back to the main balance.
+// /api/group/:id/transaction/savingsGoal/:goalId (DELETE)
+func (h *Handler) DeleteSavingsGoal(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	goalId := c.Param("goalId")
+	if goalId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing goalId parameter", lang))
+	}
+	goal, err := h.groupStore.GetSavingsGoalById(group, user, goalId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if goal == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Savings goal not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	err = h.groupStore.DeleteSavingsGoal(group, goal)
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.New(true, "Successfully deleted savings goal", lang))
+}
+
+// /api/group/:id/transaction/savingsGoal/:goalId/deposit (POST)
+func (h *Handler) DepositToSavingsGoal(c echo.Context) error {
+	return h.transferSavings(c, true)
+}
+
+// /api/group/:id/transaction/savingsGoal/:goalId/withdraw (POST)
+func (h *Handler) WithdrawFromSavingsGoal(c echo.Context) error {
+	return h.transferSavings(c, false)
+}
+
+func (h *Handler) transferSavings(c echo.Context, deposit bool) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	goalId := c.Param("goalId")
+	if goalId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing goalId parameter", lang))
+	}
+	goal, err := h.groupStore.GetSavingsGoalById(group, user, goalId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if goal == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Savings goal not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	var body bindings.SavingsTransfer
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+	if body.Amount <= 0 {
+		return c.JSON(http.StatusOK, responses.New(false, "Amount must be >0", lang))
+	}
+
+	var transaction *models.TransactionLogEntry
+	if deposit {
+		transaction, err = h.groupStore.DepositToSavingsGoal(group, goal, int(body.Amount))
+	} else {
+		transaction, err = h.groupStore.WithdrawFromSavingsGoal(group, goal, int(body.Amount))
+	}
+	if errors.Is(err, models.ErrNotEnoughMoney) {
+		return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+	}
+	if errors.Is(err, models.ErrNotInGroup) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+	}
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewTransaction(transaction, user, group.Currency, lang))
+}
+
+// /api/group/:id/transaction/:transactionId (GET)
+func (h *Handler) GetTransactionById(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	transactionId := c.Param("transactionId")
+	if transactionId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing transactionId parameter", lang))
+	}
+
+	transaction, err := h.groupStore.GetTransactionLogEntryById(group, transactionId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if transaction == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	isSender := user.Id == transaction.SenderId
+	isReceiver := user.Id == transaction.ReceiverId
+
+	if isSender || isReceiver {
+		return c.JSON(http.StatusOK, responses.NewTransaction(transaction, user, group.Currency, lang))
+	} else if transaction.SenderIsBank || transaction.ReceiverIsBank {
+		isAdmin, err := h.groupStore.IsAdmin(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+		}
+
+		return c.JSON(http.StatusOK, responses.NewBankTransaction(transaction, group.Currency, lang))
+	}
+
+	return c.JSON(http.StatusForbidden, responses.New(false, "User not allowed to view transaction", lang))
+}
+
+// Transactions of admins never need to be approved.
+func (h *Handler) needsApproval(group *models.Group, sender *models.User, receiverIsBank bool, amount int) (bool, error) {
+	if !(receiverIsBank && group.ApproveBankTransfers) && (group.ApprovalThreshold <= 0 || amount < group.ApprovalThreshold) {
+		return false, nil
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, sender)
+	return !isAdmin, err
+}
+
+func (h *Handler) createPendingTransaction(c echo.Context, group *models.Group, sender *models.User, receiverIsBank bool, receiver *models.User, title, description string, amount int) error {
+	lang := c.Get("lang").(string)
+
+	pending, err := h.groupStore.CreatePendingTransaction(group, sender, receiverIsBank, receiver, title, description, amount)
+	if errors.Is(err, models.ErrNotEnoughMoney) {
+		return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+	}
+	var limitErr *models.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		return c.JSON(http.StatusOK, responses.NewSpendingLimitExceeded(limitErr, group.Currency, lang))
+	}
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusAccepted, responses.NewPendingTransaction(pending, lang))
+}
+
+// /api/group/:id/approvalPolicy (PUT)
+func (h *Handler) SetApprovalPolicy(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	var body bindings.ApprovalPolicy
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	err = h.groupStore.SetApprovalPolicy(group, int(body.Threshold), body.BankTransfers)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewGroup(group, isMember, isAdmin))
+}
+
+// /api/group/:id/interest (PUT)
+func (h *Handler) SetInterest(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	var body bindings.Interest
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	if body.AnnualRate < 0 || body.AnnualRate > 100 {
+		return c.JSON(http.StatusOK, responses.New(false, "Interest rate must be between 0 and 100", lang))
+	}
+
+	rate := int(math.Round(body.AnnualRate * 100))
+	if rate > 0 && body.Period != models.ScheduleUnitDay && body.Period != models.ScheduleUnitWeek && body.Period != models.ScheduleUnitMonth && body.Period != models.ScheduleUnitYear {
+		return c.JSON(http.StatusOK, responses.New(false, "Invalid interest period", lang))
+	}
+
+	err = h.groupStore.SetInterest(group, rate, body.Period, int(body.MinBalance))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	isMember, err := h.groupStore.IsMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewGroup(group, isMember, isAdmin))
+}
+
+// Admins get the pending transactions of all members.
+// /api/group/:id/transaction/pending?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetPendingTransactions(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	page := 0
+	pageSize := 20
+
+	if c.QueryParam("page") != "" {
+		page, err = strconv.Atoi(c.QueryParam("page"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'page' query parameter not a number", lang))
+		}
+	}
+
+	if c.QueryParam("pageSize") != "" {
+		pageSize, err = strconv.Atoi(c.QueryParam("pageSize"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'pageSize' query parameter not a number", lang))
+		}
+		if pageSize > config.Data.MaxPageSize || pageSize < 1 {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "Unsupported page size", lang))
+		}
+	}
+
+	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	sender := user
+	if isAdmin {
+		sender = nil
+	} else {
+		isMember, err := h.groupStore.IsMember(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isMember {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member/admin of the group", lang))
+		}
+	}
+
+	pendingTransactions, err := h.groupStore.GetPendingTransactions(group, sender, page, pageSize, oldestFirst)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	count, err := h.groupStore.PendingTransactionCount(group, sender)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewPendingTransactions(pendingTransactions, count))
+}
+
+// /api/group/:id/transaction/pending/:pendingId (POST)
+func (h *Handler) ApprovePendingTransaction(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	pendingId := c.Param("pendingId")
+	if pendingId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid or missing pendingId parameter", lang))
+	}
+
+	pending, err := h.groupStore.GetPendingTransactionById(group, pendingId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if pending == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	transaction, err := h.groupStore.ApprovePendingTransaction(group, pending)
+	if errors.Is(err, models.ErrNotEnoughMoney) {
+		return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+	}
+	if errors.Is(err, models.ErrNotInGroup) {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is not a member of the group", lang))
+	}
+	if errors.Is(err, models.ErrPendingTransactionHandled) {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewBankTransaction(transaction, group.Currency, lang))
+}
+
+// Rejects the pending transaction if the user is an admin and withdraws it if the user is the sender.
+// /api/group/:id/transaction/pending/:pendingId (DELETE)
+func (h *Handler) RejectPendingTransaction(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	pendingId := c.Param("pendingId")
+	if pendingId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid or missing pendingId parameter", lang))
+	}
+
+	pending, err := h.groupStore.GetPendingTransactionById(group, pendingId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if pending == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	message := "Successfully withdrew transaction"
+	if pending.SenderId != user.Id {
+		isAdmin, err := h.groupStore.IsAdmin(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+		}
+		message = "Successfully rejected transaction"
+	}
+
+	err = h.groupStore.RejectPendingTransaction(group, pending)
+	if errors.Is(err, models.ErrPendingTransactionHandled) {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.New(true, message, lang))
+}
+
+// /api/group/:id/transaction/:transactionId/reverse (POST)
+func (h *Handler) ReverseTransaction(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	transactionId := c.Param("transactionId")
+	if transactionId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing transactionId parameter", lang))
+	}
+
+	transaction, err := h.groupStore.GetTransactionLogEntryById(group, transactionId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if transaction == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	if transaction.ReceiverIsBank || user.Id != transaction.ReceiverId {
+		isAdmin, err := h.groupStore.IsAdmin(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Only admins and the receiver can reverse a transaction", lang))
+		}
+	}
+
+	if transaction.ReversedById != "" {
+		return c.JSON(http.StatusOK, responses.New(false, "The transaction was already reversed", lang))
+	}
+	if transaction.ReversalOfId != "" {
+		return c.JSON(http.StatusOK, responses.New(false, "Cannot reverse a reversal", lang))
+	}
+	if transaction.SavingsGoalId != "" {
+		return c.JSON(http.StatusOK, responses.New(false, "Savings goal transfers cannot be reversed", lang))
+	}
+	if transaction.CashLogEntryId != "" {
+		return c.JSON(http.StatusOK, responses.New(false, "Cash payouts and deposits cannot be reversed", lang))
+	}
+
+	reversal, err := h.groupStore.ReverseTransaction(group, transaction)
+	if errors.Is(err, models.ErrNotEnoughMoney) {
+		return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+	}
+	if errors.Is(err, models.ErrNotInGroup) {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is not a member of the group", lang))
+	}
+	if errors.Is(err, models.ErrTransactionReversed) {
+		return c.JSON(http.StatusOK, responses.New(false, "The transaction was already reversed", lang))
+	}
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if user.Id == reversal.SenderId || user.Id == reversal.ReceiverId {
+		return c.JSON(http.StatusOK, responses.NewTransaction(reversal, user, group.Currency, lang))
+	}
+	return c.JSON(http.StatusOK, responses.NewBankTransaction(reversal, group.Currency, lang))
+}
+
+// /api/group/:id/transaction?bank=bool&search=string&page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetTransactionLog(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	page := 0
+	pageSize := 20
+
+	if c.QueryParam("page") != "" {
+		page, err = strconv.Atoi(c.QueryParam("page"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'page' query parameter not a number", lang))
+		}
+	}
+
+	if c.QueryParam("pageSize") != "" {
+		pageSize, err = strconv.Atoi(c.QueryParam("pageSize"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'pageSize' query parameter not a number", lang))
+		}
+		if pageSize > config.Data.MaxPageSize || pageSize < 1 {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "Unsupported page size", lang))
+		}
+	}
+
+	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	bank := services.StrToBool(c.QueryParam("bank"))
+
+	if !bank {
+		isMember, err := h.groupStore.IsMember(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+
+		if !isMember {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+		}
+
+		log, err := h.groupStore.GetTransactionLog(group, user, c.QueryParam("search"), page, pageSize, oldestFirst)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+
+		count, err := h.groupStore.TransactionLogEntryCount(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+
+		return c.JSON(http.StatusOK, responses.NewTransactionLog(log, user, count, group.Currency, lang))
+	} else {
+		isAdmin, err := h.groupStore.IsAdmin(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+
+		if !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+		}
+
+		log, err := h.groupStore.GetBankTransactionLog(group, c.QueryParam("search"), page, pageSize, oldestFirst)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+
+		count, err := h.groupStore.BankTransactionLogEntryCount(group)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+
+		return c.JSON(http.StatusOK, responses.NewBankTransactionLog(log, count, group.Currency, lang))
+	}
+}
+
+// Streams all entries of the transaction log in the time range [from, to) as a file. to = 0 means no upper limit.
+// /api/group/:id/transaction/export?format=csv|json&bank=bool&from=int&to=int (GET)
+func (h *Handler) ExportTransactionLog(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Unsupported export format", lang))
+	}
+
+	var from, to int64
+	if c.QueryParam("from") != "" {
+		from, err = strconv.ParseInt(c.QueryParam("from"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'from' query parameter not a number", lang))
+		}
+	}
+	if c.QueryParam("to") != "" {
+		to, err = strconv.ParseInt(c.QueryParam("to"), 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'to' query parameter not a number", lang))
+		}
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	bank := services.StrToBool(c.QueryParam("bank"))
+
+	logUser := user
+	if !bank {
+		isMember, err := h.groupStore.IsMember(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isMember {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+		}
+	} else {
+		isAdmin, err := h.groupStore.IsAdmin(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+		}
+		logUser = nil
+	}
+
+	names, err := h.memberNames(group)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	names["bank"] = services.Tr("Bank", lang)
+	names[models.DeletedUserId] = services.Tr("Deleted user", lang)
+
+	res := c.Response()
+	if format == "csv" {
+		res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		res.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	}
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"transactions-%s.%s\"", time.Now().Format("2006-01-02"), format))
+	res.WriteHeader(http.StatusOK)
+
+	var writer transactionExportWriter
+	if format == "csv" {
+		writer = newCSVTransactionExportWriter(res, !bank, lang)
+	} else {
+		writer = newJSONTransactionExportWriter(res)
+	}
+
+	// the status is already sent, so errors can only abort the download
+	err = writer.Begin()
+	if err != nil {
+		return err
+	}
+	err = h.groupStore.ExportTransactionLog(group, logUser, from, to, func(batch []models.TransactionLogEntry) error {
+		for _, entry := range batch {
+			err := writer.Write(newTransactionExportRow(&entry, logUser, group.Currency, names, lang))
+			if err != nil {
+				return err
+			}
+		}
+		res.Flush()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = writer.End()
+	if err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
+
+// Returns the names of the current members of the group by user id.
+func (h *Handler) memberNames(group *models.Group) (map[string]string, error) {
+	memberships, err := h.groupStore.GetMemberships(nil, "", group, -1, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(memberships)+2)
+	for _, m := range memberships {
+		names[m.UserId] = m.UserName
+	}
+	return names, nil
+}
+
+// Returns the row of the entry from the perspective of user (the bank if user is nil).
+func newTransactionExportRow(entry *models.TransactionLogEntry, user *models.User, currency string, names map[string]string, lang string) responses.TransactionExportRow {
+	senderId := entry.SenderId
+	if entry.SenderIsBank {
+		senderId = "bank"
+	}
+	receiverId := entry.ReceiverId
+	if entry.ReceiverIsBank {
+		receiverId = "bank"
+	}
+
+	row := responses.TransactionExportRow{
+		Id:           entry.Id,
+		Time:         entry.Created,
+		Title:        responses.TransactionTitle(entry, lang),
+		Description:  entry.Description,
+		SenderId:     senderId,
+		SenderName:   names[senderId],
+		ReceiverId:   receiverId,
+		ReceiverName: names[receiverId],
+		Currency:     currency,
+	}
+
+	if user == nil {
+		row.Amount = -entry.Amount
+		if entry.ReceiverIsBank {
+			row.Amount = entry.Amount
+		}
+		return row
+	}
+
+	// savings goal transfers have the member on both sides, but only one side has a difference
+	newBalance := entry.NewBalanceReceiver
+	if !entry.SenderIsBank && entry.SenderId == user.Id {
+		row.Amount += entry.BalanceDifferenceSender
+		newBalance = entry.NewBalanceSender
+	}
+	if !entry.ReceiverIsBank && entry.ReceiverId == user.Id {
+		row.Amount += entry.BalanceDifferenceReceiver
+	}
+	row.NewBalance = &newBalance
+	return row
+}
+
+type transactionExportWriter interface {
+	Begin() error
+	Write(row responses.TransactionExportRow) error
+	End() error
+}
+
+type csvTransactionExportWriter struct {
+	writer      *csv.Writer
+	withBalance bool
+	lang        string
+}
+
+// German spreadsheet applications expect semicolons because the comma is the decimal separator.
+func newCSVTransactionExportWriter(w io.Writer, withBalance bool, lang string) *csvTransactionExportWriter {
+	writer := csv.NewWriter(w)
+	if lang == "de" {
+		writer.Comma = ';'
+	}
+	return &csvTransactionExportWriter{
+		writer:      writer,
+		withBalance: withBalance,
+		lang:        lang,
+	}
+}
+
+func (e *csvTransactionExportWriter) Begin() error {
+	header := []string{
+		services.Tr("Date", e.lang),
+		services.Tr("Title", e.lang),
+		services.Tr("Description", e.lang),
+		services.Tr("Sender", e.lang),
+		services.Tr("Receiver", e.lang),
+		services.Tr("Amount", e.lang),
+	}
+	if e.withBalance {
+		header = append(header, services.Tr("Balance", e.lang))
+	}
+	header = append(header, services.Tr("Currency", e.lang))
+	return e.writer.Write(header)
+}
+
+func (e *csvTransactionExportWriter) Write(row responses.TransactionExportRow) error {
+	record := []string{
+		time.Unix(row.Time, 0).UTC().Format(time.RFC3339),
+		escapeCSVFormula(row.Title),
+		escapeCSVFormula(row.Description),
+		escapeCSVFormula(row.SenderName),
+		escapeCSVFormula(row.ReceiverName),
+		services.FormatDecimal(row.Amount, e.lang),
+	}
+	if e.withBalance && row.NewBalance != nil {
+		record = append(record, services.FormatDecimal(*row.NewBalance, e.lang))
+	}
+	record = append(record, row.Currency)
+	err := e.writer.Write(record)
+	if err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvTransactionExportWriter) End() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// Prevents spreadsheet applications from evaluating user input as a formula.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// Writes a JSON array one row at a time.
+type jsonTransactionExportWriter struct {
+	writer io.Writer
+	first  bool
+}
+
+func newJSONTransactionExportWriter(w io.Writer) *jsonTransactionExportWriter {
+	return &jsonTransactionExportWriter{
+		writer: w,
+		first:  true,
+	}
+}
+
+func (e *jsonTransactionExportWriter) Begin() error {
+	_, err := e.writer.Write([]byte("["))
+	return err
+}
+
+func (e *jsonTransactionExportWriter) Write(row responses.TransactionExportRow) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if !e.first {
+		data = append([]byte(","), data...)
+	}
+	e.first = false
+	_, err = e.writer.Write(data)
+	return err
+}
+
+func (e *jsonTransactionExportWriter) End() error {
+	_, err := e.writer.Write([]byte("]"))
+	return err
+}
+
+// /api/group/:id/transaction (POST)
+func (h *Handler) CreateTransaction(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -926,41 +2525,340 @@ func (h *Handler) GetTransactionById(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
-	transactionId := c.Param("transactionId")
-	if transactionId == "" {
-		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing transactionId parameter", lang))
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
 	}
 
-	transaction, err := h.groupStore.GetTransactionLogEntryById(group, transactionId)
+	var body bindings.CreateTransaction
+	err = c.Bind(&body)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
 	}
-	if transaction == nil {
-		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	if body.Amount <= 0 {
+		return c.JSON(http.StatusOK, responses.New(false, "Amount must be >0", lang))
 	}
 
-	isSender := user.Id == transaction.SenderId
-	isReceiver := user.Id == transaction.ReceiverId
+	body.Title = strings.TrimSpace(body.Title)
+	body.Description = strings.TrimSpace(body.Description)
 
-	if isSender || isReceiver {
-		return c.JSON(http.StatusOK, responses.NewTransaction(transaction, user))
-	} else if transaction.SenderIsBank || transaction.ReceiverIsBank {
-		isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if utf8.RuneCountInString(body.Title) > config.Data.MaxNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Title) < config.Data.MinNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too short", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) > config.Data.MaxDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) < config.Data.MinDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
+	}
+
+	if !body.FromBank {
+		isMember, err := h.groupStore.IsMember(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isMember {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+		}
+	}
+
+	var transaction *models.TransactionLogEntry
+
+	if strings.EqualFold(body.ReceiverId, "bank") {
+		if body.FromBank {
+			return c.JSON(http.StatusOK, responses.New(false, "Cannot send money from bank to bank", lang))
+		}
+		needsApproval, err := h.needsApproval(group, user, true, int(body.Amount))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if needsApproval {
+			return h.createPendingTransaction(c, group, user, true, nil, body.Title, body.Description, int(body.Amount))
+		}
+
+		transaction, err = h.groupStore.CreateTransaction(group, false, true, user, nil, body.Title, body.Description, int(body.Amount))
+		if errors.Is(err, models.ErrNotEnoughMoney) {
+			return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+		}
+		var limitErr *models.SpendingLimitError
+		if errors.As(err, &limitErr) {
+			return c.JSON(http.StatusOK, responses.NewSpendingLimitExceeded(limitErr, group.Currency, lang))
+		}
+		if errors.Is(err, models.ErrGroupArchived) {
+			return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+		}
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, responses.NewUnexpectedError(err, lang))
+		}
+	} else {
+		receiver, err := h.userStore.GetById(body.ReceiverId)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if receiver == nil {
+			return c.JSON(http.StatusNotFound, responses.New(false, "Couldn't find receiver", lang))
+		}
+		isReceiverMember, err := h.groupStore.IsMember(group, receiver)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 		}
+		if !isReceiverMember {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Receiver not a member of the group", lang))
+		}
+
+		if body.FromBank {
+			isAdmin, err := h.groupStore.IsAdmin(group, user)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+			}
+			if !isAdmin {
+				return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+			}
+			transaction, err = h.groupStore.CreateTransaction(group, true, false, nil, receiver, body.Title, body.Description, int(body.Amount))
+			if errors.Is(err, models.ErrGroupArchived) {
+				return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+			}
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, responses.NewUnexpectedError(err, lang))
+			}
+		} else {
+			if user.Id == body.ReceiverId {
+				return c.JSON(http.StatusOK, responses.New(false, "Sender is the receiver", lang))
+			}
+			needsApproval, err := h.needsApproval(group, user, false, int(body.Amount))
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+			}
+			if needsApproval {
+				return h.createPendingTransaction(c, group, user, false, receiver, body.Title, body.Description, int(body.Amount))
+			}
+
+			transaction, err = h.groupStore.CreateTransaction(group, false, false, user, receiver, body.Title, body.Description, int(body.Amount))
+			if errors.Is(err, models.ErrNotEnoughMoney) {
+				return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+			}
+			var limitErr *models.SpendingLimitError
+			if errors.As(err, &limitErr) {
+				return c.JSON(http.StatusOK, responses.NewSpendingLimitExceeded(limitErr, group.Currency, lang))
+			}
+			if errors.Is(err, models.ErrGroupArchived) {
+				return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+			}
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.NewTransaction(transaction, user, group.Currency, lang))
+}
+
+// Creates a transaction between a member and the bank together with the matching entry in the cash log of the member.
+// Members can record their own payouts, admins can record payouts and deposits of all members.
+// /api/group/:id/transaction/cash (POST)
+func (h *Handler) CreateCashTransaction(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	var body bindings.CreateCashTransaction
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	if body.Type != "payout" && body.Type != "deposit" {
+		return c.JSON(http.StatusOK, responses.New(false, "Invalid cash transaction type", lang))
+	}
+	payout := body.Type == "payout"
+
+	body.Title = strings.TrimSpace(body.Title)
+	body.Description = strings.TrimSpace(body.Description)
+
+	if utf8.RuneCountInString(body.Title) > config.Data.MaxNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Title) < config.Data.MinNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too short", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) > config.Data.MaxDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) < config.Data.MinDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	member := user
+	if body.UserId != "" && body.UserId != user.Id {
 		if !isAdmin {
 			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
 		}
+		member, err = h.userStore.GetById(body.UserId)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if member == nil {
+			return c.JSON(http.StatusNotFound, responses.New(false, "The user doesn't exist", lang))
+		}
+	}
+
+	isMember, err := h.groupStore.IsMember(group, member)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isMember {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is not a member of the group", lang))
+	}
+
+	if !payout && !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	walletId, currency, err := h.getCashWallet(member, body.Wallet)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if currency == "" {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+	if currency != group.Currency {
+		return c.JSON(http.StatusOK, responses.New(false, "The currency of the wallet does not match the currency of the group", lang))
+	}
+
+	denominations, invalidKey := newDenominationCounts(body.Denominations, currency)
+	if invalidKey != "" {
+		return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Invalid denomination '%s' for currency %s", lang), invalidKey, currency), ""))
+	}
+	amount := 0
+	for _, d := range denominations {
+		denomination, _ := services.GetDenomination(currency, d.Key)
+		amount += denomination.Value * d.Count
+	}
+	if amount <= 0 {
+		return c.JSON(http.StatusOK, responses.New(false, "Amount must be >0", lang))
+	}
+
+	// payouts recorded by admins are handed out by them and don't need to be approved
+	if payout && !isAdmin {
+		needsApproval, err := h.needsApproval(group, member, true, amount)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if needsApproval {
+			return c.JSON(http.StatusOK, responses.New(false, "This cash payout needs to be recorded by an admin", lang))
+		}
+	}
+
+	transaction, cashEntry, err := h.groupStore.CreateCashTransaction(group, member, payout, body.Title, body.Description, walletId, denominations, member.Id == user.Id)
+	if errors.Is(err, models.ErrNotEnoughMoney) {
+		return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+	}
+	if errors.Is(err, models.ErrNotEnoughCash) {
+		return c.JSON(http.StatusOK, responses.New(false, "The wallet does not contain enough cash", lang))
+	}
+	if errors.Is(err, models.ErrCurrencyMismatch) {
+		return c.JSON(http.StatusOK, responses.New(false, "The currency of the wallet does not match the currency of the group", lang))
+	}
+	var limitErr *models.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		return c.JSON(http.StatusOK, responses.NewSpendingLimitExceeded(limitErr, group.Currency, lang))
+	}
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusCreated, responses.NewCashTransaction(transaction, cashEntry, member, group.Currency, lang))
+}
+
+// /api/group/invitation?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetInvitationsByUser(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	page := 0
+	pageSize := 20
+
+	if c.QueryParam("page") != "" {
+		page, err = strconv.Atoi(c.QueryParam("page"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'page' query parameter not a number", lang))
+		}
+	}
+
+	if c.QueryParam("pageSize") != "" {
+		pageSize, err = strconv.Atoi(c.QueryParam("pageSize"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'pageSize' query parameter not a number", lang))
+		}
+		if pageSize > config.Data.MaxPageSize || pageSize < 1 {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "Unsupported page size", lang))
+		}
+	}
+
+	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
+
+	invitations, err := h.groupStore.GetInvitationsByUser(user, page, pageSize, oldestFirst)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
 
-		return c.JSON(http.StatusOK, responses.NewBankTransaction(transaction))
+	count, err := h.groupStore.InvitationCountByUser(user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusForbidden, responses.New(false, "User not allowed to view transaction", lang))
+	return c.JSON(http.StatusOK, responses.NewInvitations(invitations, count))
 }
 
-// /api/group/:id/transaction?bank=bool&search=string&page=int&pageSize=int&oldestFirst=bool (GET)
-func (h *Handler) GetTransactionLog(c echo.Context) error {
+// /api/group/:id/invitation?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetInvitationsByGroup(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -998,6 +2896,7 @@ func (h *Handler) GetTransactionLog(c echo.Context) error {
 	if groupId == "" {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
 	}
+
 	group, err := h.groupStore.GetById(groupId)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
@@ -1006,55 +2905,239 @@ func (h *Handler) GetTransactionLog(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
-	bank := services.StrToBool(c.QueryParam("bank"))
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
 
-	if !bank {
-		isMember, err := h.groupStore.IsMember(group, user)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-		}
+	invitations, err := h.groupStore.GetInvitationsByGroup(group, page, pageSize, oldestFirst)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
 
-		if !isMember {
-			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
-		}
+	count, err := h.groupStore.InvitationCountByGroup(group)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
 
-		log, err := h.groupStore.GetTransactionLog(group, user, c.QueryParam("search"), page, pageSize, oldestFirst)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-		}
+	return c.JSON(http.StatusOK, responses.NewInvitations(invitations, count))
+}
 
-		count, err := h.groupStore.TransactionLogEntryCount(group, user)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-		}
+// /api/group/invitation/:id (GET)
+func (h *Handler) GetInvitationById(c echo.Context) error {
+	lang := c.Get("lang").(string)
 
-		return c.JSON(http.StatusOK, responses.NewTransactionLog(log, user, count))
-	} else {
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	invitation, err := h.groupStore.GetInvitationById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if invitation == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	group, err := h.groupStore.GetById(invitation.GroupId)
+	if err != nil || group == nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if userId != invitation.UserId {
 		isAdmin, err := h.groupStore.IsAdmin(group, user)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 		}
-
 		if !isAdmin {
 			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
 		}
+	}
 
-		log, err := h.groupStore.GetBankTransactionLog(group, c.QueryParam("search"), page, pageSize, oldestFirst)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-		}
+	return c.JSON(http.StatusOK, responses.NewInvitation(invitation))
+}
 
-		count, err := h.groupStore.BankTransactionLogEntryCount(group)
+// /api/group/:id/invitation (POST)
+func (h *Handler) CreateInvitation(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	authUserId := c.Get("userId").(string)
+	authUser, err := h.userStore.GetById(authUserId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if authUser == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	var body bindings.CreateInvitation
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	if utf8.RuneCountInString(body.Message) > config.Data.MaxDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Message too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Message) < config.Data.MinDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Message too short", lang))
+	}
+
+	if body.UserId == authUserId {
+		return c.JSON(http.StatusOK, responses.New(false, "You can't invite yourself", lang))
+	}
+
+	user, err := h.userStore.GetById(body.UserId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusOK, responses.New(false, "The user doesn't exist", lang))
+	}
+
+	userIsInGroup, err := h.groupStore.IsInGroup(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if userIsInGroup {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is already a member/an admin of the group", lang))
+	}
+
+	authUserIsAdmin, err := h.groupStore.IsAdmin(group, authUser)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !authUserIsAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	invitation, err := h.groupStore.GetInvitationByGroupAndUser(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if invitation != nil {
+		return c.JSON(http.StatusOK, responses.New(false, "The user was already invited", lang))
+	}
+
+	invitation, err = h.groupStore.CreateInvitation(group, user, body.Message)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if !user.DontSendInvitationEmail && config.Data.EmailEnabled {
+		type templateData struct {
+			Name           string
+			GroupName      string
+			InvitationsUrl string
+		}
+		body, err := services.ParseEmailTemplate("invitation", c.Get("lang").(string), templateData{
+			Name:           user.Name,
+			GroupName:      group.Name,
+			InvitationsUrl: fmt.Sprintf("%s/invitations", config.Data.BaseURL),
+		})
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 		}
+		go services.SendEmail([]string{user.Email}, services.Tr("H-Bank Invitation", lang), body)
+	}
+
+	return c.JSON(http.StatusCreated, responses.NewInvitation(invitation))
+}
+
+// /api/group/invitation/:id (POST)
+func (h *Handler) AcceptInvitation(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	invitation, err := h.groupStore.GetInvitationById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if invitation == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	group, err := h.groupStore.GetById(invitation.GroupId)
+	if err != nil || group == nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if userId != invitation.UserId {
+		return c.JSON(http.StatusForbidden, responses.New(false, "User is not the receiver of the invitation", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	isInGroup, err := h.groupStore.IsInGroup(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if isInGroup {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is already a member/an admin of the group", lang))
+	}
+
+	err = h.groupStore.AddMember(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
 
-		return c.JSON(http.StatusOK, responses.NewBankTransactionLog(log, count))
+	err = h.groupStore.DeleteInvitation(invitation)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
+
+	return c.JSON(http.StatusOK, responses.NewGroup(group, true, false))
 }
 
-// /api/group/:id/transaction (POST)
-func (h *Handler) CreateTransaction(c echo.Context) error {
+// /api/group/invitation/:id (DELETE)
+func (h *Handler) DenyInvitation(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -1062,123 +3145,90 @@ func (h *Handler) CreateTransaction(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if user == nil {
-		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	invitation, err := h.groupStore.GetInvitationById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if invitation == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
 	}
 
-	groupId := c.Param("id")
-	if groupId == "" {
-		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
-	}
-	group, err := h.groupStore.GetById(groupId)
-	if err != nil {
+	group, err := h.groupStore.GetById(invitation.GroupId)
+	if err != nil || group == nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if group == nil {
-		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+
+	if userId != invitation.UserId {
+		return c.JSON(http.StatusForbidden, responses.New(false, "User is not the receiver of the invitation", lang))
 	}
 
-	var body bindings.CreateTransaction
-	err = c.Bind(&body)
+	err = h.groupStore.DeleteInvitation(invitation)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
-	}
-	if body.Amount <= 0 {
-		return c.JSON(http.StatusOK, responses.New(false, "Amount must be >0", lang))
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	body.Title = strings.TrimSpace(body.Title)
-	body.Description = strings.TrimSpace(body.Description)
+	return c.JSON(http.StatusOK, responses.New(true, "Successfully denied invitation", lang))
+}
 
-	if utf8.RuneCountInString(body.Title) > config.Data.MaxNameLength {
-		return c.JSON(http.StatusOK, responses.New(false, "Title too long", lang))
-	}
+// /api/group/paymentRequest/incoming?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetIncomingPaymentRequests(c echo.Context) error {
+	lang := c.Get("lang").(string)
 
-	if utf8.RuneCountInString(body.Title) < config.Data.MinNameLength {
-		return c.JSON(http.StatusOK, responses.New(false, "Title too short", lang))
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-
-	if utf8.RuneCountInString(body.Description) > config.Data.MaxDescriptionLength {
-		return c.JSON(http.StatusOK, responses.New(false, "Description too long", lang))
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
 	}
 
-	if utf8.RuneCountInString(body.Description) < config.Data.MinDescriptionLength {
-		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
-	}
+	page := 0
+	pageSize := 20
 
-	if !body.FromBank {
-		isMember, err := h.groupStore.IsMember(group, user)
+	if c.QueryParam("page") != "" {
+		page, err = strconv.Atoi(c.QueryParam("page"))
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-		}
-		if !isMember {
-			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'page' query parameter not a number", lang))
 		}
+	}
 
-		balanceSender, err := h.groupStore.GetUserBalance(group, user)
+	if c.QueryParam("pageSize") != "" {
+		pageSize, err = strconv.Atoi(c.QueryParam("pageSize"))
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'pageSize' query parameter not a number", lang))
 		}
-
-		if balanceSender-int(body.Amount) < 0 {
-			return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+		if pageSize > config.Data.MaxPageSize || pageSize < 1 {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "Unsupported page size", lang))
 		}
 	}
 
-	var transaction *models.TransactionLogEntry
+	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
 
-	if strings.EqualFold(body.ReceiverId, "bank") {
-		if body.FromBank {
-			return c.JSON(http.StatusOK, responses.New(false, "Cannot send money from bank to bank", lang))
-		}
-		transaction, err = h.groupStore.CreateTransaction(group, false, true, user, nil, body.Title, body.Description, int(body.Amount))
-		if err != nil {
-			return c.JSON(http.StatusUnauthorized, responses.NewUnexpectedError(err, lang))
-		}
-	} else {
-		receiver, err := h.userStore.GetById(body.ReceiverId)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-		}
-		if receiver == nil {
-			return c.JSON(http.StatusNotFound, responses.New(false, "Couldn't find receiver", lang))
-		}
-		isReceiverMember, err := h.groupStore.IsMember(group, receiver)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-		}
-		if !isReceiverMember {
-			return c.JSON(http.StatusForbidden, responses.New(false, "Receiver not a member of the group", lang))
-		}
+	requests, err := h.groupStore.GetIncomingPaymentRequests(user, page, pageSize, oldestFirst)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
 
-		if body.FromBank {
-			isAdmin, err := h.groupStore.IsAdmin(group, user)
-			if err != nil {
-				return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-			}
-			if !isAdmin {
-				return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
-			}
-			transaction, err = h.groupStore.CreateTransaction(group, true, false, nil, receiver, body.Title, body.Description, int(body.Amount))
-			if err != nil {
-				return c.JSON(http.StatusUnauthorized, responses.NewUnexpectedError(err, lang))
-			}
-		} else {
-			if user.Id == body.ReceiverId {
-				return c.JSON(http.StatusOK, responses.New(false, "Sender is the receiver", lang))
-			}
-			transaction, err = h.groupStore.CreateTransaction(group, false, false, user, receiver, body.Title, body.Description, int(body.Amount))
-			if err != nil {
-				return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-			}
-		}
+	count, err := h.groupStore.IncomingPaymentRequestCount(user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusOK, responses.NewTransaction(transaction, user))
+	return c.JSON(http.StatusOK, responses.NewPaymentRequests(requests, count))
 }
 
-// /api/group/invitation?page=int&pageSize=int&oldestFirst=bool (GET)
-func (h *Handler) GetInvitationsByUser(c echo.Context) error {
+// /api/group/paymentRequest/outgoing?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetOutgoingPaymentRequests(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -1212,21 +3262,21 @@ func (h *Handler) GetInvitationsByUser(c echo.Context) error {
 
 	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
 
-	invitations, err := h.groupStore.GetInvitationsByUser(user, page, pageSize, oldestFirst)
+	requests, err := h.groupStore.GetOutgoingPaymentRequests(user, page, pageSize, oldestFirst)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	count, err := h.groupStore.InvitationCountByUser(user)
+	count, err := h.groupStore.OutgoingPaymentRequestCount(user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusOK, responses.NewInvitations(invitations, count))
+	return c.JSON(http.StatusOK, responses.NewPaymentRequests(requests, count))
 }
 
-// /api/group/:id/invitation?page=int&pageSize=int&oldestFirst=bool (GET)
-func (h *Handler) GetInvitationsByGroup(c echo.Context) error {
+// /api/group/:id/paymentRequest?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetBankPaymentRequests(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -1281,21 +3331,21 @@ func (h *Handler) GetInvitationsByGroup(c echo.Context) error {
 		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
 	}
 
-	invitations, err := h.groupStore.GetInvitationsByGroup(group, page, pageSize, oldestFirst)
+	requests, err := h.groupStore.GetBankPaymentRequests(group, page, pageSize, oldestFirst)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	count, err := h.groupStore.InvitationCountByGroup(group)
+	count, err := h.groupStore.BankPaymentRequestCount(group)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusOK, responses.NewInvitations(invitations, count))
+	return c.JSON(http.StatusOK, responses.NewPaymentRequests(requests, count))
 }
 
-// /api/group/invitation/:id (GET)
-func (h *Handler) GetInvitationById(c echo.Context) error {
+// /api/group/paymentRequest/:id (GET)
+func (h *Handler) GetPaymentRequestById(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -1312,42 +3362,42 @@ func (h *Handler) GetInvitationById(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
 	}
 
-	invitation, err := h.groupStore.GetInvitationById(id)
+	request, err := h.groupStore.GetPaymentRequestById(id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if invitation == nil {
+	if request == nil {
 		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
 	}
 
-	group, err := h.groupStore.GetById(invitation.GroupId)
+	group, err := h.groupStore.GetById(request.GroupId)
 	if err != nil || group == nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	if userId != invitation.UserId {
-		isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if userId != request.RequesterId {
+		isPayer, err := h.isPayerOfPaymentRequest(group, user, request)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 		}
-		if !isAdmin {
-			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+		if !isPayer {
+			return c.JSON(http.StatusForbidden, responses.New(false, "User not allowed to view payment request", lang))
 		}
 	}
 
-	return c.JSON(http.StatusOK, responses.NewInvitation(invitation))
+	return c.JSON(http.StatusOK, responses.NewPaymentRequest(request))
 }
 
-// /api/group/:id/invitation (POST)
-func (h *Handler) CreateInvitation(c echo.Context) error {
+// /api/group/:id/paymentRequest (POST)
+func (h *Handler) CreatePaymentRequest(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
-	authUserId := c.Get("userId").(string)
-	authUser, err := h.userStore.GetById(authUserId)
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if authUser == nil {
+	if user == nil {
 		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
 	}
 
@@ -1363,84 +3413,191 @@ func (h *Handler) CreateInvitation(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
-	var body bindings.CreateInvitation
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	var body bindings.CreatePaymentRequest
 	err = c.Bind(&body)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
 	}
+	if body.Amount <= 0 {
+		return c.JSON(http.StatusOK, responses.New(false, "Amount must be >0", lang))
+	}
 
-	if utf8.RuneCountInString(body.Message) > config.Data.MaxDescriptionLength {
-		return c.JSON(http.StatusOK, responses.New(false, "Message too long", lang))
+	body.Title = strings.TrimSpace(body.Title)
+	body.Description = strings.TrimSpace(body.Description)
+
+	if utf8.RuneCountInString(body.Title) > config.Data.MaxNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too long", lang))
 	}
 
-	if utf8.RuneCountInString(body.Message) < config.Data.MinDescriptionLength {
-		return c.JSON(http.StatusOK, responses.New(false, "Message too short", lang))
+	if utf8.RuneCountInString(body.Title) < config.Data.MinNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too short", lang))
 	}
 
-	if body.UserId == authUserId {
-		return c.JSON(http.StatusOK, responses.New(false, "You can't invite yourself", lang))
+	if utf8.RuneCountInString(body.Description) > config.Data.MaxDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too long", lang))
 	}
 
-	user, err := h.userStore.GetById(body.UserId)
+	if utf8.RuneCountInString(body.Description) < config.Data.MinDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
+	}
+
+	isMember, err := h.groupStore.IsMember(group, user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if user == nil {
-		return c.JSON(http.StatusOK, responses.New(false, "The user doesn't exist", lang))
+	if !isMember {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
 	}
 
-	userIsInGroup, err := h.groupStore.IsInGroup(group, user)
+	var request *models.PaymentRequest
+	if strings.EqualFold(body.PayerId, "bank") {
+		request, err = h.groupStore.CreatePaymentRequest(group, user, true, nil, body.Title, body.Description, int(body.Amount))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+	} else {
+		if body.PayerId == user.Id {
+			return c.JSON(http.StatusOK, responses.New(false, "You can't request money from yourself", lang))
+		}
+
+		payer, err := h.userStore.GetById(body.PayerId)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if payer == nil {
+			return c.JSON(http.StatusNotFound, responses.New(false, "Couldn't find payer", lang))
+		}
+		isPayerMember, err := h.groupStore.IsMember(group, payer)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isPayerMember {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Payer not a member of the group", lang))
+		}
+
+		request, err = h.groupStore.CreatePaymentRequest(group, user, false, payer, body.Title, body.Description, int(body.Amount))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+	}
+
+	return c.JSON(http.StatusCreated, responses.NewPaymentRequest(request))
+}
+
+// /api/group/paymentRequest/:id (POST)
+func (h *Handler) ApprovePaymentRequest(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
 
-	if userIsInGroup {
-		return c.JSON(http.StatusOK, responses.New(false, "The user is already a member/an admin of the group", lang))
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
 	}
 
-	authUserIsAdmin, err := h.groupStore.IsAdmin(group, authUser)
+	request, err := h.groupStore.GetPaymentRequestById(id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if !authUserIsAdmin {
-		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	if request == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
 	}
 
-	invitation, err := h.groupStore.GetInvitationByGroupAndUser(group, user)
-	if err != nil {
+	group, err := h.groupStore.GetById(request.GroupId)
+	if err != nil || group == nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if invitation != nil {
-		return c.JSON(http.StatusOK, responses.New(false, "The user was already invited", lang))
-	}
 
-	invitation, err = h.groupStore.CreateInvitation(group, user, body.Message)
+	isPayer, err := h.isPayerOfPaymentRequest(group, user, request)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
+	if !isPayer {
+		return c.JSON(http.StatusForbidden, responses.New(false, "User is not the payer of the payment request", lang))
+	}
 
-	if !user.DontSendInvitationEmail && config.Data.EmailEnabled {
-		type templateData struct {
-			Name           string
-			GroupName      string
-			InvitationsUrl string
-		}
-		body, err := services.ParseEmailTemplate("invitation", c.Get("lang").(string), templateData{
-			Name:           user.Name,
-			GroupName:      group.Name,
-			InvitationsUrl: fmt.Sprintf("%s/invitations", config.Data.BaseURL),
-		})
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	if !request.PayerIsBank {
+		needsApproval, err := h.needsApproval(group, user, false, request.Amount)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 		}
-		go services.SendEmail([]string{user.Email}, services.Tr("H-Bank Invitation", lang), body)
+		if needsApproval {
+			return h.approvePaymentRequestPending(c, group, request)
+		}
+	}
+
+	transaction, err := h.groupStore.ApprovePaymentRequest(group, request)
+	if errors.Is(err, models.ErrNotEnoughMoney) {
+		return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+	}
+	var limitErr *models.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		return c.JSON(http.StatusOK, responses.NewSpendingLimitExceeded(limitErr, group.Currency, lang))
+	}
+	if errors.Is(err, models.ErrNotInGroup) {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is not a member of the group", lang))
+	}
+	if errors.Is(err, models.ErrPaymentRequestHandled) {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if request.PayerIsBank {
+		return c.JSON(http.StatusOK, responses.NewBankTransaction(transaction, group.Currency, lang))
+	}
+	return c.JSON(http.StatusOK, responses.NewTransaction(transaction, user, group.Currency, lang))
+}
+
+func (h *Handler) approvePaymentRequestPending(c echo.Context, group *models.Group, request *models.PaymentRequest) error {
+	lang := c.Get("lang").(string)
+
+	pending, err := h.groupStore.ApprovePaymentRequestPending(group, request)
+	if errors.Is(err, models.ErrNotEnoughMoney) {
+		return c.JSON(http.StatusOK, responses.New(false, "Not enough money", lang))
+	}
+	var limitErr *models.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		return c.JSON(http.StatusOK, responses.NewSpendingLimitExceeded(limitErr, group.Currency, lang))
+	}
+	if errors.Is(err, models.ErrNotInGroup) {
+		return c.JSON(http.StatusOK, responses.New(false, "The user is not a member of the group", lang))
+	}
+	if errors.Is(err, models.ErrPaymentRequestHandled) {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusCreated, responses.NewInvitation(invitation))
+	return c.JSON(http.StatusAccepted, responses.NewPendingTransaction(pending, lang))
 }
 
-// /api/group/invitation/:id (POST)
-func (h *Handler) AcceptInvitation(c echo.Context) error {
+// Declines the payment request if the user is the payer and withdraws it if the user is the requester.
+// /api/group/paymentRequest/:id (DELETE)
+func (h *Handler) DeclinePaymentRequest(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -1457,47 +3614,49 @@ func (h *Handler) AcceptInvitation(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
 	}
 
-	invitation, err := h.groupStore.GetInvitationById(id)
+	request, err := h.groupStore.GetPaymentRequestById(id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if invitation == nil {
+	if request == nil {
 		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
 	}
 
-	group, err := h.groupStore.GetById(invitation.GroupId)
+	group, err := h.groupStore.GetById(request.GroupId)
 	if err != nil || group == nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	if userId != invitation.UserId {
-		return c.JSON(http.StatusForbidden, responses.New(false, "User is not the receiver of the invitation", lang))
+	message := "Successfully withdrew payment request"
+	if userId != request.RequesterId {
+		isPayer, err := h.isPayerOfPaymentRequest(group, user, request)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isPayer {
+			return c.JSON(http.StatusForbidden, responses.New(false, "User is not the payer of the payment request", lang))
+		}
+		message = "Successfully declined payment request"
 	}
 
-	isInGroup, err := h.groupStore.IsInGroup(group, user)
+	err = h.groupStore.DeletePaymentRequest(request)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	if isInGroup {
-		return c.JSON(http.StatusOK, responses.New(false, "The user is already a member/an admin of the group", lang))
-	}
-
-	err = h.groupStore.AddMember(group, user)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
-	}
+	return c.JSON(http.StatusOK, responses.New(true, message, lang))
+}
 
-	err = h.groupStore.DeleteInvitation(invitation)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+// Requests to the bank are paid by the admins of the group.
+func (h *Handler) isPayerOfPaymentRequest(group *models.Group, user *models.User, request *models.PaymentRequest) (bool, error) {
+	if !request.PayerIsBank {
+		return user.Id == request.PayerId, nil
 	}
-
-	return c.JSON(http.StatusOK, responses.NewGroup(group, true, false))
+	return h.groupStore.IsAdmin(group, user)
 }
 
-// /api/group/invitation/:id (DELETE)
-func (h *Handler) DenyInvitation(c echo.Context) error {
+// /api/group/:id/paymentPlan/:paymentPlanId (GET)
+func (h *Handler) GetPaymentPlanById(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -1509,38 +3668,61 @@ func (h *Handler) DenyInvitation(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
 	}
 
-	id := c.Param("id")
-	if id == "" {
+	groupId := c.Param("id")
+	if groupId == "" {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
 	}
-
-	invitation, err := h.groupStore.GetInvitationById(id)
+	group, err := h.groupStore.GetById(groupId)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
-	if invitation == nil {
-		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
-	}
-
-	group, err := h.groupStore.GetById(invitation.GroupId)
-	if err != nil || group == nil {
-		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
-	if userId != invitation.UserId {
-		return c.JSON(http.StatusForbidden, responses.New(false, "User is not the receiver of the invitation", lang))
+	paymentPlanId := c.Param("paymentPlanId")
+	if paymentPlanId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
 	}
 
-	err = h.groupStore.DeleteInvitation(invitation)
+	paymentPlan, err := h.groupStore.GetPaymentPlanById(group, paymentPlanId)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
+	if paymentPlan == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
 
-	return c.JSON(http.StatusOK, responses.New(true, "Successfully denied invitation", lang))
+	isSender := user.Id == paymentPlan.SenderId
+	isReceiver := user.Id == paymentPlan.ReceiverId
+
+	if isSender || isReceiver {
+		isMember, err := h.groupStore.IsMember(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isMember {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
+		}
+
+		return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan, group.Currency, lang))
+	} else if paymentPlan.SenderIsBank || paymentPlan.ReceiverIsBank {
+		isAdmin, err := h.groupStore.IsAdmin(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+		}
+
+		return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan, group.Currency, lang))
+	}
+
+	return c.JSON(http.StatusForbidden, responses.New(false, "User not allowed to view payment plan", lang))
 }
 
-// /api/group/:id/paymentPlan/:paymentPlanId (GET)
-func (h *Handler) GetPaymentPlanById(c echo.Context) error {
+// /api/group/:id/paymentPlan/:paymentPlanId/executions?page=int&pageSize=int&oldestFirst=bool (GET)
+func (h *Handler) GetPaymentPlanExecutions(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
 	userId := c.Get("userId").(string)
@@ -1552,6 +3734,28 @@ func (h *Handler) GetPaymentPlanById(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
 	}
 
+	page := 0
+	pageSize := 20
+
+	if c.QueryParam("page") != "" {
+		page, err = strconv.Atoi(c.QueryParam("page"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'page' query parameter not a number", lang))
+		}
+	}
+
+	if c.QueryParam("pageSize") != "" {
+		pageSize, err = strconv.Atoi(c.QueryParam("pageSize"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "'pageSize' query parameter not a number", lang))
+		}
+		if pageSize > config.Data.MaxPageSize || pageSize < 1 {
+			return c.JSON(http.StatusBadRequest, responses.New(false, "Unsupported page size", lang))
+		}
+	}
+
+	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
+
 	groupId := c.Param("id")
 	if groupId == "" {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
@@ -1577,10 +3781,7 @@ func (h *Handler) GetPaymentPlanById(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
 	}
 
-	isSender := user.Id == paymentPlan.SenderId
-	isReceiver := user.Id == paymentPlan.ReceiverId
-
-	if isSender || isReceiver {
+	if user.Id == paymentPlan.SenderId || user.Id == paymentPlan.ReceiverId {
 		isMember, err := h.groupStore.IsMember(group, user)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
@@ -1588,8 +3789,6 @@ func (h *Handler) GetPaymentPlanById(c echo.Context) error {
 		if !isMember {
 			return c.JSON(http.StatusForbidden, responses.New(false, "Not a member of the group", lang))
 		}
-
-		return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan))
 	} else if paymentPlan.SenderIsBank || paymentPlan.ReceiverIsBank {
 		isAdmin, err := h.groupStore.IsAdmin(group, user)
 		if err != nil {
@@ -1598,11 +3797,21 @@ func (h *Handler) GetPaymentPlanById(c echo.Context) error {
 		if !isAdmin {
 			return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
 		}
+	} else {
+		return c.JSON(http.StatusForbidden, responses.New(false, "User not allowed to view payment plan", lang))
+	}
 
-		return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan))
+	executions, err := h.groupStore.GetPaymentPlanExecutions(paymentPlan, page, pageSize, oldestFirst)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusForbidden, responses.New(false, "User not allowed to view payment plan", lang))
+	count, err := h.groupStore.PaymentPlanExecutionCount(paymentPlan)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewPaymentPlanExecutions(executions, count))
 }
 
 // /api/group/:id/paymentPlan?bank=bool&search=string&page=int&pageSize=int&oldestFirst=bool (GET)
@@ -1674,7 +3883,7 @@ func (h *Handler) GetPaymentPlans(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 		}
 
-		return c.JSON(http.StatusOK, responses.NewPaymentPlans(paymentPlans, count))
+		return c.JSON(http.StatusOK, responses.NewPaymentPlans(paymentPlans, count, group.Currency, lang))
 	} else {
 		isAdmin, err := h.groupStore.IsAdmin(group, user)
 		if err != nil {
@@ -1695,11 +3904,11 @@ func (h *Handler) GetPaymentPlans(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 		}
 
-		return c.JSON(http.StatusOK, responses.NewPaymentPlans(paymentPlans, count))
+		return c.JSON(http.StatusOK, responses.NewPaymentPlans(paymentPlans, count, group.Currency, lang))
 	}
 }
 
-// /api/group/:id/paymentPlan/nextPayment?id=uuid&firstPayment=int&schedule=int&scheduleUnit=string&count=int
+// /api/group/:id/paymentPlan/nextPayment?id=uuid&firstPayment=int&schedule=int&scheduleUnit=string&scheduleRule=string&weekdaysOnly=bool&endDate=int&count=int
 func (h *Handler) GetPaymentPlanNextPayments(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
@@ -1735,9 +3944,11 @@ func (h *Handler) GetPaymentPlanNextPayments(c echo.Context) error {
 		}
 	}
 
-	schedule := -1
-	scheduleUnit := ""
-	firstPayment := int64(-1)
+	var paymentSchedule services.Schedule
+	// the first payment is included
+	after := int64(-1)
+	paymentCount := -1
+	endDate := int64(0)
 
 	if c.QueryParam("id") != "" {
 		id := c.QueryParam("id")
@@ -1776,38 +3987,59 @@ func (h *Handler) GetPaymentPlanNextPayments(c echo.Context) error {
 			return c.JSON(http.StatusForbidden, responses.New(false, "User not allowed to view payment plan", lang))
 		}
 
-		schedule = paymentPlan.Schedule
-		scheduleUnit = paymentPlan.ScheduleUnit
-		firstPayment = paymentPlan.NextExecute
+		paymentSchedule = paymentPlan.PaymentSchedule()
+		after = paymentPlan.NextExecute - 1
+		paymentCount = paymentPlan.PaymentCount
+		endDate = paymentPlan.EndDate
 	} else {
 		if c.QueryParam("schedule") != "" {
-			schedule, err = strconv.Atoi(c.QueryParam("schedule"))
-			if err != nil || schedule < 1 {
+			paymentSchedule.Interval, err = strconv.Atoi(c.QueryParam("schedule"))
+			if err != nil || paymentSchedule.Interval < 1 {
 				return c.JSON(http.StatusBadRequest, responses.New(false, "'schedule' query parameter not a number or <1", lang))
 			}
 		} else {
 			return c.JSON(http.StatusBadRequest, responses.New(false, "Missing 'schedule' or 'id' query parameter", lang))
 		}
 
-		scheduleUnit = strings.ToLower(c.QueryParam("scheduleUnit"))
-		if scheduleUnit != models.ScheduleUnitDay && scheduleUnit != models.ScheduleUnitWeek && scheduleUnit != models.ScheduleUnitMonth && scheduleUnit != models.ScheduleUnitYear {
+		paymentSchedule.Unit = strings.ToLower(c.QueryParam("scheduleUnit"))
+		if paymentSchedule.Unit != models.ScheduleUnitDay && paymentSchedule.Unit != models.ScheduleUnitWeek && paymentSchedule.Unit != models.ScheduleUnitMonth && paymentSchedule.Unit != models.ScheduleUnitYear {
 			return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid schedule unit", lang))
 		}
 
 		if c.QueryParam("firstPayment") != "" {
-			firstPayment, err = strconv.ParseInt(c.QueryParam("firstPayment"), 10, 64)
+			paymentSchedule.Start, err = strconv.ParseInt(c.QueryParam("firstPayment"), 10, 64)
 			if err != nil {
 				return c.JSON(http.StatusBadRequest, responses.New(false, "'firstPayment' query parameter not a number", lang))
 			}
 		} else {
 			return c.JSON(http.StatusBadRequest, responses.New(false, "Missing 'firstPayment' or 'id' query parameter", lang))
 		}
+
+		paymentSchedule.WeekdaysOnly = services.StrToBool(c.QueryParam("weekdaysOnly"))
+		paymentSchedule.Location = group.Location()
+		paymentSchedule.Rule = c.QueryParam("scheduleRule")
+		var code int
+		var resp interface{}
+		paymentSchedule.Rule, _, code, resp = validatePaymentPlanSchedule(paymentSchedule, "", lang)
+		if resp != nil {
+			return c.JSON(code, resp)
+		}
+
+		if c.QueryParam("endDate") != "" {
+			endDate, err = strconv.ParseInt(c.QueryParam("endDate"), 10, 64)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, responses.New(false, "'endDate' query parameter not a number", lang))
+			}
+		}
 	}
 
-	executionTimes := make([]int64, count)
-	for i := 0; i < count; i++ {
-		executionTimes[i] = firstPayment
-		firstPayment = services.AddTime(firstPayment, schedule, scheduleUnit)
+	executionTimes := make([]int64, 0, count)
+	for len(executionTimes) < count && len(executionTimes) != paymentCount {
+		after = paymentSchedule.Next(after)
+		if endDate > 0 && after > endDate {
+			break
+		}
+		executionTimes = append(executionTimes, after)
 	}
 
 	return c.JSON(http.StatusOK, responses.PaymentPlanExecutionTimes{
@@ -1843,6 +4075,10 @@ func (h *Handler) CreatePaymentPlan(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
 	var body bindings.CreatePaymentPlan
 	err = c.Bind(&body)
 	if err != nil {
@@ -1882,7 +4118,7 @@ func (h *Handler) CreatePaymentPlan(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid schedule unit", lang))
 	}
 
-	firstPayment, err := time.Parse("2006-01-02", body.FirstPayment)
+	firstPayment, err := parsePaymentTime(body.FirstPayment, body.TimeOfDay, group.Location())
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid date string", lang))
 	}
@@ -1894,6 +4130,26 @@ func (h *Handler) CreatePaymentPlan(c echo.Context) error {
 		body.PaymentCount = -1
 	}
 
+	scheduleRule, endDate, code, resp := validatePaymentPlanSchedule(services.Schedule{
+		Start:        firstPayment.Unix(),
+		Interval:     int(body.Schedule),
+		Unit:         body.ScheduleUnit,
+		Rule:         body.ScheduleRule,
+		WeekdaysOnly: body.WeekdaysOnly,
+		Location:     group.Location(),
+	}, body.EndDate, lang)
+	if resp != nil {
+		return c.JSON(code, resp)
+	}
+
+	body.FailurePolicy = strings.ToLower(body.FailurePolicy)
+	if body.FailurePolicy == "" {
+		body.FailurePolicy = models.PaymentPlanFailureRetry
+	}
+	if body.FailurePolicy != models.PaymentPlanFailureRetry && body.FailurePolicy != models.PaymentPlanFailureSkip && body.FailurePolicy != models.PaymentPlanFailurePause {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid failure policy", lang))
+	}
+
 	if !body.FromBank {
 		isMember, err := h.groupStore.IsMember(group, user)
 		if err != nil {
@@ -1910,7 +4166,14 @@ func (h *Handler) CreatePaymentPlan(c echo.Context) error {
 		if body.FromBank {
 			return c.JSON(http.StatusOK, responses.New(false, "Cannot send money from bank to bank", lang))
 		}
-		paymentPlan, err = h.groupStore.CreatePaymentPlan(group, false, true, user, nil, body.Name, body.Description, int(body.Amount), body.PaymentCount, int(body.Schedule), body.ScheduleUnit, firstPayment.Unix())
+		needsApproval, err := h.needsApproval(group, user, true, int(body.Amount))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if needsApproval {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Payment plans that need approval can only be set up by an admin", lang))
+		}
+		paymentPlan, err = h.groupStore.CreatePaymentPlan(group, false, true, user, nil, body.Name, body.Description, int(body.Amount), body.PaymentCount, int(body.Schedule), body.ScheduleUnit, scheduleRule, body.WeekdaysOnly, firstPayment.Unix(), endDate, body.FailurePolicy)
 		if err != nil {
 			return c.JSON(http.StatusUnauthorized, responses.NewUnexpectedError(err, lang))
 		}
@@ -1938,7 +4201,7 @@ func (h *Handler) CreatePaymentPlan(c echo.Context) error {
 			if !isAdmin {
 				return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
 			}
-			paymentPlan, err = h.groupStore.CreatePaymentPlan(group, true, false, nil, receiver, body.Name, body.Description, int(body.Amount), body.PaymentCount, int(body.Schedule), body.ScheduleUnit, firstPayment.Unix())
+			paymentPlan, err = h.groupStore.CreatePaymentPlan(group, true, false, nil, receiver, body.Name, body.Description, int(body.Amount), body.PaymentCount, int(body.Schedule), body.ScheduleUnit, scheduleRule, body.WeekdaysOnly, firstPayment.Unix(), endDate, body.FailurePolicy)
 			if err != nil {
 				return c.JSON(http.StatusUnauthorized, responses.NewUnexpectedError(err, lang))
 			}
@@ -1946,14 +4209,21 @@ func (h *Handler) CreatePaymentPlan(c echo.Context) error {
 			if user.Id == body.ReceiverId {
 				return c.JSON(http.StatusOK, responses.New(false, "Sender is the receiver", lang))
 			}
-			paymentPlan, err = h.groupStore.CreatePaymentPlan(group, false, false, user, receiver, body.Name, body.Description, int(body.Amount), body.PaymentCount, int(body.Schedule), body.ScheduleUnit, firstPayment.Unix())
+			needsApproval, err := h.needsApproval(group, user, false, int(body.Amount))
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+			}
+			if needsApproval {
+				return c.JSON(http.StatusForbidden, responses.New(false, "Payment plans that need approval can only be set up by an admin", lang))
+			}
+			paymentPlan, err = h.groupStore.CreatePaymentPlan(group, false, false, user, receiver, body.Name, body.Description, int(body.Amount), body.PaymentCount, int(body.Schedule), body.ScheduleUnit, scheduleRule, body.WeekdaysOnly, firstPayment.Unix(), endDate, body.FailurePolicy)
 			if err != nil {
 				return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 			}
 		}
 	}
 
-	return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan))
+	return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan, group.Currency, lang))
 }
 
 // /api/group/:id/paymentPlan/:paymentPlanId (DELETE)
@@ -2038,6 +4308,10 @@ func (h *Handler) UpdatePaymentPlan(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
 	}
 
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
 	paymentPlanId := c.Param("paymentPlanId")
 	if paymentPlanId == "" {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
@@ -2072,6 +4346,16 @@ func (h *Handler) UpdatePaymentPlan(c echo.Context) error {
 		return c.JSON(http.StatusOK, responses.New(false, "Amount must be >0", lang))
 	}
 
+	if !paymentPlan.SenderIsBank {
+		needsApproval, err := h.needsApproval(group, user, paymentPlan.ReceiverIsBank, int(body.Amount))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if needsApproval {
+			return c.JSON(http.StatusForbidden, responses.New(false, "Payment plans that need approval can only be set up by an admin", lang))
+		}
+	}
+
 	if body.Schedule <= 0 {
 		return c.JSON(http.StatusOK, responses.New(false, "Schedule must be >0", lang))
 	}
@@ -2101,7 +4385,12 @@ func (h *Handler) UpdatePaymentPlan(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid schedule unit", lang))
 	}
 
-	nextPayment, err := time.Parse("2006-01-02", body.NextPayment)
+	body.FailurePolicy = strings.ToLower(body.FailurePolicy)
+	if body.FailurePolicy != "" && body.FailurePolicy != models.PaymentPlanFailureRetry && body.FailurePolicy != models.PaymentPlanFailureSkip && body.FailurePolicy != models.PaymentPlanFailurePause {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid failure policy", lang))
+	}
+
+	nextPayment, err := parsePaymentTime(body.NextPayment, body.TimeOfDay, paymentPlan.Location())
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid date string", lang))
 	}
@@ -2109,19 +4398,192 @@ func (h *Handler) UpdatePaymentPlan(c echo.Context) error {
 		return c.JSON(http.StatusOK, responses.New(false, "Next payment can't be in the past", lang))
 	}
 
+	scheduleRule, endDate, code, resp := validatePaymentPlanSchedule(services.Schedule{
+		Start:        nextPayment.Unix(),
+		Interval:     int(body.Schedule),
+		Unit:         body.ScheduleUnit,
+		Rule:         body.ScheduleRule,
+		WeekdaysOnly: body.WeekdaysOnly,
+		Location:     paymentPlan.Location(),
+	}, body.EndDate, lang)
+	if resp != nil {
+		return c.JSON(code, resp)
+	}
+
+	// the schedule only starts over if it was changed, otherwise e.g. monthly plans on the 31st would continue on the 30th
+	if paymentPlan.NextExecute != nextPayment.Unix() || paymentPlan.Schedule != int(body.Schedule) || paymentPlan.ScheduleUnit != body.ScheduleUnit || paymentPlan.ScheduleRule != scheduleRule || paymentPlan.WeekdaysOnly != body.WeekdaysOnly {
+		paymentPlan.FirstPayment = nextPayment.Unix()
+		paymentPlan.Schedule = int(body.Schedule)
+		paymentPlan.ScheduleUnit = body.ScheduleUnit
+		paymentPlan.ScheduleRule = scheduleRule
+		paymentPlan.WeekdaysOnly = body.WeekdaysOnly
+		paymentPlan.NextExecute = paymentPlan.PaymentSchedule().Occurrence(0)
+	}
+
 	paymentPlan.Amount = int(body.Amount)
 	paymentPlan.Name = body.Name
 	paymentPlan.Description = body.Description
-	paymentPlan.NextExecute = nextPayment.Unix()
-	paymentPlan.Schedule = int(body.Schedule)
-	paymentPlan.ScheduleUnit = body.ScheduleUnit
+	paymentPlan.EndDate = endDate
+	if body.FailurePolicy != "" {
+		paymentPlan.FailurePolicy = body.FailurePolicy
+	}
 
 	err = h.groupStore.UpdatePaymentPlan(paymentPlan)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan))
+	return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan, group.Currency, lang))
+}
+
+// /api/group/:id/paymentPlan/:paymentPlanId/pause (POST)
+func (h *Handler) PausePaymentPlan(c echo.Context) error {
+	return h.changePaymentPlanState(c, "pause")
+}
+
+// /api/group/:id/paymentPlan/:paymentPlanId/resume (POST)
+func (h *Handler) ResumePaymentPlan(c echo.Context) error {
+	return h.changePaymentPlanState(c, "resume")
+}
+
+// /api/group/:id/paymentPlan/:paymentPlanId/skip (POST)
+func (h *Handler) SkipPaymentPlan(c echo.Context) error {
+	return h.changePaymentPlanState(c, "skip")
+}
+
+// Max number of payments which can be skipped at once.
+const maxSkippedPayments = 1000
+
+// action: "pause", "resume" or "skip"
+func (h *Handler) changePaymentPlanState(c echo.Context, action string) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	paymentPlanId := c.Param("paymentPlanId")
+	if paymentPlanId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	paymentPlan, err := h.groupStore.GetPaymentPlanById(group, paymentPlanId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if paymentPlan == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	isSender := user.Id == paymentPlan.SenderId
+	if !isSender {
+		isAdmin, err := h.groupStore.IsAdmin(group, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if !paymentPlan.SenderIsBank || !isAdmin {
+			return c.JSON(http.StatusForbidden, responses.New(false, "User not the sender of the payment plan", lang))
+		}
+	}
+
+	switch action {
+	case "pause":
+		if paymentPlan.Paused {
+			return c.JSON(http.StatusOK, responses.New(false, "The payment plan is already paused", lang))
+		}
+		err = h.groupStore.SetPaymentPlanPaused(paymentPlan, true, false)
+	case "resume":
+		var body bindings.ResumePaymentPlan
+		err = c.Bind(&body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+		}
+		if !paymentPlan.Paused {
+			return c.JSON(http.StatusOK, responses.New(false, "The payment plan is not paused", lang))
+		}
+		err = h.groupStore.SetPaymentPlanPaused(paymentPlan, false, body.CatchUp)
+	case "skip":
+		var body bindings.SkipPaymentPlan
+		err = c.Bind(&body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+		}
+		if body.Count == 0 {
+			body.Count = 1
+		}
+		if body.Count > maxSkippedPayments {
+			return c.JSON(http.StatusOK, responses.New(false, "Count too big", lang))
+		}
+		err = h.groupStore.SkipPaymentPlanPayments(paymentPlan, int(body.Count))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	if paymentPlan.Finished() {
+		return c.JSON(http.StatusOK, responses.New(true, "The payment plan has ended", lang))
+	}
+	return c.JSON(http.StatusOK, responses.NewPaymentPlan(paymentPlan, group.Currency, lang))
+}
+
+// Returns a response and its status code if the schedule rule or the end date are invalid.
+// paymentSchedule.Rule is the unvalidated rule of the request.
+func validatePaymentPlanSchedule(paymentSchedule services.Schedule, endDate string, lang string) (rule string, end int64, code int, resp interface{}) {
+	rule = paymentSchedule.Rule
+	if rule == "" {
+		rule = models.ScheduleRuleDate
+	}
+	if rule != models.ScheduleRuleDate && rule != models.ScheduleRuleLastDay && rule != models.ScheduleRuleWeekday && rule != models.ScheduleRuleLastWeekday {
+		return "", 0, http.StatusBadRequest, responses.New(false, "Invalid schedule rule", lang)
+	}
+	if rule != models.ScheduleRuleDate && paymentSchedule.Unit != models.ScheduleUnitMonth && paymentSchedule.Unit != models.ScheduleUnitYear {
+		return "", 0, http.StatusOK, responses.New(false, "The schedule rule requires a monthly or yearly schedule", lang)
+	}
+	paymentSchedule.Rule = rule
+
+	if endDate != "" {
+		endTime, err := time.ParseInLocation("2006-01-02", endDate, paymentSchedule.Location)
+		if err != nil {
+			return "", 0, http.StatusBadRequest, responses.New(false, "Invalid date string", lang)
+		}
+		// payments on the end date are still made
+		end = endTime.AddDate(0, 0, 1).Unix() - 1
+
+		if end < paymentSchedule.Occurrence(0) {
+			return "", 0, http.StatusOK, responses.New(false, "End date can't be before the first payment", lang)
+		}
+	}
+
+	return rule, end, 0, nil
+}
+
+// Parses a date with format "YYYY-MM-DD" and an optional time of day with format "HH:MM" (default "00:00") in location.
+func parsePaymentTime(date, timeOfDay string, location *time.Location) (time.Time, error) {
+	if timeOfDay == "" {
+		timeOfDay = "00:00"
+	}
+	return time.ParseInLocation("2006-01-02 15:04", date+" "+timeOfDay, location)
 }
 
 // /api/group/:id/total (GET)
@@ -0,0 +1,1641 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juho05/h-bank/config"
+	"github.com/juho05/h-bank/db"
+	"github.com/juho05/h-bank/models"
+	"github.com/juho05/h-bank/router"
+)
+
+func TestHandler_DeleteGroup(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	member := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(member)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, member)
+
+	gs.CreateTransaction(group, true, false, nil, member, "pocket money", "", 500)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Not an admin", user: member, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "Not an admin of the group"},
+		{tName: "Success", user: admin, wantCode: http.StatusOK, wantSuccess: true, wantMessage: "Successfully deleted group"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+
+			err := handler.DeleteGroup(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+
+			g, _ := gs.GetById(group.Id)
+			if tt.wantSuccess {
+				assert.Nil(t, g)
+			} else {
+				assert.NotNil(t, g)
+			}
+		})
+	}
+
+	var count int64
+	database.Model(&models.TransactionLogEntry{}).Where("group_id = ?", group.Id).Count(&count)
+	assert.Equal(t, int64(0), count)
+	database.Model(&models.GroupMembership{}).Where("group_id = ?", group.Id).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestHandler_ArchiveGroup(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	member := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(member)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, member)
+
+	paymentPlan, _ := gs.CreatePaymentPlan(group, true, false, nil, member, "allowance", "", 100, -1, 1, models.ScheduleUnitWeek, models.ScheduleRuleDate, false, time.Now().Add(time.Hour).Unix(), 0, models.PaymentPlanFailureRetry)
+
+	handler := New(us, gs, nil)
+
+	createTransaction := func() *httptest.ResponseRecorder {
+		jsonBody := fmt.Sprintf(`{"title": "pocket money", "amount": 100, "receiverId": "%s", "fromBank": true}`, member.Id)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", admin.Id)
+		c.SetParamNames("id")
+		c.SetParamValues(group.Id)
+		assert.NoError(t, handler.CreateTransaction(c))
+		return rec
+	}
+
+	setArchived := func(user *models.User, archived bool) *httptest.ResponseRecorder {
+		method := http.MethodPost
+		if !archived {
+			method = http.MethodDelete
+		}
+		req := httptest.NewRequest(method, "/", nil)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", user.Id)
+		c.SetParamNames("id")
+		c.SetParamValues(group.Id)
+		if archived {
+			assert.NoError(t, handler.ArchiveGroup(c))
+		} else {
+			assert.NoError(t, handler.UnarchiveGroup(c))
+		}
+		return rec
+	}
+
+	rec := createTransaction()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"success":true`)
+
+	rec = setArchived(member, true)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"Not an admin of the group"`)
+
+	rec = setArchived(admin, true)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"archived":true`)
+
+	rec = createTransaction()
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"The group is archived"`)
+
+	_, err = gs.CreateTransaction(group, true, false, nil, member, "pocket money", "", 100)
+	assert.ErrorIs(t, err, models.ErrGroupArchived)
+
+	database.Model(paymentPlan).Update("next_execute", time.Now().Add(-8*24*time.Hour).Unix())
+	paymentPlans, err := gs.GetPaymentPlansThatNeedToBeExecuted()
+	assert.NoError(t, err)
+	assert.Empty(t, paymentPlans)
+
+	log, err := gs.GetTransactionLog(group, member, "", -1, -1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(log))
+
+	rec = setArchived(admin, false)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"archived":false`)
+
+	paymentPlans, err = gs.GetPaymentPlansThatNeedToBeExecuted()
+	assert.NoError(t, err)
+	assert.Empty(t, paymentPlans)
+
+	paymentPlan, _ = gs.GetPaymentPlanById(group, paymentPlan.Id)
+	assert.Greater(t, paymentPlan.NextExecute, time.Now().Unix())
+
+	rec = createTransaction()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"success":true`)
+}
+
+func TestHandler_ReverseTransaction(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	user1 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+
+	pocketMoney, _ := gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 500)
+	gift, _ := gs.CreateTransaction(group, false, false, user1, user2, "gift", "", 200)
+	spent, _ := gs.CreateTransaction(group, false, true, user2, nil, "ice cream", "", 150)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName         string
+		user          *models.User
+		transactionId string
+		wantCode      int
+		wantSuccess   bool
+		wantMessage   string
+	}{
+		{tName: "Sender", user: user1, transactionId: gift.Id, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "Only admins and the receiver can reverse a transaction"},
+		{tName: "Not enough money", user: admin, transactionId: gift.Id, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Not enough money"},
+		{tName: "Bank as receiver", user: admin, transactionId: spent.Id, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Receiver", user: user2, transactionId: gift.Id, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Already reversed", user: admin, transactionId: gift.Id, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "The transaction was already reversed"},
+		{tName: "Not found", user: admin, transactionId: "abc", wantCode: http.StatusNotFound, wantSuccess: false, wantMessage: "Resource not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id", "transactionId")
+			c.SetParamValues(group.Id, tt.transactionId)
+
+			err := handler.ReverseTransaction(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if tt.wantSuccess {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"reversalOfId":"%s"`, tt.transactionId))
+			} else {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+			}
+		})
+	}
+
+	gift, _ = gs.GetTransactionLogEntryById(group, gift.Id)
+	assert.NotEmpty(t, gift.ReversedById)
+
+	pocketMoney, _ = gs.GetTransactionLogEntryById(group, pocketMoney.Id)
+	assert.Empty(t, pocketMoney.ReversedById)
+
+	balance, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 500, balance)
+	balance, _ = gs.GetUserBalance(group, user2)
+	assert.Equal(t, 0, balance)
+}
+
+func TestHandler_ApprovePaymentRequest(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	user1 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user2, "pocket money", "", 300)
+
+	fromUser, _ := gs.CreatePaymentRequest(group, user1, false, user2, "cinema", "", 100)
+	fromBank, _ := gs.CreatePaymentRequest(group, user1, true, nil, "pocket money", "", 50)
+	tooExpensive, _ := gs.CreatePaymentRequest(group, user1, false, user2, "bike", "", 1000)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		requestId   string
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Requester", user: user1, requestId: fromUser.Id, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "User is not the payer of the payment request"},
+		{tName: "Not enough money", user: user2, requestId: tooExpensive.Id, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Not enough money"},
+		{tName: "Payer", user: user2, requestId: fromUser.Id, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Already approved", user: user2, requestId: fromUser.Id, wantCode: http.StatusNotFound, wantSuccess: false, wantMessage: "Resource not found"},
+		{tName: "Bank as payer, not an admin", user: user2, requestId: fromBank.Id, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "User is not the payer of the payment request"},
+		{tName: "Bank as payer", user: admin, requestId: fromBank.Id, wantCode: http.StatusOK, wantSuccess: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.requestId)
+
+			err := handler.ApprovePaymentRequest(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if !tt.wantSuccess {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+			}
+		})
+	}
+
+	balance, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 150, balance)
+	balance, _ = gs.GetUserBalance(group, user2)
+	assert.Equal(t, 200, balance)
+
+	count, _ := gs.IncomingPaymentRequestCount(user2)
+	assert.Equal(t, int64(1), count)
+	count, _ = gs.OutgoingPaymentRequestCount(user1)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestHandler_DeclinePaymentRequest(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	user1 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+
+	user3 := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user3)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+	gs.AddMember(group, user3)
+
+	declined, _ := gs.CreatePaymentRequest(group, user1, false, user2, "cinema", "", 100)
+	withdrawn, _ := gs.CreatePaymentRequest(group, user1, false, user2, "bike", "", 1000)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		requestId   string
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Other member", user: user3, requestId: declined.Id, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "User is not the payer of the payment request"},
+		{tName: "Payer", user: user2, requestId: declined.Id, wantCode: http.StatusOK, wantSuccess: true, wantMessage: "Successfully declined payment request"},
+		{tName: "Requester", user: user1, requestId: withdrawn.Id, wantCode: http.StatusOK, wantSuccess: true, wantMessage: "Successfully withdrew payment request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.requestId)
+
+			err := handler.DeclinePaymentRequest(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+
+			request, _ := gs.GetPaymentRequestById(tt.requestId)
+			if tt.wantSuccess {
+				assert.Nil(t, request)
+			} else {
+				assert.NotNil(t, request)
+			}
+		})
+	}
+
+	count, _ := gs.TransactionLogEntryCount(group, user2)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestHandler_PendingTransactions(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	user1 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 300)
+	gs.SetApprovalPolicy(group, 100, true)
+
+	handler := New(us, gs, nil)
+
+	createTransaction := func(receiverId string, amount int) *httptest.ResponseRecorder {
+		jsonBody := fmt.Sprintf(`{"title": "transfer", "amount": %d, "receiverId": "%s"}`, amount, receiverId)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", user1.Id)
+		c.SetParamNames("id")
+		c.SetParamValues(group.Id)
+		assert.NoError(t, handler.CreateTransaction(c))
+		return rec
+	}
+
+	handlePending := func(user *models.User, pendingId string, approve bool) *httptest.ResponseRecorder {
+		method := http.MethodPost
+		if !approve {
+			method = http.MethodDelete
+		}
+		req := httptest.NewRequest(method, "/", nil)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", user.Id)
+		c.SetParamNames("id", "pendingId")
+		c.SetParamValues(group.Id, pendingId)
+		if approve {
+			assert.NoError(t, handler.ApprovePendingTransaction(c))
+		} else {
+			assert.NoError(t, handler.RejectPendingTransaction(c))
+		}
+		return rec
+	}
+
+	rec := createTransaction(user2.Id, 50)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = createTransaction(user2.Id, 200)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"pending":true`)
+
+	rec = createTransaction(user2.Id, 300)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"Not enough money"`)
+
+	rec = createTransaction("bank", 10)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	// reserved money can't be spent
+	rec = createTransaction(user2.Id, 60)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"Not enough money"`)
+
+	reserved, _ := gs.GetReservedAmount(group, user1)
+	assert.Equal(t, 210, reserved)
+
+	pendingTransactions, _ := gs.GetPendingTransactions(group, nil, -1, -1, true)
+	if !assert.Equal(t, 2, len(pendingTransactions)) {
+		return
+	}
+	var toUser, toBank models.PendingTransaction
+	for _, p := range pendingTransactions {
+		if p.ReceiverIsBank {
+			toBank = p
+		} else {
+			toUser = p
+		}
+	}
+
+	rec = handlePending(user2, toUser.Id, true)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = handlePending(admin, toUser.Id, true)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"success":true`)
+
+	rec = handlePending(admin, toUser.Id, true)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = handlePending(admin, toBank.Id, false)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"Successfully rejected transaction"`)
+
+	balance, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 50, balance)
+	balance, _ = gs.GetUserBalance(group, user2)
+	assert.Equal(t, 250, balance)
+	reserved, _ = gs.GetReservedAmount(group, user1)
+	assert.Equal(t, 0, reserved)
+}
+
+func TestHandler_ApprovePaymentRequest_NeedsApproval(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	user1 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user2, "pocket money", "", 300)
+	gs.SetApprovalPolicy(group, 100, false)
+
+	small, _ := gs.CreatePaymentRequest(group, user1, false, user2, "cinema", "", 50)
+	big, _ := gs.CreatePaymentRequest(group, user1, false, user2, "bike", "", 200)
+
+	handler := New(us, gs, nil)
+
+	approve := func(requestId string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", user2.Id)
+		c.SetParamNames("id")
+		c.SetParamValues(requestId)
+		assert.NoError(t, handler.ApprovePaymentRequest(c))
+		return rec
+	}
+
+	rec := approve(small.Id)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"success":true`)
+
+	rec = approve(big.Id)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"pending":true`)
+
+	rec = approve(big.Id)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	balance, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 50, balance)
+	balance, _ = gs.GetUserBalance(group, user2)
+	assert.Equal(t, 250, balance)
+	reserved, _ := gs.GetReservedAmount(group, user2)
+	assert.Equal(t, 200, reserved)
+
+	pendingTransactions, _ := gs.GetPendingTransactions(group, nil, -1, -1, true)
+	if !assert.Len(t, pendingTransactions, 1) {
+		return
+	}
+	assert.Equal(t, user2.Id, pendingTransactions[0].SenderId)
+	assert.Equal(t, user1.Id, pendingTransactions[0].ReceiverId)
+	assert.Equal(t, 200, pendingTransactions[0].Amount)
+
+	count, _ := gs.IncomingPaymentRequestCount(user2)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestHandler_CreatePaymentPlan_NeedsApproval(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	user1 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, admin)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+
+	gs.SetApprovalPolicy(group, 100, true)
+
+	handler := New(us, gs, nil)
+
+	year := time.Now().Year() + 1
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		receiverId  string
+		amount      int
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Below threshold", user: user1, receiverId: user2.Id, amount: 50, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Above threshold", user: user1, receiverId: user2.Id, amount: 100, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "Payment plans that need approval can only be set up by an admin"},
+		{tName: "To bank", user: user1, receiverId: "bank", amount: 10, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "Payment plans that need approval can only be set up by an admin"},
+		{tName: "Admin", user: admin, receiverId: user2.Id, amount: 100, wantCode: http.StatusOK, wantSuccess: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			body := fmt.Sprintf(`{"name":"rent","amount":%d,"receiverId":"%s","schedule":1,"scheduleUnit":"month","firstPayment":"%d-03-01"}`, tt.amount, tt.receiverId, year)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+
+			err := handler.CreatePaymentPlan(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if !tt.wantSuccess {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+			}
+		})
+	}
+
+	var paymentPlans []models.PaymentPlan
+	database.Find(&paymentPlans, "group_id = ?", group.Id)
+	assert.Len(t, paymentPlans, 2)
+}
+
+func TestHandler_SpendingLimits(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	user1 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(user2)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 1000)
+
+	handler := New(us, gs, nil)
+
+	setLimits := func(authUser, user *models.User, jsonBody string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(jsonBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", authUser.Id)
+		c.SetParamNames("id", "userId")
+		c.SetParamValues(group.Id, user.Id)
+		assert.NoError(t, handler.SetSpendingLimits(c))
+		return rec
+	}
+
+	rec := setLimits(user1, user1, `{"maxPerTransaction": 0}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = setLimits(admin, user1, `{"maxPerTransaction": 200, "maxPerDay": 300}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"maxPerDay":300`)
+
+	rec = setLimits(admin, user2, `{"overdraft": 50}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	tests := []struct {
+		tName       string
+		sender      *models.User
+		receiver    *models.User
+		amount      int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Limit per transaction", sender: user1, receiver: user2, amount: 250, wantSuccess: false, wantMessage: "The transfer exceeds the spending limit per transaction (max €2.00)"},
+		{tName: "Below limits", sender: user1, receiver: user2, amount: 200, wantSuccess: true},
+		{tName: "Daily limit", sender: user1, receiver: user2, amount: 150, wantSuccess: false, wantMessage: "The transfer exceeds the daily spending limit (max €3.00)"},
+		{tName: "Up to daily limit", sender: user1, receiver: user2, amount: 100, wantSuccess: true},
+		{tName: "Overdraft", sender: user2, receiver: user1, amount: 350, wantSuccess: true},
+		{tName: "Overdraft exceeded", sender: user2, receiver: user1, amount: 1, wantSuccess: false, wantMessage: "Not enough money"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			jsonBody := fmt.Sprintf(`{"title": "transfer", "amount": %d, "receiverId": "%s"}`, tt.amount, tt.receiver.Id)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.sender.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+
+			err := handler.CreateTransaction(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if !tt.wantSuccess {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+			}
+		})
+	}
+
+	balance, _ := gs.GetUserBalance(group, user2)
+	assert.Equal(t, -50, balance)
+
+	limits, _ := gs.GetSpendingLimits(group, user1)
+	assert.Equal(t, models.SpendingLimits{MaxPerTransaction: 200, MaxPerDay: 300}, *limits)
+}
+
+func TestHandler_SavingsGoals(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	user1 := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user1)
+
+	user2 := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user2)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, user1)
+	gs.AddMember(group, user2)
+
+	gs.CreateTransaction(group, true, false, nil, user1, "pocket money", "", 300)
+
+	handler := New(us, gs, nil)
+
+	newContext := func(method, jsonBody string, user *models.User, goalId string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(jsonBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", user.Id)
+		if goalId != "" {
+			c.SetParamNames("id", "goalId")
+			c.SetParamValues(group.Id, goalId)
+		} else {
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+		}
+		return c, rec
+	}
+
+	c, rec := newContext(http.MethodPost, `{"name": "bike", "target": 0}`, user1, "")
+	assert.NoError(t, handler.CreateSavingsGoal(c))
+	assert.Contains(t, rec.Body.String(), `"success":false`)
+
+	c, rec = newContext(http.MethodPost, `{"name": "bike", "target": 400}`, user1, "")
+	assert.NoError(t, handler.CreateSavingsGoal(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	goals, _ := gs.GetSavingsGoals(group, user1, -1, -1, false)
+	if !assert.Len(t, goals, 1) {
+		return
+	}
+	goal := goals[0]
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		deposit     bool
+		amount      int
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Deposit", user: user1, deposit: true, amount: 200, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Deposit more than balance", user: user1, deposit: true, amount: 101, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Not enough money"},
+		{tName: "Withdraw", user: user1, deposit: false, amount: 50, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Withdraw more than goal balance", user: user1, deposit: false, amount: 151, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Not enough money"},
+		{tName: "Goal of other user", user: user2, deposit: true, amount: 10, wantCode: http.StatusNotFound, wantSuccess: false, wantMessage: "Savings goal not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			c, rec := newContext(http.MethodPost, fmt.Sprintf(`{"amount": %d}`, tt.amount), tt.user, goal.Id)
+
+			var err error
+			if tt.deposit {
+				err = handler.DepositToSavingsGoal(c)
+			} else {
+				err = handler.WithdrawFromSavingsGoal(c)
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if !tt.wantSuccess {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+			}
+		})
+	}
+
+	balance, _ := gs.GetUserBalance(group, user1)
+	assert.Equal(t, 150, balance)
+
+	c, rec = newContext(http.MethodGet, "", user1, goal.Id)
+	assert.NoError(t, handler.GetSavingsGoalById(c))
+	assert.Contains(t, rec.Body.String(), `"balance":150`)
+	assert.Contains(t, rec.Body.String(), `"remaining":250`)
+	assert.Contains(t, rec.Body.String(), `"progress":37`)
+
+	total, _ := gs.GetTotalMoney(group)
+	assert.Equal(t, 300, total, "savings should count towards the total money of the group")
+
+	drifts, _ := gs.GetBalanceDrifts(group)
+	assert.Empty(t, drifts)
+
+	c, rec = newContext(http.MethodDelete, "", user1, goal.Id)
+	assert.NoError(t, handler.DeleteSavingsGoal(c))
+	assert.Contains(t, rec.Body.String(), `"success":true`)
+
+	balance, _ = gs.GetUserBalance(group, user1)
+	assert.Equal(t, 300, balance, "the remaining savings should be moved back to the main balance")
+
+	log, _ := gs.GetTransactionLog(group, user1, "", -1, -1, true)
+	if assert.Len(t, log, 4) {
+		assert.Equal(t, goal.Id, log[1].SavingsGoalId)
+		assert.Equal(t, -200, log[1].BalanceDifferenceSender)
+		assert.Equal(t, 150, log[3].BalanceDifferenceReceiver)
+	}
+}
+
+func TestHandler_CreateCashTransaction(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	member := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(member)
+
+	admin := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(admin)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, member)
+	gs.AddMember(group, admin)
+	gs.AddAdmin(group, admin)
+
+	gs.CreateTransaction(group, true, false, nil, member, "pocket money", "", 300)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		body        string
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+		wantBalance int
+		wantCash    int
+	}{
+		{tName: "Deposit by member", user: member, body: `{"type": "deposit", "title": "Cash", "denominations": {"eur1": 1}}`, wantCode: http.StatusForbidden, wantSuccess: false, wantMessage: "Not an admin of the group", wantBalance: 300, wantCash: 0},
+		{tName: "Invalid type", user: member, body: `{"type": "gift", "title": "Cash", "denominations": {"eur1": 1}}`, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Invalid cash transaction type", wantBalance: 300, wantCash: 0},
+		{tName: "Not enough money", user: member, body: `{"type": "payout", "title": "Cash", "denominations": {"eur5": 1}}`, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Not enough money", wantBalance: 300, wantCash: 0},
+		{tName: "Payout", user: member, body: `{"type": "payout", "title": "Cash", "denominations": {"eur2": 1}}`, wantCode: http.StatusCreated, wantSuccess: true, wantBalance: 100, wantCash: 200},
+		{tName: "Not enough cash", user: admin, body: fmt.Sprintf(`{"type": "deposit", "title": "Cash", "userId": "%s", "denominations": {"eur1": 1}}`, member.Id), wantCode: http.StatusOK, wantSuccess: false, wantMessage: "The wallet does not contain enough cash", wantBalance: 100, wantCash: 200},
+		{tName: "Deposit", user: admin, body: fmt.Sprintf(`{"type": "deposit", "title": "Cash", "userId": "%s", "denominations": {"eur2": 1}}`, member.Id), wantCode: http.StatusCreated, wantSuccess: true, wantBalance: 300, wantCash: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+
+			err := handler.CreateCashTransaction(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if tt.wantMessage != "" {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+			}
+
+			balance, _ := gs.GetUserBalance(group, member)
+			assert.Equal(t, tt.wantBalance, balance)
+
+			cash := 0
+			entry, _ := us.GetLastCashLogEntry(member, models.DefaultCashWalletId)
+			if entry != nil {
+				cash = entry.TotalAmount
+			}
+			assert.Equal(t, tt.wantCash, cash)
+		})
+	}
+
+	log, _ := gs.GetTransactionLog(group, member, "", 0, 10, true)
+	assert.Len(t, log, 3)
+	var payout models.TransactionLogEntry
+	for _, entry := range log {
+		if entry.ReceiverIsBank {
+			payout = entry
+		}
+	}
+	assert.Equal(t, 200, payout.Amount)
+
+	cashEntry, _ := us.GetCashLogEntryById(member, payout.CashLogEntryId)
+	if assert.NotNil(t, cashEntry) {
+		assert.Equal(t, payout.Id, cashEntry.TransactionId)
+		assert.Equal(t, group.Id, cashEntry.TransactionGroupId)
+		assert.Equal(t, 200, cashEntry.ChangeDifference)
+	}
+
+	_, err = gs.ReverseTransaction(group, &payout)
+	assert.ErrorIs(t, err, models.ErrCashTransaction)
+}
+
+func TestHandler_ExportTransactionLog(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	member := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(member)
+
+	admin := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(admin)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, member)
+	gs.AddMember(group, admin)
+	gs.AddAdmin(group, admin)
+
+	gs.CreateTransaction(group, true, false, nil, member, "Pocket money", "", 300)
+	gs.CreateTransaction(group, false, false, member, admin, "Ice cream", "with sprinkles, please", 100)
+	gs.CreateTransaction(group, false, false, admin, member, "=1+1", "@SUM(A1:A2)", 50)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName    string
+		user     *models.User
+		query    string
+		wantCode int
+		want     []string
+		notWant  []string
+	}{
+		{tName: "Member CSV", user: member, query: "format=csv", wantCode: http.StatusOK, want: []string{"Date,Title,Description,Sender,Receiver,Amount,Balance,Currency\n", `,Pocket money,,Bank,bob,3.00,3.00,EUR`, `,Ice cream,"with sprinkles, please",bob,peter,-1.00,2.00,EUR`, `,'=1+1,'@SUM(A1:A2),peter,bob,0.50,2.50,EUR`}},
+		{tName: "Receiver JSON", user: admin, query: "format=json", wantCode: http.StatusOK, want: []string{`"title":"Ice cream"`, `"amount":100,"newBalance":100`, `"title":"=1+1"`}, notWant: []string{"Pocket money"}},
+		{tName: "Bank JSON", user: admin, query: "format=json&bank=true", wantCode: http.StatusOK, want: []string{`"title":"Pocket money"`, `"amount":-300,"currency":"EUR"`}, notWant: []string{"Ice cream"}},
+		{tName: "Date range", user: member, query: fmt.Sprintf("format=json&from=%d", time.Now().Add(time.Hour).Unix()), wantCode: http.StatusOK, want: []string{"[]"}},
+		{tName: "Bank as member", user: member, query: "bank=true", wantCode: http.StatusForbidden, want: []string{`"message":"Not an admin of the group"`}},
+		{tName: "Invalid format", user: member, query: "format=xml", wantCode: http.StatusBadRequest, want: []string{`"message":"Unsupported export format"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+
+			err := handler.ExportTransactionLog(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			for _, want := range tt.want {
+				assert.Contains(t, rec.Body.String(), want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, rec.Body.String(), notWant)
+			}
+		})
+	}
+}
+
+func TestEscapeCSVFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Ice cream", want: "Ice cream"},
+		{value: "", want: ""},
+		{value: "=1+1", want: "'=1+1"},
+		{value: "+49 123", want: "'+49 123"},
+		{value: "-5", want: "'-5"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "\tcmd", want: "'\tcmd"},
+		{value: "\rcmd", want: "'\rcmd"},
+		{value: "a=b", want: "a=b"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, escapeCSVFormula(tt.value))
+	}
+}
+
+func TestHandler_GetPaymentPlanExecutions(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(admin)
+
+	member := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(member)
+
+	other := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(other)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, member)
+	gs.AddMember(group, other)
+
+	firstPayment := time.Now().AddDate(0, 0, -1).Unix()
+	paymentPlan, _ := gs.CreatePaymentPlan(group, false, true, member, nil, "savings", "", 100, -1, 1, models.ScheduleUnitDay, models.ScheduleRuleDate, false, firstPayment, 0, models.PaymentPlanFailureSkip)
+	gs.RecordPaymentPlanFailure(paymentPlan, models.PaymentPlanExecutionInsufficientFunds, "not enough money")
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		wantCode    int
+		wantSuccess bool
+	}{
+		{tName: "Sender", user: member, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Admin", user: admin, wantCode: http.StatusOK, wantSuccess: true},
+		{tName: "Other member", user: other, wantCode: http.StatusForbidden, wantSuccess: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id", "paymentPlanId")
+			c.SetParamValues(group.Id, paymentPlan.Id)
+
+			err := handler.GetPaymentPlanExecutions(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if tt.wantSuccess {
+				assert.Contains(t, rec.Body.String(), `"count":1`)
+				assert.Contains(t, rec.Body.String(), `"status":"insufficientFunds"`)
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"scheduled":%d`, firstPayment))
+			}
+		})
+	}
+}
+
+func TestHandler_GetPaymentPlanNextPayments(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	user := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(user)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, user)
+
+	handler := New(us, gs, nil)
+
+	date := func(year int, month time.Month, day int) int64 {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()
+	}
+
+	tests := []struct {
+		tName    string
+		query    string
+		wantCode int
+		want     []int64
+	}{
+		{tName: "Monthly on the 31st", query: fmt.Sprintf("firstPayment=%d&schedule=1&scheduleUnit=month&count=3", date(2023, 1, 31)), wantCode: http.StatusOK, want: []int64{date(2023, 1, 31), date(2023, 2, 28), date(2023, 3, 31)}},
+		{tName: "Last day of the month", query: fmt.Sprintf("firstPayment=%d&schedule=1&scheduleUnit=month&scheduleRule=lastDay&count=3", date(2023, 4, 1)), wantCode: http.StatusOK, want: []int64{date(2023, 4, 30), date(2023, 5, 31), date(2023, 6, 30)}},
+		{tName: "2nd friday", query: fmt.Sprintf("firstPayment=%d&schedule=1&scheduleUnit=month&scheduleRule=weekday&count=2", date(2023, 1, 13)), wantCode: http.StatusOK, want: []int64{date(2023, 1, 13), date(2023, 2, 10)}},
+		{tName: "Weekdays only", query: fmt.Sprintf("firstPayment=%d&schedule=1&scheduleUnit=day&weekdaysOnly=true&count=3", date(2023, 3, 3)), wantCode: http.StatusOK, want: []int64{date(2023, 3, 3), date(2023, 3, 6), date(2023, 3, 7)}},
+		{tName: "End date", query: fmt.Sprintf("firstPayment=%d&schedule=1&scheduleUnit=week&endDate=%d&count=10", date(2023, 3, 1), date(2023, 3, 8)), wantCode: http.StatusOK, want: []int64{date(2023, 3, 1), date(2023, 3, 8)}},
+		{tName: "Invalid rule", query: fmt.Sprintf("firstPayment=%d&schedule=1&scheduleUnit=month&scheduleRule=everyOtherDay", date(2023, 3, 1)), wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+
+			err := handler.GetPaymentPlanNextPayments(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.want != nil {
+				times := make([]string, len(tt.want))
+				for i, w := range tt.want {
+					times[i] = strconv.FormatInt(w, 10)
+				}
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"executionTimes":[%s]`, strings.Join(times, ",")))
+			}
+		})
+	}
+}
+
+func TestHandler_CreatePaymentPlan_TimeZone(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(admin)
+
+	child := &models.User{
+		Name:  "ben",
+		Email: "ben@gmail.com",
+	}
+	us.Create(child)
+
+	group := &models.Group{
+		Name:     "family",
+		TimeZone: "Europe/Berlin",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, child)
+
+	handler := New(us, gs, nil)
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	year := time.Now().Year() + 1
+
+	body := fmt.Sprintf(`{"name":"pocket money","amount":100,"receiverId":"%s","fromBank":true,"schedule":1,"scheduleUnit":"month","firstPayment":"%d-03-01","timeOfDay":"08:00"}`, child.Id, year)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", admin.Id)
+	c.SetParamNames("id")
+	c.SetParamValues(group.Id)
+
+	err = handler.CreatePaymentPlan(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"timeZone":"Europe/Berlin"`)
+	assert.Contains(t, rec.Body.String(), `"timeOfDay":"08:00"`)
+	assert.Contains(t, rec.Body.String(), `"currency":"EUR","formattedAmount":"€1.00"`)
+
+	var paymentPlans []models.PaymentPlan
+	database.Find(&paymentPlans, "group_id = ?", group.Id)
+	if !assert.Len(t, paymentPlans, 1) {
+		return
+	}
+	assert.Equal(t, time.Date(year, 3, 1, 8, 0, 0, 0, berlin).Unix(), paymentPlans[0].NextExecute)
+
+	// the payments stay at 08:00 local time after the change to summer time
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/?id=%s&count=2", paymentPlans[0].Id), nil)
+	rec = httptest.NewRecorder()
+	c = r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", admin.Id)
+	c.SetParamNames("id")
+	c.SetParamValues(group.Id)
+
+	err = handler.GetPaymentPlanNextPayments(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"executionTimes":[%d,%d]`, time.Date(year, 3, 1, 8, 0, 0, 0, berlin).Unix(), time.Date(year, 4, 1, 8, 0, 0, 0, berlin).Unix()))
+}
+
+func TestHandler_ChangePaymentPlanState(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(admin)
+
+	child := &models.User{
+		Name:  "ben",
+		Email: "ben@gmail.com",
+	}
+	us.Create(child)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, child)
+
+	firstPayment := time.Now().AddDate(0, 0, 1).Unix()
+	paymentPlan, _ := gs.CreatePaymentPlan(group, true, false, nil, child, "pocket money", "", 100, -1, 1, models.ScheduleUnitWeek, models.ScheduleRuleDate, false, firstPayment, 0, models.PaymentPlanFailureRetry)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		action      func(c echo.Context) error
+		body        string
+		wantCode    int
+		wantSuccess bool
+		wantPaused  bool
+		wantNext    int64
+	}{
+		{tName: "Receiver", user: child, action: handler.PausePaymentPlan, wantCode: http.StatusForbidden, wantSuccess: false, wantPaused: false, wantNext: firstPayment},
+		{tName: "Pause", user: admin, action: handler.PausePaymentPlan, wantCode: http.StatusOK, wantSuccess: true, wantPaused: true, wantNext: firstPayment},
+		{tName: "Pause twice", user: admin, action: handler.PausePaymentPlan, wantCode: http.StatusOK, wantSuccess: false, wantPaused: true, wantNext: firstPayment},
+		{tName: "Skip", user: admin, action: handler.SkipPaymentPlan, body: `{"count":2}`, wantCode: http.StatusOK, wantSuccess: true, wantPaused: true, wantNext: time.Unix(firstPayment, 0).AddDate(0, 0, 14).Unix()},
+		{tName: "Resume", user: admin, action: handler.ResumePaymentPlan, body: `{"catchUp":true}`, wantCode: http.StatusOK, wantSuccess: true, wantPaused: false, wantNext: time.Unix(firstPayment, 0).AddDate(0, 0, 14).Unix()},
+		{tName: "Resume twice", user: admin, action: handler.ResumePaymentPlan, body: `{}`, wantCode: http.StatusOK, wantSuccess: false, wantPaused: false, wantNext: time.Unix(firstPayment, 0).AddDate(0, 0, 14).Unix()},
+		{tName: "Skip too many", user: admin, action: handler.SkipPaymentPlan, body: `{"count":1001}`, wantCode: http.StatusOK, wantSuccess: false, wantPaused: false, wantNext: time.Unix(firstPayment, 0).AddDate(0, 0, 14).Unix()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id", "paymentPlanId")
+			c.SetParamValues(group.Id, paymentPlan.Id)
+
+			err := tt.action(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+
+			plan, _ := gs.GetPaymentPlanById(group, paymentPlan.Id)
+			if assert.NotNil(t, plan) {
+				assert.Equal(t, tt.wantPaused, plan.Paused)
+				assert.Equal(t, tt.wantNext, plan.NextExecute)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/juho05/h-bank/config"
+	"github.com/juho05/h-bank/models"
+	"github.com/juho05/h-bank/responses"
+	"github.com/juho05/h-bank/services"
+)
+
+var errImportFileTooBig = errors.New("import file too big")
+
+// Imports backdated transactions from the CSV file in the 'file' form field.
+// Columns: Date, Title, Description (optional), Sender, Receiver, Amount, Balance (ignored), Currency (optional).
+// Sender and receiver are emails or names of members or 'bank'. The direction is given by sender and receiver,
+// so the signed amounts of exported logs are imported as their absolute value.
+// Files exported by ExportTransactionLog can be imported again.
+// /api/group/:id/transaction/import?dryRun=bool (POST)
+func (h *Handler) ImportTransactions(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	groupId := c.Param("id")
+	if groupId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+	group, err := h.groupStore.GetById(groupId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if group == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Group not found", lang))
+	}
+
+	if group.Archived {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+
+	isAdmin, err := h.groupStore.IsAdmin(group, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if !isAdmin {
+		return c.JSON(http.StatusForbidden, responses.New(false, "Not an admin of the group", lang))
+	}
+
+	dryRun := services.StrToBool(c.QueryParam("dryRun"))
+
+	records, err := readImportFile(c)
+	if errors.Is(err, errImportFileTooBig) {
+		return c.JSON(http.StatusBadRequest, responses.New(false, fmt.Sprintf(services.Tr("File too big (max %s)", lang), services.SizeInBytesToStr(config.Data.MaxImportFileSize)), ""))
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid or missing CSV file", lang))
+	}
+
+	columns, unknown := importColumns(records[0], []string{"Date", "Title", "Description", "Sender", "Receiver", "Amount", "Balance", "Currency"}, lang)
+	if len(unknown) > 0 {
+		return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Unknown column '%s'", lang), unknown[0]), ""))
+	}
+	for _, required := range []string{"Date", "Title", "Sender", "Receiver", "Amount"} {
+		if _, ok := columns[required]; !ok {
+			return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Missing column '%s'", lang), services.Tr(required, lang)), ""))
+		}
+	}
+
+	// exports contain the names of the members instead of their emails
+	memberships, err := h.groupStore.GetMemberships(nil, "", group, -1, -1, false)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	memberIdsByName := make(map[string][]string, len(memberships))
+	for _, m := range memberships {
+		if !m.IsMember {
+			continue
+		}
+		name := strings.ToLower(m.UserName)
+		memberIdsByName[name] = append(memberIdsByName[name], m.UserId)
+	}
+
+	// resolved members by lowercase email or name, nil if the value doesn't belong to exactly one member
+	members := make(map[string]*models.User)
+	resolveMember := func(value string) (*models.User, error) {
+		value = strings.ToLower(value)
+		if member, ok := members[value]; ok {
+			return member, nil
+		}
+		var member *models.User
+		if strings.Contains(value, "@") {
+			member, err = h.userStore.GetByEmail(value)
+			if err != nil {
+				return nil, err
+			}
+			if member != nil {
+				isMember, err := h.groupStore.IsMember(group, member)
+				if err != nil {
+					return nil, err
+				}
+				if !isMember {
+					member = nil
+				}
+			}
+		} else if ids := memberIdsByName[value]; len(ids) == 1 {
+			member, err = h.userStore.GetById(ids[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+		members[value] = member
+		return member, nil
+	}
+
+	rows := records[1:]
+	transactions := make([]models.TransactionImport, len(rows))
+	var rowErrors []responses.ImportRowError
+	addRowError := func(i int, message string) {
+		rowErrors = append(rowErrors, responses.ImportRowError{Row: i + 2, Message: message})
+	}
+
+rows:
+	for i, row := range rows {
+		t := &transactions[i]
+
+		t.Time, err = services.ParseDate(importField(row, columns, "Date"))
+		if err != nil {
+			addRowError(i, fmt.Sprintf(services.Tr("Invalid date '%s'", lang), importField(row, columns, "Date")))
+			continue
+		}
+
+		t.Title = importField(row, columns, "Title")
+		t.Description = importField(row, columns, "Description")
+		if message := validateTitleAndDescription(t.Title, t.Description); message != "" {
+			addRowError(i, services.Tr(message, lang))
+			continue
+		}
+
+		t.Amount, err = services.ParseDecimal(importField(row, columns, "Amount"))
+		if t.Amount < 0 {
+			t.Amount = -t.Amount
+		}
+		if err != nil || t.Amount == 0 {
+			addRowError(i, fmt.Sprintf(services.Tr("Invalid amount '%s'", lang), importField(row, columns, "Amount")))
+			continue
+		}
+
+		if currency := importField(row, columns, "Currency"); currency != "" && !strings.EqualFold(currency, group.Currency) {
+			addRowError(i, fmt.Sprintf(services.Tr("The currency '%s' doesn't match the currency of the group", lang), currency))
+			continue
+		}
+
+		for _, side := range []string{"Sender", "Receiver"} {
+			value := importField(row, columns, side)
+			isBank := strings.EqualFold(value, "bank") || strings.EqualFold(value, services.Tr("Bank", lang))
+			var member *models.User
+			if !isBank {
+				member, err = resolveMember(value)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+				}
+				if member == nil && len(memberIdsByName[strings.ToLower(value)]) > 1 {
+					addRowError(i, fmt.Sprintf(services.Tr("Several members are called '%s', use their email instead", lang), value))
+					continue rows
+				}
+				if member == nil {
+					addRowError(i, fmt.Sprintf(services.Tr("'%s' is not the email or name of a member of the group", lang), value))
+					continue rows
+				}
+			}
+			if side == "Sender" {
+				t.SenderIsBank, t.Sender = isBank, member
+			} else {
+				t.ReceiverIsBank, t.Receiver = isBank, member
+			}
+		}
+
+		if t.SenderIsBank && t.ReceiverIsBank {
+			addRowError(i, services.Tr("Cannot send money from bank to bank", lang))
+			continue
+		}
+		if !t.SenderIsBank && !t.ReceiverIsBank && t.Sender.Id == t.Receiver.Id {
+			addRowError(i, services.Tr("Sender is the receiver", lang))
+			continue
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return c.JSON(http.StatusOK, responses.NewImportResult(dryRun, len(rows), rowErrors, lang))
+	}
+
+	failed, err := h.groupStore.ImportTransactions(group, transactions, dryRun)
+	if errors.Is(err, models.ErrGroupArchived) {
+		return c.JSON(http.StatusForbidden, responses.New(false, "The group is archived", lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	for i := range rows {
+		err, ok := failed[i]
+		if !ok {
+			continue
+		}
+		if errors.Is(err, models.ErrNotEnoughMoney) {
+			addRowError(i, services.Tr("Not enough money", lang))
+		} else {
+			addRowError(i, services.Tr("The user is not a member of the group", lang))
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.NewImportResult(dryRun, len(rows), rowErrors, lang))
+}
+
+// Imports cash log history from the CSV file in the 'file' form field.
+// Columns: Date, Title, Description (optional) and one column per denomination key of the currency of the wallet.
+// /api/user/cash/import?wallet=string&dryRun=bool (POST)
+func (h *Handler) ImportCashLog(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	walletId, currency, err := h.getCashWallet(user, c.QueryParam("wallet"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if currency == "" {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+
+	dryRun := services.StrToBool(c.QueryParam("dryRun"))
+
+	records, err := readImportFile(c)
+	if errors.Is(err, errImportFileTooBig) {
+		return c.JSON(http.StatusBadRequest, responses.New(false, fmt.Sprintf(services.Tr("File too big (max %s)", lang), services.SizeInBytesToStr(config.Data.MaxImportFileSize)), ""))
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Invalid or missing CSV file", lang))
+	}
+
+	known := []string{"Date", "Title", "Description"}
+	denominations := services.Currencies[currency].Denominations
+	for _, d := range denominations {
+		known = append(known, d.Key)
+	}
+	columns, unknown := importColumns(records[0], known, lang)
+	if len(unknown) > 0 {
+		return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Unknown column '%s'", lang), unknown[0]), ""))
+	}
+	for _, required := range []string{"Date", "Title"} {
+		if _, ok := columns[required]; !ok {
+			return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Missing column '%s'", lang), services.Tr(required, lang)), ""))
+		}
+	}
+
+	rows := records[1:]
+	entries := make([]models.CashLogEntry, len(rows))
+	var rowErrors []responses.ImportRowError
+	addRowError := func(i int, message string) {
+		rowErrors = append(rowErrors, responses.ImportRowError{Row: i + 2, Message: message})
+	}
+
+rows:
+	for i, row := range rows {
+		entry := &entries[i]
+		entry.Currency = currency
+
+		entry.Created, err = services.ParseDate(importField(row, columns, "Date"))
+		if err != nil {
+			addRowError(i, fmt.Sprintf(services.Tr("Invalid date '%s'", lang), importField(row, columns, "Date")))
+			continue
+		}
+
+		entry.ChangeTitle = importField(row, columns, "Title")
+		entry.ChangeDescription = importField(row, columns, "Description")
+		if message := validateTitleAndDescription(entry.ChangeTitle, entry.ChangeDescription); message != "" {
+			addRowError(i, services.Tr(message, lang))
+			continue
+		}
+
+		for _, d := range denominations {
+			value := importField(row, columns, d.Key)
+			if value == "" {
+				continue
+			}
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 0 {
+				addRowError(i, fmt.Sprintf(services.Tr("Invalid count '%s'", lang), value))
+				continue rows
+			}
+			if count > 0 {
+				entry.Denominations = append(entry.Denominations, models.CashDenominationCount{Key: d.Key, Count: count})
+			}
+		}
+	}
+
+	if len(rowErrors) == 0 && !dryRun {
+		err = h.userStore.ImportCashLog(user, walletId, entries)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.NewImportResult(dryRun, len(rows), rowErrors, lang))
+}
+
+// Returns the records of the uploaded CSV file including the header.
+// Commas and semicolons are supported as separators.
+func readImportFile(c echo.Context) ([][]string, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	if fileHeader.Size > config.Data.MaxImportFileSize {
+		return nil, errImportFileTooBig
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	// spreadsheet applications like to add a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty import file")
+	}
+	return records, nil
+}
+
+// Returns the indices of the known columns in the header by their (english) name.
+// Header names can also be in the language of the user.
+func importColumns(header []string, known []string, lang string) (map[string]int, []string) {
+	columns := make(map[string]int, len(header))
+	var unknown []string
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, k := range known {
+			if strings.EqualFold(name, k) || strings.EqualFold(name, services.Tr(k, lang)) {
+				columns[k] = i
+				found = true
+				break
+			}
+		}
+		if !found && name != "" {
+			unknown = append(unknown, name)
+		}
+	}
+	return columns, unknown
+}
+
+func importField(row []string, columns map[string]int, name string) string {
+	i, ok := columns[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// Returns the untranslated error message or an empty string if both are valid.
+func validateTitleAndDescription(title, description string) string {
+	if utf8.RuneCountInString(title) > config.Data.MaxNameLength {
+		return "Title too long"
+	}
+	if utf8.RuneCountInString(title) < config.Data.MinNameLength {
+		return "Title too short"
+	}
+	if utf8.RuneCountInString(description) > config.Data.MaxDescriptionLength {
+		return "Description too long"
+	}
+	if utf8.RuneCountInString(description) < config.Data.MinDescriptionLength {
+		return "Description too short"
+	}
+	return ""
+}
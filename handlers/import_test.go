@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/juho05/h-bank/config"
+	"github.com/juho05/h-bank/db"
+	"github.com/juho05/h-bank/models"
+	"github.com/juho05/h-bank/router"
+)
+
+func newImportRequest(query, file string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "import.csv")
+	part.Write([]byte(file))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/?"+query, body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestHandler_ImportTransactions(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	member := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(member)
+
+	admin := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(admin)
+
+	outsider := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(outsider)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddMember(group, member)
+	gs.AddMember(group, admin)
+	gs.AddAdmin(group, admin)
+
+	handler := New(us, gs, nil)
+
+	valid := "Date,Title,Sender,Receiver,Amount\n2022-01-01,Pocket money,bank,bob@gmail.com,3\n2022-01-02,Ice cream,BOB@gmail.com,peter@gmail.com,\"1,50\"\n"
+
+	tests := []struct {
+		tName       string
+		user        *models.User
+		query       string
+		file        string
+		wantCode    int
+		wantSuccess bool
+		want        []string
+		wantBalance int
+	}{
+		{tName: "Not an admin", user: member, file: valid, wantCode: http.StatusForbidden, wantSuccess: false, want: []string{`"message":"Not an admin of the group"`}},
+		{tName: "Missing column", user: admin, file: "Date,Title,Sender,Receiver\n", wantCode: http.StatusOK, wantSuccess: false, want: []string{`"message":"Missing column 'Amount'"`}},
+		{tName: "Invalid rows", user: admin, file: "date;title;sender;receiver;amount\n01.01.2022;Test;Bank;alice@gmail.com;3\nyesterday;Test;Bank;bob@gmail.com;3\n2022-01-01;Test;bank;bob@gmail.com;0\n", wantCode: http.StatusOK, wantSuccess: false, want: []string{
+			`"rowCount":3`, `{"row":2,"message":"'alice@gmail.com' is not the email or name of a member of the group"}`, `{"row":3,"message":"Invalid date 'yesterday'"}`, `{"row":4,"message":"Invalid amount '0'"}`,
+		}},
+		{tName: "Not enough money", user: admin, file: "Date,Title,Sender,Receiver,Amount\n2022-01-01,Ice cream,bob@gmail.com,peter@gmail.com,1\n", wantCode: http.StatusOK, wantSuccess: false, want: []string{`{"row":2,"message":"Not enough money"}`}},
+		{tName: "Dry run", user: admin, query: "dryRun=true", file: valid, wantCode: http.StatusOK, wantSuccess: true, want: []string{`"message":"All rows are valid"`, `"dryRun":true,"rowCount":2,"errors":[]`}},
+		{tName: "Success", user: admin, file: valid, wantCode: http.StatusOK, wantSuccess: true, want: []string{`"message":"Successfully imported all rows"`}, wantBalance: 150},
+		{tName: "Older than latest transaction", user: admin, file: "Date,Title,Sender,Receiver,Amount\n2021-12-31,Gift,bank,bob@gmail.com,1\n", wantCode: http.StatusOK, wantSuccess: true, wantBalance: 250},
+		{tName: "Newer than latest transaction", user: admin, file: "Date,Title,Sender,Receiver,Amount\n2022-01-03,Refund,bank,bob@gmail.com,1\n", wantCode: http.StatusOK, wantSuccess: true, wantBalance: 350},
+		{tName: "Export", user: admin, file: "Date,Title,Description,Sender,Receiver,Amount,Balance,Currency\n2022-01-04T10:00:00Z,Chocolate,,Bob,Peter,-0.50,3.00,EUR\n", wantCode: http.StatusOK, wantSuccess: true, wantBalance: 300},
+		{tName: "Other currency", user: admin, file: "Date,Title,Sender,Receiver,Amount,Currency\n2022-01-04,Chocolate,bob,peter,1,USD\n", wantCode: http.StatusOK, wantSuccess: false, want: []string{`{"row":2,"message":"The currency 'USD' doesn't match the currency of the group"}`}, wantBalance: 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := newImportRequest(tt.query, tt.file)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+			c.Set("userId", tt.user.Id)
+			c.SetParamNames("id")
+			c.SetParamValues(group.Id)
+
+			err := handler.ImportTransactions(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			for _, want := range tt.want {
+				assert.Contains(t, rec.Body.String(), want)
+			}
+
+			balance, err := gs.GetUserBalance(group, member)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBalance, balance)
+		})
+	}
+
+	// the new balances of the transactions after the backdated one are recomputed
+	log, err := gs.GetTransactionLog(group, member, "", -1, -1, true)
+	if assert.NoError(t, err) && assert.Len(t, log, 5) {
+		wantBalances := []int{100, 400, 250, 350, 300}
+		for i, entry := range log {
+			newBalance := entry.NewBalanceReceiver
+			if entry.SenderId == member.Id {
+				newBalance = entry.NewBalanceSender
+			}
+			assert.Equal(t, wantBalances[i], newBalance, "new balance of '%s'", entry.Title)
+		}
+	}
+}
+
+func TestHandler_ImportCashLog(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+
+	handler := New(us, gs, nil)
+
+	valid := "Date;Title;Description;eur1;eur5;ct50\n2022-02-01;Birthday;;2;1;\n2022-01-01;Start;from grandma;1;;1\n"
+
+	tests := []struct {
+		tName       string
+		query       string
+		file        string
+		wantCode    int
+		wantSuccess bool
+		want        []string
+		wantTotal   int
+	}{
+		{tName: "Unknown wallet", query: "wallet=abc", file: valid, wantCode: http.StatusNotFound, wantSuccess: false, want: []string{`"message":"Wallet not found"`}},
+		{tName: "Missing file", wantCode: http.StatusBadRequest, wantSuccess: false, want: []string{`"message":"Invalid or missing CSV file"`}},
+		{tName: "Unknown column", file: "Date,Title,chf1\n", wantCode: http.StatusOK, wantSuccess: false, want: []string{`"message":"Unknown column 'chf1'"`}},
+		{tName: "Invalid count", file: "Date,Title,eur1\n2022-01-01,Start,-1\n", wantCode: http.StatusOK, wantSuccess: false, want: []string{`{"row":2,"message":"Invalid count '-1'"}`}},
+		{tName: "Dry run", query: "dryRun=true", file: valid, wantCode: http.StatusOK, wantSuccess: true, want: []string{`"dryRun":true,"rowCount":2,"errors":[]`}},
+		{tName: "Success", file: valid, wantCode: http.StatusOK, wantSuccess: true, want: []string{`"message":"Successfully imported all rows"`}, wantTotal: 700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/?"+tt.query, nil)
+			if tt.file != "" {
+				req = newImportRequest(tt.query, tt.file)
+			}
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+			c.Set("userId", user.Id)
+
+			err := handler.ImportCashLog(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			for _, want := range tt.want {
+				assert.Contains(t, rec.Body.String(), want)
+			}
+
+			total := 0
+			entry, err := us.GetLastCashLogEntry(user, models.DefaultCashWalletId)
+			assert.NoError(t, err)
+			if entry != nil {
+				total = entry.TotalAmount
+			}
+			assert.Equal(t, tt.wantTotal, total)
+		})
+	}
+}
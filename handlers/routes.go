@@ -27,18 +27,39 @@ func (h *Handler) RegisterAPI(api *echo.Group) {
 	user := api.Group("/user")
 
 	user.GET("/cash/current", h.GetCurrentCash, jwt)
+	user.GET("/cash/total", h.GetCashTotal, jwt)
+	user.GET("/cash/wallet", h.GetCashWallets, jwt)
+	user.POST("/cash/wallet", h.CreateCashWallet, jwt)
+	user.PUT("/cash/wallet/:walletId", h.UpdateCashWallet, jwt)
+	user.DELETE("/cash/wallet/:walletId", h.DeleteCashWallet, jwt)
+	user.POST("/cash/move", h.MoveCash, jwt)
+	user.POST("/cash/import", h.ImportCashLog, jwt)
 	user.GET("/cash/:id", h.GetCashLogEntryById, jwt)
+	user.PUT("/cash/:id", h.UpdateCashLogEntry, jwt)
+	user.DELETE("/cash/:id", h.DeleteCashLogEntry, jwt)
+	user.GET("/cash/:id/revisions", h.GetCashLogEntryRevisions, jwt)
 	user.GET("/cash", h.GetCashLog, jwt)
 	user.POST("/cash", h.AddCashLogEntry, jwt)
 
+	v2 := api.Group("/v2")
+	v2.GET("/user/cash/current", h.GetCurrentCashV2, jwt)
+	v2.GET("/user/cash/:id", h.GetCashLogEntryByIdV2, jwt)
+
 	api.GET("/group", h.GetGroups, jwt)
 	api.GET("/group/:id", h.GetGroupById, jwt)
 	api.POST("/group", h.CreateGroup, jwt)
 	api.PUT("/group/:id", h.UpdateGroup, jwt)
+	api.DELETE("/group/:id", h.DeleteGroup, jwt)
 
 	group := api.Group("/group")
+	group.PUT("/:id/approvalPolicy", h.SetApprovalPolicy, jwt)
+	group.PUT("/:id/interest", h.SetInterest, jwt)
+	group.POST("/:id/archive", h.ArchiveGroup, jwt)
+	group.DELETE("/:id/archive", h.UnarchiveGroup, jwt)
 	group.GET("/:id/member", h.GetGroupMembers, jwt)
 	group.DELETE("/:id/member", h.LeaveGroup, jwt)
+	group.GET("/:id/member/:userId/limits", h.GetSpendingLimits, jwt)
+	group.PUT("/:id/member/:userId/limits", h.SetSpendingLimits, jwt)
 	group.GET("/:id/admin", h.GetGroupAdmins, jwt)
 	group.POST("/:id/admin", h.AddGroupAdmin, jwt)
 	group.DELETE("/:id/admin", h.RemoveAdminRights, jwt)
@@ -48,9 +69,23 @@ func (h *Handler) RegisterAPI(api *echo.Group) {
 	group.DELETE("/:id/picture", h.RemoveGroupPicture, jwt)
 
 	group.GET("/:id/transaction/balance", h.GetBalance, jwt)
+	group.GET("/:id/transaction/savingsGoal", h.GetSavingsGoals, jwt)
+	group.POST("/:id/transaction/savingsGoal", h.CreateSavingsGoal, jwt)
+	group.GET("/:id/transaction/savingsGoal/:goalId", h.GetSavingsGoalById, jwt)
+	group.PUT("/:id/transaction/savingsGoal/:goalId", h.UpdateSavingsGoal, jwt)
+	group.DELETE("/:id/transaction/savingsGoal/:goalId", h.DeleteSavingsGoal, jwt)
+	group.POST("/:id/transaction/savingsGoal/:goalId/deposit", h.DepositToSavingsGoal, jwt)
+	group.POST("/:id/transaction/savingsGoal/:goalId/withdraw", h.WithdrawFromSavingsGoal, jwt)
+	group.GET("/:id/transaction/export", h.ExportTransactionLog, jwt)
+	group.POST("/:id/transaction/import", h.ImportTransactions, jwt)
+	group.GET("/:id/transaction/pending", h.GetPendingTransactions, jwt)
+	group.POST("/:id/transaction/pending/:pendingId", h.ApprovePendingTransaction, jwt)
+	group.DELETE("/:id/transaction/pending/:pendingId", h.RejectPendingTransaction, jwt)
 	group.GET("/:id/transaction/:transactionId", h.GetTransactionById, jwt)
 	group.GET("/:id/transaction", h.GetTransactionLog, jwt)
 	group.POST("/:id/transaction", h.CreateTransaction, jwt)
+	group.POST("/:id/transaction/cash", h.CreateCashTransaction, jwt)
+	group.POST("/:id/transaction/:transactionId/reverse", h.ReverseTransaction, jwt)
 
 	group.GET("/:id/invitation", h.GetInvitationsByGroup, jwt)
 	group.GET("/invitation", h.GetInvitationsByUser, jwt)
@@ -59,12 +94,24 @@ func (h *Handler) RegisterAPI(api *echo.Group) {
 	group.POST("/invitation/:id", h.AcceptInvitation, jwt)
 	group.DELETE("/invitation/:id", h.DenyInvitation, jwt)
 
+	group.GET("/:id/paymentRequest", h.GetBankPaymentRequests, jwt)
+	group.GET("/paymentRequest/incoming", h.GetIncomingPaymentRequests, jwt)
+	group.GET("/paymentRequest/outgoing", h.GetOutgoingPaymentRequests, jwt)
+	group.GET("/paymentRequest/:id", h.GetPaymentRequestById, jwt)
+	group.POST("/:id/paymentRequest", h.CreatePaymentRequest, jwt)
+	group.POST("/paymentRequest/:id", h.ApprovePaymentRequest, jwt)
+	group.DELETE("/paymentRequest/:id", h.DeclinePaymentRequest, jwt)
+
 	group.GET("/:id/paymentPlan/:paymentPlanId", h.GetPaymentPlanById, jwt)
+	group.GET("/:id/paymentPlan/:paymentPlanId/executions", h.GetPaymentPlanExecutions, jwt)
 	group.GET("/:id/paymentPlan", h.GetPaymentPlans, jwt)
 	group.GET("/:id/paymentPlan/nextPayment", h.GetPaymentPlanNextPayments, jwt)
 	group.POST("/:id/paymentPlan", h.CreatePaymentPlan, jwt)
 	group.PUT("/:id/paymentPlan/:paymentPlanId", h.UpdatePaymentPlan, jwt)
 	group.DELETE("/:id/paymentPlan/:paymentPlanId", h.DeletePaymentPlan, jwt)
+	group.POST("/:id/paymentPlan/:paymentPlanId/pause", h.PausePaymentPlan, jwt)
+	group.POST("/:id/paymentPlan/:paymentPlanId/resume", h.ResumePaymentPlan, jwt)
+	group.POST("/:id/paymentPlan/:paymentPlanId/skip", h.SkipPaymentPlan, jwt)
 
 	group.GET("/:id/total", h.GetTotalMoney, jwt)
 }
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -102,8 +104,30 @@ func (h *Handler) GetUser(c echo.Context) error {
 
 // /api/user/delete (POST)
 func (h *Handler) DeleteUser(c echo.Context) error {
-	// TODO
-	return echo.ErrNotFound
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	// groups in which the user is the only user are deleted together with the user
+	err = h.userStore.Delete(user)
+	var soleAdminErr *models.SoleGroupAdminError
+	if errors.As(err, &soleAdminErr) {
+		return c.JSON(http.StatusOK, responses.NewDeleteFailedBecauseOfSoleGroupAdmin(soleAdminErr.GroupIds, lang))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	h.Logout(c)
+
+	return c.JSON(http.StatusOK, responses.New(true, "Successfully deleted account", lang))
 }
 
 // /api/user (PUT)
@@ -125,6 +149,21 @@ func (h *Handler) UpdateUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
 	}
 
+	if body.CashCurrency != "" && body.CashCurrency != user.CashCurrency {
+		if !services.IsSupportedCurrency(body.CashCurrency) {
+			return c.JSON(http.StatusOK, responses.New(false, "Unsupported currency", lang))
+		}
+
+		entry, err := h.userStore.GetLastCashLogEntry(user, models.DefaultCashWalletId)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if entry != nil && entry.TotalAmount != 0 {
+			return c.JSON(http.StatusOK, responses.New(false, "Your cash needs to be empty to change its currency", lang))
+		}
+		user.CashCurrency = body.CashCurrency
+	}
+
 	user.DontSendInvitationEmail = body.DontSendInvitationEmail
 	user.PubliclyVisible = body.PubliclyVisible
 	h.userStore.Update(user)
@@ -132,7 +171,7 @@ func (h *Handler) UpdateUser(c echo.Context) error {
 	return c.JSON(http.StatusOK, responses.NewAuthUser(user))
 }
 
-// /api/user/cash/current (GET)
+// /api/user/cash/current?wallet=string (GET)
 func (h *Handler) GetCurrentCash(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
@@ -145,7 +184,15 @@ func (h *Handler) GetCurrentCash(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
 	}
 
-	entry, err := h.userStore.GetLastCashLogEntry(user)
+	walletId, currency, err := h.getCashWallet(user, c.QueryParam("wallet"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if currency == "" {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+
+	entry, err := h.userStore.GetLastCashLogEntry(user, walletId)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
@@ -154,10 +201,12 @@ func (h *Handler) GetCurrentCash(c echo.Context) error {
 			Base: models.Base{
 				Created: user.Created,
 			},
+			Currency: currency,
+			WalletId: walletId,
 		}
 	}
 
-	return c.JSON(http.StatusOK, responses.NewCashLogEntry(entry))
+	return c.JSON(http.StatusOK, responses.NewCashLogEntry(entry, lang))
 }
 
 // /api/user/cash/:id (GET)
@@ -186,10 +235,78 @@ func (h *Handler) GetCashLogEntryById(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
 	}
 
-	return c.JSON(http.StatusOK, responses.NewCashLogEntry(entry))
+	return c.JSON(http.StatusOK, responses.NewCashLogEntry(entry, lang))
 }
 
-// /api/user/cash?page=int&pageSize=int&oldestFirst=bool (GET)
+// Returns the counts of all denominations of the currency instead of the EUR fields.
+// /api/v2/user/cash/current?wallet=string (GET)
+func (h *Handler) GetCurrentCashV2(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	walletId, currency, err := h.getCashWallet(user, c.QueryParam("wallet"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if currency == "" {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+
+	entry, err := h.userStore.GetLastCashLogEntry(user, walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if entry == nil {
+		entry = &models.CashLogEntry{
+			Base: models.Base{
+				Created: user.Created,
+			},
+			Currency: currency,
+			WalletId: walletId,
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashLogEntryV2(entry, lang))
+}
+
+// /api/v2/user/cash/:id (GET)
+func (h *Handler) GetCashLogEntryByIdV2(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	entry, err := h.userStore.GetCashLogEntryById(user, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if entry == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashLogEntryV2(entry, lang))
+}
+
+// /api/user/cash?wallet=string&page=int&pageSize=int&oldestFirst=bool (GET)
 func (h *Handler) GetCashLog(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
@@ -224,20 +341,28 @@ func (h *Handler) GetCashLog(c echo.Context) error {
 
 	oldestFirst := services.StrToBool(c.QueryParam("oldestFirst"))
 
-	entries, err := h.userStore.GetCashLog(user, c.QueryParam("search"), page, pageSize, oldestFirst)
+	walletId, currency, err := h.getCashWallet(user, c.QueryParam("wallet"))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
+	if currency == "" {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
 
-	count, err := h.userStore.CashLogEntryCount(user)
+	entries, err := h.userStore.GetCashLog(user, walletId, c.QueryParam("search"), page, pageSize, oldestFirst)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
 	}
 
-	return c.JSON(http.StatusOK, responses.NewCashLog(entries, count))
+	count, err := h.userStore.CashLogEntryCount(user, walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashLog(entries, count, lang))
 }
 
-// /api/user/cash (POST)
+// /api/user/cash?wallet=string (POST)
 func (h *Handler) AddCashLogEntry(c echo.Context) error {
 	lang := c.Get("lang").(string)
 
@@ -275,24 +400,36 @@ func (h *Handler) AddCashLogEntry(c echo.Context) error {
 		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
 	}
 
+	walletId, currency, err := h.getCashWallet(user, c.QueryParam("wallet"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if currency == "" {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+
+	denominations, invalidKey := newDenominationCounts(bodyDenominationCounts(body), currency)
+	if invalidKey != "" {
+		return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Invalid denomination '%s' for currency %s", lang), invalidKey, currency), ""))
+	}
+
+	if body.CorrectionOfId != "" {
+		corrected, err := h.userStore.GetCashLogEntryById(user, body.CorrectionOfId)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if corrected == nil || corrected.WalletId != walletId {
+			return c.JSON(http.StatusOK, responses.New(false, "The corrected cash log entry does not exist in this wallet", lang))
+		}
+	}
+
 	cashLogEntry := models.CashLogEntry{
 		ChangeTitle:       body.Title,
 		ChangeDescription: body.Description,
-		Ct1:               int(body.Ct1),
-		Ct2:               int(body.Ct2),
-		Ct5:               int(body.Ct5),
-		Ct10:              int(body.Ct10),
-		Ct20:              int(body.Ct20),
-		Ct50:              int(body.Ct50),
-		Eur1:              int(body.Eur1),
-		Eur2:              int(body.Eur2),
-		Eur5:              int(body.Eur5),
-		Eur10:             int(body.Eur10),
-		Eur20:             int(body.Eur20),
-		Eur50:             int(body.Eur50),
-		Eur100:            int(body.Eur100),
-		Eur200:            int(body.Eur200),
-		Eur500:            int(body.Eur500),
+		Currency:          currency,
+		WalletId:          walletId,
+		Denominations:     denominations,
+		CorrectionOfId:    body.CorrectionOfId,
 	}
 
 	err = h.userStore.AddCashLogEntry(user, &cashLogEntry)
@@ -302,3 +439,487 @@ func (h *Handler) AddCashLogEntry(c echo.Context) error {
 
 	return c.JSON(http.StatusCreated, responses.New(true, "Successfully added new cash log entry", lang))
 }
+
+// Replaces the values of one of the most recent entries of a wallet.
+// /api/user/cash/:id (PUT)
+func (h *Handler) UpdateCashLogEntry(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	entry, err := h.userStore.GetCashLogEntryById(user, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if entry == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	var body bindings.AddCashLogEntry
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	body.Title = strings.TrimSpace(body.Title)
+	body.Description = strings.TrimSpace(body.Description)
+
+	if utf8.RuneCountInString(body.Title) > config.Data.MaxNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Title) < config.Data.MinNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too short", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) > config.Data.MaxDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) < config.Data.MinDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
+	}
+
+	denominations, invalidKey := newDenominationCounts(bodyDenominationCounts(body), entry.Currency)
+	if invalidKey != "" {
+		return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Invalid denomination '%s' for currency %s", lang), invalidKey, entry.Currency), ""))
+	}
+
+	err = h.userStore.UpdateCashLogEntry(user, entry, body.Title, body.Description, denominations)
+	if err != nil {
+		switch err {
+		case models.ErrCashLogEntryNotEditable:
+			return c.JSON(http.StatusOK, responses.New(false, "Only the most recent cash log entries can be changed, add a correction entry instead", lang))
+		case models.ErrCashLogEntryLinked:
+			return c.JSON(http.StatusOK, responses.New(false, "Cash log entries of moves, payouts and deposits cannot be changed", lang))
+		default:
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+	}
+
+	entry, err = h.userStore.GetCashLogEntryById(user, id)
+	if err != nil || entry == nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashLogEntryV2(entry, lang))
+}
+
+// Deletes one of the most recent entries of a wallet.
+// /api/user/cash/:id (DELETE)
+func (h *Handler) DeleteCashLogEntry(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	entry, err := h.userStore.GetCashLogEntryById(user, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if entry == nil {
+		return c.JSON(http.StatusNotFound, responses.NewNotFound(lang))
+	}
+
+	err = h.userStore.DeleteCashLogEntry(user, entry)
+	if err != nil {
+		switch err {
+		case models.ErrCashLogEntryNotEditable:
+			return c.JSON(http.StatusOK, responses.New(false, "Only the most recent cash log entries can be changed, add a correction entry instead", lang))
+		case models.ErrCashLogEntryLinked:
+			return c.JSON(http.StatusOK, responses.New(false, "Cash log entries of moves, payouts and deposits cannot be changed", lang))
+		default:
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.New(true, "Successfully deleted cash log entry", lang))
+}
+
+// Returns the original values of all updates and the deletion of the entry.
+// /api/user/cash/:id/revisions (GET)
+func (h *Handler) GetCashLogEntryRevisions(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing id parameter", lang))
+	}
+
+	revisions, err := h.userStore.GetCashLogEntryRevisions(user, id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashLogEntryRevisions(revisions, lang))
+}
+
+// Merges the EUR fields of the v1 API with the generic denomination counts.
+func bodyDenominationCounts(body bindings.AddCashLogEntry) map[string]uint {
+	counts := map[string]uint{
+		"ct1": body.Ct1, "ct2": body.Ct2, "ct5": body.Ct5, "ct10": body.Ct10, "ct20": body.Ct20, "ct50": body.Ct50,
+		"eur1": body.Eur1, "eur2": body.Eur2, "eur5": body.Eur5, "eur10": body.Eur10, "eur20": body.Eur20,
+		"eur50": body.Eur50, "eur100": body.Eur100, "eur200": body.Eur200, "eur500": body.Eur500,
+	}
+	for key, count := range body.Denominations {
+		counts[key] += count
+	}
+	return counts
+}
+
+// Returns the counts in the order of the denominations of the currency
+// or the key of a denomination which does not belong to the currency.
+func newDenominationCounts(counts map[string]uint, currency string) ([]models.CashDenominationCount, string) {
+	for key, count := range counts {
+		if count == 0 {
+			continue
+		}
+		if _, ok := services.GetDenomination(currency, key); !ok {
+			return nil, key
+		}
+	}
+
+	denominations := make([]models.CashDenominationCount, 0, len(counts))
+	for _, d := range services.Currencies[currency].Denominations {
+		if counts[d.Key] > 0 {
+			denominations = append(denominations, models.CashDenominationCount{
+				Key:   d.Key,
+				Count: int(counts[d.Key]),
+			})
+		}
+	}
+	return denominations, ""
+}
+
+// Returns the id and the currency of the wallet. An empty id or "default" selects the default wallet.
+// The currency is empty if the wallet does not exist.
+func (h *Handler) getCashWallet(user *models.User, walletId string) (string, string, error) {
+	if walletId == "" || walletId == "default" {
+		return models.DefaultCashWalletId, user.CashCurrency, nil
+	}
+
+	wallet, err := h.userStore.GetCashWalletById(user, walletId)
+	if err != nil || wallet == nil {
+		return "", "", err
+	}
+	return wallet.Id, wallet.Currency, nil
+}
+
+// /api/user/cash/wallet (GET)
+func (h *Handler) GetCashWallets(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	wallets, err := h.userStore.GetCashWallets(user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	// the default wallet is listed first
+	wallets = append([]models.CashWallet{{
+		Base:     models.Base{Id: models.DefaultCashWalletId},
+		Name:     services.Tr("Cash", lang),
+		Currency: user.CashCurrency,
+		UserId:   user.Id,
+	}}, wallets...)
+
+	amounts := make([]int, len(wallets))
+	for i, w := range wallets {
+		entry, err := h.userStore.GetLastCashLogEntry(user, w.Id)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+		if entry != nil {
+			amounts[i] = entry.TotalAmount
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashWallets(wallets, amounts, lang))
+}
+
+// /api/user/cash/wallet (POST)
+func (h *Handler) CreateCashWallet(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	var body bindings.CashWallet
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if utf8.RuneCountInString(body.Name) > config.Data.MaxNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Name too long", lang))
+	}
+	if utf8.RuneCountInString(body.Name) < config.Data.MinNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Name too short", lang))
+	}
+
+	if body.Currency == "" {
+		body.Currency = user.CashCurrency
+	}
+	if !services.IsSupportedCurrency(body.Currency) {
+		return c.JSON(http.StatusOK, responses.New(false, "Unsupported currency", lang))
+	}
+
+	wallet, err := h.userStore.CreateCashWallet(user, body.Name, body.Currency)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusCreated, responses.NewCashWallet(wallet, 0, lang))
+}
+
+// /api/user/cash/wallet/:walletId (PUT)
+func (h *Handler) UpdateCashWallet(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	walletId := c.Param("walletId")
+	if walletId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing walletId parameter", lang))
+	}
+
+	wallet, err := h.userStore.GetCashWalletById(user, walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if wallet == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+
+	var body bindings.CashWallet
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if utf8.RuneCountInString(body.Name) > config.Data.MaxNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Name too long", lang))
+	}
+	if utf8.RuneCountInString(body.Name) < config.Data.MinNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Name too short", lang))
+	}
+
+	wallet.Name = body.Name
+	err = h.userStore.UpdateCashWallet(wallet)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	entry, err := h.userStore.GetLastCashLogEntry(user, wallet.Id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	amount := 0
+	if entry != nil {
+		amount = entry.TotalAmount
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashWallet(wallet, amount, lang))
+}
+
+// Deletes the wallet with its log. The wallet needs to be empty.
+// /api/user/cash/wallet/:walletId (DELETE)
+func (h *Handler) DeleteCashWallet(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	walletId := c.Param("walletId")
+	if walletId == "" {
+		return c.JSON(http.StatusBadRequest, responses.New(false, "Missing walletId parameter", lang))
+	}
+
+	wallet, err := h.userStore.GetCashWalletById(user, walletId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if wallet == nil {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+
+	entry, err := h.userStore.GetLastCashLogEntry(user, wallet.Id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if entry != nil && entry.TotalAmount != 0 {
+		return c.JSON(http.StatusOK, responses.New(false, "The wallet needs to be empty to be deleted", lang))
+	}
+
+	err = h.userStore.DeleteCashWallet(wallet)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.New(true, "Successfully deleted wallet", lang))
+}
+
+// Moves cash from one wallet to another. The log of each wallet gets an entry referencing the other one.
+// /api/user/cash/move (POST)
+func (h *Handler) MoveCash(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	var body bindings.MoveCash
+	err = c.Bind(&body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.NewInvalidRequestBody(lang))
+	}
+
+	body.Title = strings.TrimSpace(body.Title)
+	body.Description = strings.TrimSpace(body.Description)
+
+	if utf8.RuneCountInString(body.Title) > config.Data.MaxNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Title) < config.Data.MinNameLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Title too short", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) > config.Data.MaxDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too long", lang))
+	}
+
+	if utf8.RuneCountInString(body.Description) < config.Data.MinDescriptionLength {
+		return c.JSON(http.StatusOK, responses.New(false, "Description too short", lang))
+	}
+
+	fromId, fromCurrency, err := h.getCashWallet(user, body.From)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	toId, toCurrency, err := h.getCashWallet(user, body.To)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if fromCurrency == "" || toCurrency == "" {
+		return c.JSON(http.StatusNotFound, responses.New(false, "Wallet not found", lang))
+	}
+
+	if fromId == toId {
+		return c.JSON(http.StatusOK, responses.New(false, "Cannot move cash into the same wallet", lang))
+	}
+
+	if fromCurrency != toCurrency {
+		return c.JSON(http.StatusOK, responses.New(false, "Cash can only be moved between wallets of the same currency", lang))
+	}
+
+	denominations, invalidKey := newDenominationCounts(body.Denominations, fromCurrency)
+	if invalidKey != "" {
+		return c.JSON(http.StatusOK, responses.New(false, fmt.Sprintf(services.Tr("Invalid denomination '%s' for currency %s", lang), invalidKey, fromCurrency), ""))
+	}
+	if len(denominations) == 0 {
+		return c.JSON(http.StatusOK, responses.New(false, "Nothing to move", lang))
+	}
+
+	from, _, err := h.userStore.MoveCash(user, fromId, toId, body.Title, body.Description, denominations)
+	if err != nil {
+		switch err {
+		case models.ErrNotEnoughCash:
+			return c.JSON(http.StatusOK, responses.New(false, "The wallet does not contain enough cash", lang))
+		case models.ErrCurrencyMismatch:
+			return c.JSON(http.StatusOK, responses.New(false, "Cash can only be moved between wallets of the same currency", lang))
+		default:
+			return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+		}
+	}
+
+	return c.JSON(http.StatusCreated, responses.NewCashLogEntryV2(from, lang))
+}
+
+// Returns the sum of all wallets by currency.
+// /api/user/cash/total (GET)
+func (h *Handler) GetCashTotal(c echo.Context) error {
+	lang := c.Get("lang").(string)
+
+	userId := c.Get("userId").(string)
+	user, err := h.userStore.GetById(userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, responses.NewUserNoLongerExists(lang))
+	}
+
+	totals, err := h.userStore.GetCashTotals(user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.NewUnexpectedError(err, lang))
+	}
+
+	return c.JSON(http.StatusOK, responses.NewCashTotals(totals, lang))
+}
@@ -31,9 +31,9 @@ func TestHandler_GetUsers(t *testing.T) {
 		t.Fatalf("Couldn't create test database")
 	}
 	defer db.DeleteTestDB(dbId)
-	err = db.AutoMigrate(database)
+	err = db.Migrate(database)
 	if err != nil {
-		t.Fatalf("Couldn't auto migrate database")
+		t.Fatalf("Couldn't migrate database")
 	}
 
 	us := db.NewUserStore(database)
@@ -103,9 +103,9 @@ func TestHandler_GetUser(t *testing.T) {
 		t.Fatalf("Couldn't create test database")
 	}
 	defer db.DeleteTestDB(dbId)
-	err = db.AutoMigrate(database)
+	err = db.Migrate(database)
 	if err != nil {
-		t.Fatalf("Couldn't auto migrate database")
+		t.Fatalf("Couldn't migrate database")
 	}
 
 	us := db.NewUserStore(database)
@@ -182,9 +182,9 @@ func TestHandler_UpdateUser(t *testing.T) {
 		t.Fatalf("Couldn't create test database")
 	}
 	defer db.DeleteTestDB(dbId)
-	err = db.AutoMigrate(database)
+	err = db.Migrate(database)
 	if err != nil {
-		t.Fatalf("Couldn't auto migrate database")
+		t.Fatalf("Couldn't migrate database")
 	}
 
 	us := db.NewUserStore(database)
@@ -255,9 +255,9 @@ func TestHandler_GetCurrentCash(t *testing.T) {
 		t.Fatalf("Couldn't create test database")
 	}
 	defer db.DeleteTestDB(dbId)
-	err = db.AutoMigrate(database)
+	err = db.Migrate(database)
 	if err != nil {
-		t.Fatalf("Couldn't auto migrate database")
+		t.Fatalf("Couldn't migrate database")
 	}
 
 	us := db.NewUserStore(database)
@@ -324,9 +324,9 @@ func TestHandler_GetCashLogEntryById(t *testing.T) {
 		t.Fatalf("Couldn't create test database")
 	}
 	defer db.DeleteTestDB(dbId)
-	err = db.AutoMigrate(database)
+	err = db.Migrate(database)
 	if err != nil {
-		t.Fatalf("Couldn't auto migrate database")
+		t.Fatalf("Couldn't migrate database")
 	}
 
 	us := db.NewUserStore(database)
@@ -389,9 +389,9 @@ func TestHandler_GetCashLog(t *testing.T) {
 		t.Fatalf("Couldn't create test database")
 	}
 	defer db.DeleteTestDB(dbId)
-	err = db.AutoMigrate(database)
+	err = db.Migrate(database)
 	if err != nil {
-		t.Fatalf("Couldn't auto migrate database")
+		t.Fatalf("Couldn't migrate database")
 	}
 
 	us := db.NewUserStore(database)
@@ -460,9 +460,9 @@ func TestHandler_AddCashLogEntry(t *testing.T) {
 		t.Fatalf("Couldn't create test database")
 	}
 	defer db.DeleteTestDB(dbId)
-	err = db.AutoMigrate(database)
+	err = db.Migrate(database)
 	if err != nil {
-		t.Fatalf("Couldn't auto migrate database")
+		t.Fatalf("Couldn't migrate database")
 	}
 
 	us := db.NewUserStore(database)
@@ -512,7 +512,7 @@ func TestHandler_AddCashLogEntry(t *testing.T) {
 			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
 			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
 
-			log, _ := us.GetCashLog(tt.user, "", 0, 10, false)
+			log, _ := us.GetCashLog(tt.user, models.DefaultCashWalletId, "", 0, 10, false)
 			if tt.wantSuccess {
 				assert.Equal(t, 1, len(log))
 			} else {
@@ -521,3 +521,439 @@ func TestHandler_AddCashLogEntry(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_DeleteUser(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+	gs := db.NewGroupStore(database)
+
+	admin := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(admin)
+
+	member := &models.User{
+		Name:  "peter",
+		Email: "peter@gmail.com",
+	}
+	us.Create(member)
+
+	loner := &models.User{
+		Name:  "alice",
+		Email: "alice@gmail.com",
+	}
+	us.Create(loner)
+
+	group := &models.Group{
+		Name: "family",
+	}
+	gs.Create(group)
+	gs.AddAdmin(group, admin)
+	gs.AddMember(group, admin)
+	gs.AddMember(group, member)
+
+	lonerGroup := &models.Group{
+		Name: "alone",
+	}
+	gs.Create(lonerGroup)
+	gs.AddAdmin(lonerGroup, loner)
+	gs.AddMember(lonerGroup, loner)
+
+	gs.CreateTransaction(group, true, false, nil, member, "pocket money", "", 500)
+	gs.CreateTransaction(group, false, false, member, admin, "gift", "", 200)
+	gs.CreatePaymentPlan(group, true, false, nil, member, "allowance", "", 100, -1, 1, models.ScheduleUnitWeek, models.ScheduleRuleDate, false, time.Now().Add(time.Hour).Unix(), 0, models.PaymentPlanFailureRetry)
+
+	handler := New(us, gs, nil)
+
+	tests := []struct {
+		tName        string
+		user         *models.User
+		wantCode     int
+		wantSuccess  bool
+		wantMessage  string
+		wantGroupIds []string
+	}{
+		{tName: "Sole admin", user: admin, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Failed to delete user because he is the only admin of one or more groups", wantGroupIds: []string{group.Id}},
+		{tName: "Member with balance", user: member, wantCode: http.StatusOK, wantSuccess: true, wantMessage: "Successfully deleted account"},
+		{tName: "Only user of group", user: loner, wantCode: http.StatusOK, wantSuccess: true, wantMessage: "Successfully deleted account"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+
+			c.Set("userId", tt.user.Id)
+
+			err := handler.DeleteUser(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+
+			user, _ := us.GetById(tt.user.Id)
+			if tt.wantSuccess {
+				assert.Nil(t, user)
+			} else {
+				assert.NotNil(t, user)
+				var resp responses.DeleteFailedBecauseOfSoleGroupAdmin
+				json.Unmarshal(rec.Body.Bytes(), &resp)
+				assert.Equal(t, tt.wantGroupIds, resp.GroupIds)
+			}
+		})
+	}
+
+	var count int64
+	database.Model(&models.TransactionLogEntry{}).Where("sender_id = ? OR receiver_id = ?", member.Id, member.Id).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	log, _ := gs.GetTransactionLog(group, admin, "", -1, -1, true)
+	assert.Equal(t, 1, len(log))
+	assert.Equal(t, models.DeletedUserId, log[0].SenderId)
+
+	bankLog, _ := gs.GetBankTransactionLog(group, "", -1, -1, true)
+	assert.Equal(t, 2, len(bankLog))
+	payout := 0
+	for _, entry := range bankLog {
+		if entry.ReceiverIsBank {
+			payout += entry.Amount
+			assert.Equal(t, models.TransactionKindAccountDeleted, entry.Kind)
+			assert.Empty(t, entry.Title, "the title of the payout should be translated from its kind")
+		}
+	}
+	assert.Equal(t, 300, payout)
+
+	paymentPlanCount, _ := gs.BankPaymentPlanCount(group)
+	assert.Equal(t, int64(0), paymentPlanCount)
+
+	deletedGroup, _ := gs.GetById(lonerGroup.Id)
+	assert.Nil(t, deletedGroup)
+
+	balance, _ := gs.GetUserBalance(group, admin)
+	assert.Equal(t, 200, balance)
+}
+
+func TestHandler_CashCurrency(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+
+	user := &models.User{
+		Name:         "bob",
+		Email:        "bob@gmail.com",
+		CashCurrency: "CHF",
+	}
+	us.Create(user)
+
+	handler := New(us, nil, nil)
+
+	tests := []struct {
+		tName       string
+		entry       bindings.AddCashLogEntry
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Euro denomination", entry: bindings.AddCashLogEntry{Title: "Test", Denominations: map[string]uint{"eur1": 1}}, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Invalid denomination 'eur1' for currency CHF"},
+		{tName: "Euro column", entry: bindings.AddCashLogEntry{Title: "Test", Eur1: 1}, wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Invalid denomination 'eur1' for currency CHF"},
+		{tName: "Success", entry: bindings.AddCashLogEntry{Title: "Test", Denominations: map[string]uint{"chf5": 2, "rp50": 1}}, wantCode: http.StatusCreated, wantSuccess: true, wantMessage: "Successfully added new cash log entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			jsonBody, _ := json.Marshal(tt.entry)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(jsonBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+			c.Set("userId", user.Id)
+
+			err := handler.AddCashLogEntry(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", user.Id)
+	assert.NoError(t, handler.GetCurrentCashV2(c))
+	assert.Contains(t, rec.Body.String(), `"amount":1050`)
+	assert.Contains(t, rec.Body.String(), `"currency":"CHF"`)
+	assert.Contains(t, rec.Body.String(), `"formattedAmount":"CHF 10.50"`)
+	assert.Contains(t, rec.Body.String(), `{"key":"chf5","value":500,"count":2}`)
+
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"cashCurrency": "GBP"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", user.Id)
+	assert.NoError(t, handler.UpdateUser(c))
+	assert.Contains(t, rec.Body.String(), `"message":"Your cash needs to be empty to change its currency"`)
+}
+
+func TestHandler_GetCurrentCash_EurFields(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+
+	handler := New(us, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title": "Test", "ct50": 3, "eur10": 1, "denominations": {"eur10": 1}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", user.Id)
+	assert.NoError(t, handler.AddCashLogEntry(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c = r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", user.Id)
+	assert.NoError(t, handler.GetCurrentCash(c))
+	assert.Contains(t, rec.Body.String(), `"ct50":3`)
+	assert.Contains(t, rec.Body.String(), `"eur10":2`)
+	assert.Contains(t, rec.Body.String(), `"amount":2150`)
+	assert.NotContains(t, rec.Body.String(), `"denominations"`)
+}
+
+func TestHandler_CashWallets(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+
+	handler := New(us, nil, nil)
+
+	piggyBank, err := us.CreateCashWallet(user, "Piggy bank", "EUR")
+	assert.NoError(t, err)
+	holiday, err := us.CreateCashWallet(user, "Holiday", "USD")
+	assert.NoError(t, err)
+
+	err = us.AddCashLogEntry(user, &models.CashLogEntry{ChangeTitle: "Pocket money", Currency: "EUR", Denominations: []models.CashDenominationCount{{Key: "eur5", Count: 2}, {Key: "eur1", Count: 3}}})
+	assert.NoError(t, err)
+	err = us.AddCashLogEntry(user, &models.CashLogEntry{ChangeTitle: "Souvenirs", Currency: "USD", WalletId: holiday.Id, Denominations: []models.CashDenominationCount{{Key: "usd20", Count: 1}}})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		tName       string
+		body        string
+		wantCode    int
+		wantSuccess bool
+		wantMessage string
+	}{
+		{tName: "Unknown wallet", body: `{"title": "Save", "to": "unknown", "denominations": {"eur5": 1}}`, wantCode: http.StatusNotFound, wantSuccess: false, wantMessage: "Wallet not found"},
+		{tName: "Same wallet", body: fmt.Sprintf(`{"title": "Save", "from": "%s", "to": "%s", "denominations": {"eur5": 1}}`, piggyBank.Id, piggyBank.Id), wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Cannot move cash into the same wallet"},
+		{tName: "Different currency", body: fmt.Sprintf(`{"title": "Save", "to": "%s", "denominations": {"eur5": 1}}`, holiday.Id), wantCode: http.StatusOK, wantSuccess: false, wantMessage: "Cash can only be moved between wallets of the same currency"},
+		{tName: "Not enough cash", body: fmt.Sprintf(`{"title": "Save", "to": "%s", "denominations": {"eur5": 3}}`, piggyBank.Id), wantCode: http.StatusOK, wantSuccess: false, wantMessage: "The wallet does not contain enough cash"},
+		{tName: "Success", body: fmt.Sprintf(`{"title": "Save", "to": "%s", "denominations": {"eur5": 1, "eur1": 1}}`, piggyBank.Id), wantCode: http.StatusCreated, wantSuccess: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := r.NewContext(req, rec)
+			c.Set("lang", "en")
+			c.Set("userId", user.Id)
+
+			err := handler.MoveCash(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"success":%t`, tt.wantSuccess))
+			if tt.wantMessage != "" {
+				assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"message":"%s"`, tt.wantMessage))
+			}
+		})
+	}
+
+	from, _ := us.GetLastCashLogEntry(user, models.DefaultCashWalletId)
+	to, _ := us.GetLastCashLogEntry(user, piggyBank.Id)
+	if assert.NotNil(t, from) && assert.NotNil(t, to) {
+		assert.Equal(t, 700, from.TotalAmount)
+		assert.Equal(t, -600, from.ChangeDifference)
+		assert.Equal(t, 600, to.TotalAmount)
+		assert.Equal(t, 600, to.ChangeDifference)
+		assert.Equal(t, to.Id, from.MoveEntryId)
+		assert.Equal(t, from.Id, to.MoveEntryId)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", user.Id)
+	assert.NoError(t, handler.GetCashTotal(c))
+	assert.Contains(t, rec.Body.String(), `{"currency":"EUR","amount":1300,"formattedAmount":"€13.00"}`)
+	assert.Contains(t, rec.Body.String(), `{"currency":"USD","amount":2000,"formattedAmount":"$20.00"}`)
+
+	req = httptest.NewRequest(http.MethodGet, "/?wallet="+piggyBank.Id, nil)
+	rec = httptest.NewRecorder()
+	c = r.NewContext(req, rec)
+	c.Set("lang", "en")
+	c.Set("userId", user.Id)
+	assert.NoError(t, handler.GetCashLog(c))
+	assert.Contains(t, rec.Body.String(), `"count":1`)
+
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c = r.NewContext(req, rec)
+	c.SetParamNames("walletId")
+	c.SetParamValues(piggyBank.Id)
+	c.Set("lang", "en")
+	c.Set("userId", user.Id)
+	assert.NoError(t, handler.DeleteCashWallet(c))
+	assert.Contains(t, rec.Body.String(), `"message":"The wallet needs to be empty to be deleted"`)
+}
+
+func TestHandler_UpdateAndDeleteCashLogEntry(t *testing.T) {
+	t.Parallel()
+	config.Data.Debug = true
+	r := router.New()
+
+	database, dbId, err := db.NewTestDB()
+	if err != nil {
+		t.Fatalf("Couldn't create test database")
+	}
+	defer db.DeleteTestDB(dbId)
+	err = db.Migrate(database)
+	if err != nil {
+		t.Fatalf("Couldn't migrate database")
+	}
+
+	us := db.NewUserStore(database)
+
+	user := &models.User{
+		Name:  "bob",
+		Email: "bob@gmail.com",
+	}
+	us.Create(user)
+
+	handler := New(us, nil, nil)
+
+	entries := make([]*models.CashLogEntry, config.Data.EditableCashLogEntries+2)
+	for i := range entries {
+		entries[i] = &models.CashLogEntry{ChangeTitle: "Count", Currency: "EUR", Denominations: []models.CashDenominationCount{{Key: "eur1", Count: i + 1}}}
+		assert.NoError(t, us.AddCashLogEntry(user, entries[i]))
+	}
+	oldest := entries[0]
+	second := entries[len(entries)-2]
+	latest := entries[len(entries)-1]
+
+	newContext := func(method, body, id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := r.NewContext(req, rec)
+		c.Set("lang", "en")
+		c.Set("userId", user.Id)
+		if id != "" {
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+		}
+		return c, rec
+	}
+
+	c, rec := newContext(http.MethodPut, `{"title": "Typo", "denominations": {"eur1": 1}}`, oldest.Id)
+	assert.NoError(t, handler.UpdateCashLogEntry(c))
+	assert.Contains(t, rec.Body.String(), `"message":"Only the most recent cash log entries can be changed, add a correction entry instead"`)
+
+	c, rec = newContext(http.MethodPut, `{"title": "Typo", "denominations": {"eur10": 1}}`, second.Id)
+	assert.NoError(t, handler.UpdateCashLogEntry(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"amount":1000`)
+
+	entry, _ := us.GetCashLogEntryById(user, second.Id)
+	assert.Equal(t, 1000-second.TotalAmount+100, entry.ChangeDifference)
+	entry, _ = us.GetCashLogEntryById(user, latest.Id)
+	assert.Equal(t, latest.TotalAmount-1000, entry.ChangeDifference)
+
+	c, rec = newContext(http.MethodDelete, "", second.Id)
+	assert.NoError(t, handler.DeleteCashLogEntry(c))
+	assert.Contains(t, rec.Body.String(), `"message":"Successfully deleted cash log entry"`)
+
+	entry, _ = us.GetCashLogEntryById(user, latest.Id)
+	assert.Equal(t, 200, entry.ChangeDifference)
+
+	c, rec = newContext(http.MethodGet, "", second.Id)
+	assert.NoError(t, handler.GetCashLogEntryRevisions(c))
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"action":"update","title":"Count","description":"","currency":"EUR","denominations":[{"key":"ct1","value":1,"count":0},{"key":"ct2","value":2,"count":0},{"key":"ct5","value":5,"count":0},{"key":"ct10","value":10,"count":0},{"key":"ct20","value":20,"count":0},{"key":"ct50","value":50,"count":0},{"key":"eur1","value":100,"count":%d}`, len(entries)-1))
+	assert.Contains(t, rec.Body.String(), `"action":"delete","title":"Typo"`)
+
+	c, rec = newContext(http.MethodPost, fmt.Sprintf(`{"title": "Correction", "correctionOfId": "%s", "denominations": {"eur1": 1}}`, oldest.Id), "")
+	assert.NoError(t, handler.AddCashLogEntry(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
@@ -1,17 +1,66 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
 	"github.com/juho05/h-bank/services"
 )
 
+var (
+	ErrNotEnoughMoney = errors.New("not enough money")
+	ErrGroupArchived  = errors.New("group is archived")
+	ErrNotInGroup     = errors.New("user is not in the group")
+
+	ErrTransactionReversed   = errors.New("transaction was already reversed")
+	ErrTransactionIsReversal = errors.New("transaction is a reversal")
+	ErrSavingsGoalTransfer   = errors.New("transaction is a transfer between a member and their savings goal")
+	ErrCashTransaction       = errors.New("transaction is linked to a cash log entry")
+
+	ErrPaymentRequestHandled     = errors.New("payment request was already approved or declined")
+	ErrPendingTransactionHandled = errors.New("pending transaction was already approved or rejected")
+	ErrSpendingLimitExceeded     = errors.New("spending limit exceeded")
+
+	ErrPaymentPlanAlreadyExecuted = errors.New("payment plan was already executed")
+	ErrPaymentPlanPaused          = errors.New("payment plan is paused")
+)
+
+const SpendingLimitTransaction = "transaction"
+
+type SpendingLimitError struct {
+	// SpendingLimitTransaction, ScheduleUnitDay, ScheduleUnitWeek or ScheduleUnitMonth
+	Limit string
+	Max   int
+}
+
+func (e *SpendingLimitError) Error() string {
+	return fmt.Sprintf("spending limit per %s exceeded (max %d)", e.Limit, e.Max)
+}
+
+func (e *SpendingLimitError) Unwrap() error {
+	return ErrSpendingLimitExceeded
+}
+
 type GroupStore interface {
 	GetAllByUser(user *User, page, pageSize int, descending bool) ([]Group, error)
 	Count(user *User) (int64, error)
 	GetById(id string) (*Group, error)
 	Create(group *Group) error
 	Update(group *Group) error
+	// Changes the time zone of the group and moves its payment plans so that they keep their local dates and times.
+	SetTimeZone(group *Group, timeZone string) error
 	Delete(group *Group) error
 	DeleteById(id string) error
+	// Archived groups are read-only: no transactions, payment plan executions or invitations.
+	SetArchived(group *Group, archived bool) error
+	SetApprovalPolicy(group *Group, threshold int, approveBankTransfers bool) error
+	// Schedules the first payout for the end of the current period.
+	SetInterest(group *Group, rate int, period string, minBalance int) error
+	GetGroupsWithDueInterest() ([]Group, error)
+	// Pays the interest for all periods which ended before now and returns the number of paid periods.
+	PayInterest(group *Group) (int, error)
 
 	GetGroupPicture(group *Group, size services.PictureSize) ([]byte, error)
 	UpdateGroupPicture(group *Group, pic *GroupPicture) error
@@ -27,6 +76,7 @@ type GroupStore interface {
 	IsAdmin(group *Group, user *User) (bool, error)
 	AddAdmin(group *Group, user *User) error
 	RemoveAdmin(group *Group, user *User) error
+	GetGroupsWithSoleAdmin(user *User) ([]Group, error)
 
 	GetMemberships(except *User, searchInput string, group *Group, page, pageSize int, descending bool) ([]GroupMembership, error)
 	MembershipCount(group *Group) (int64, error)
@@ -38,11 +88,48 @@ type GroupStore interface {
 	TransactionLogEntryCount(group *Group, user *User) (int64, error)
 	GetBankTransactionLog(group *Group, searchInput string, page, pageSize int, oldestFirst bool) ([]TransactionLogEntry, error)
 	BankTransactionLogEntryCount(group *Group) (int64, error)
+	// Calls handle with batches of the entries of the user (the bank if user is nil) created in [from, to), oldest first.
+	// to = 0 means no upper limit.
+	ExportTransactionLog(group *Group, user *User, from, to int64, handle func([]TransactionLogEntry) error) error
 	GetTransactionLogEntryById(group *Group, id string) (*TransactionLogEntry, error)
 	GetLastTransactionLogEntry(group *Group, user *User) (*TransactionLogEntry, error)
 	GetUserBalance(group *Group, user *User) (int, error)
 	CreateTransaction(group *Group, senderIsBank, receiverIsBank bool, sender *User, receiver *User, title, description string, amount int) (*TransactionLogEntry, error)
 	CreateTransactionFromPaymentPlan(group *Group, senderIsBank, receiverIsBank bool, sender *User, receiver *User, title, description string, amount int, paymentPlanId string) (*TransactionLogEntry, error)
+	// Applies the transactions to the current balances in chronological order and backdates them to their time.
+	// The new balances of later transactions of the affected members are recomputed.
+	// Returns the errors of failed transactions by index. Nothing is stored if dryRun is true or any transaction failed.
+	ImportTransactions(group *Group, transactions []TransactionImport, dryRun bool) (map[int]error, error)
+	// Creates a transaction between the member and the bank and a cash log entry in the wallet of the member which reference each other.
+	// A payout moves the value of the denominations from the balance to the wallet, a deposit from the wallet to the balance.
+	// The spending limits of the member are only enforced if checkLimits is true.
+	CreateCashTransaction(group *Group, user *User, payout bool, title, description, walletId string, denominations []CashDenominationCount, checkLimits bool) (*TransactionLogEntry, *CashLogEntry, error)
+	GetReservedAmount(group *Group, user *User) (int, error)
+	// Returns nil if the user is not in the group.
+	GetSpendingLimits(group *Group, user *User) (*SpendingLimits, error)
+	SetSpendingLimits(group *Group, user *User, limits SpendingLimits) error
+	// Reserves the amount on the balance of the sender until an admin approves or rejects the transaction.
+	CreatePendingTransaction(group *Group, sender *User, receiverIsBank bool, receiver *User, title, description string, amount int) (*PendingTransaction, error)
+	GetPendingTransactionById(group *Group, id string) (*PendingTransaction, error)
+	// Returns the pending transactions of all members if user is nil.
+	GetPendingTransactions(group *Group, user *User, page, pageSize int, oldestFirst bool) ([]PendingTransaction, error)
+	PendingTransactionCount(group *Group, user *User) (int64, error)
+	ApprovePendingTransaction(group *Group, pending *PendingTransaction) (*TransactionLogEntry, error)
+	RejectPendingTransaction(group *Group, pending *PendingTransaction) error
+	// Creates a counter-transaction which sends the amount of the transaction back from the receiver to the sender.
+	ReverseTransaction(group *Group, transaction *TransactionLogEntry) (*TransactionLogEntry, error)
+
+	GetSavingsGoals(group *Group, user *User, page, pageSize int, oldestFirst bool) ([]SavingsGoal, error)
+	SavingsGoalCount(group *Group, user *User) (int64, error)
+	GetSavingsGoalById(group *Group, user *User, id string) (*SavingsGoal, error)
+	CreateSavingsGoal(group *Group, user *User, name string, target int, deadline int64) (*SavingsGoal, error)
+	UpdateSavingsGoal(goal *SavingsGoal) error
+	// Moves the remaining balance of the goal back to the main balance of the member before deleting it.
+	DeleteSavingsGoal(group *Group, goal *SavingsGoal) error
+	// Moves money from the main balance of the member to the goal.
+	DepositToSavingsGoal(group *Group, goal *SavingsGoal, amount int) (*TransactionLogEntry, error)
+	// Moves money from the goal back to the main balance of the member.
+	WithdrawFromSavingsGoal(group *Group, goal *SavingsGoal, amount int) (*TransactionLogEntry, error)
 
 	CreateInvitation(group *Group, user *User, message string) (*GroupInvitation, error)
 	GetInvitationById(id string) (*GroupInvitation, error)
@@ -53,17 +140,49 @@ type GroupStore interface {
 	GetInvitationByGroupAndUser(group *Group, user *User) (*GroupInvitation, error)
 	DeleteInvitation(invitation *GroupInvitation) error
 
+	CreatePaymentRequest(group *Group, requester *User, payerIsBank bool, payer *User, title, description string, amount int) (*PaymentRequest, error)
+	GetPaymentRequestById(id string) (*PaymentRequest, error)
+	GetIncomingPaymentRequests(user *User, page, pageSize int, oldestFirst bool) ([]PaymentRequest, error)
+	IncomingPaymentRequestCount(user *User) (int64, error)
+	GetOutgoingPaymentRequests(user *User, page, pageSize int, oldestFirst bool) ([]PaymentRequest, error)
+	OutgoingPaymentRequestCount(user *User) (int64, error)
+	GetBankPaymentRequests(group *Group, page, pageSize int, oldestFirst bool) ([]PaymentRequest, error)
+	BankPaymentRequestCount(group *Group) (int64, error)
+	// Creates the requested transaction and deletes the payment request.
+	ApprovePaymentRequest(group *Group, request *PaymentRequest) (*TransactionLogEntry, error)
+	ApprovePaymentRequestPending(group *Group, request *PaymentRequest) (*PendingTransaction, error)
+	DeletePaymentRequest(request *PaymentRequest) error
+
 	GetPaymentPlans(group *Group, user *User, searchInput string, page, pageSize int, descending bool) ([]PaymentPlan, error)
 	PaymentPlanCount(group *Group, user *User) (int64, error)
 	GetBankPaymentPlans(group *Group, searchInput string, page, pageSize int, descending bool) ([]PaymentPlan, error)
 	BankPaymentPlanCount(group *Group) (int64, error)
 	GetPaymentPlansThatNeedToBeExecuted() ([]PaymentPlan, error)
 	GetPaymentPlanById(group *Group, id string) (*PaymentPlan, error)
-	CreatePaymentPlan(group *Group, senderIsBank, receiverIsBank bool, sender *User, receiver *User, name, description string, amount, repeats, schedule int, scheduleUnit string, firstPayment int64) (*PaymentPlan, error)
+	CreatePaymentPlan(group *Group, senderIsBank, receiverIsBank bool, sender *User, receiver *User, name, description string, amount, repeats, schedule int, scheduleUnit, scheduleRule string, weekdaysOnly bool, firstPayment, endDate int64, failurePolicy string) (*PaymentPlan, error)
 	UpdatePaymentPlan(paymentPlan *PaymentPlan) error
 	DeletePaymentPlan(paymentPlan *PaymentPlan) error
+	// Pays the occurrence at paymentPlan.NextExecute and advances the schedule of paymentPlan in one database transaction.
+	// The plan is deleted once its payment count reaches 0.
+	// Returns ErrPaymentPlanAlreadyExecuted if the occurrence was already paid.
+	ExecutePaymentPlan(paymentPlan *PaymentPlan) (*TransactionLogEntry, error)
+	// Records a failed attempt to pay the occurrence at paymentPlan.NextExecute and applies the failure policy of paymentPlan.
+	RecordPaymentPlanFailure(paymentPlan *PaymentPlan, status, message string) (*PaymentPlanExecution, error)
+	// Pauses or resumes the payment plan. Resumed plans pay all missed payments if catchUp is true,
+	// otherwise they continue with the next payment in the future.
+	SetPaymentPlanPaused(paymentPlan *PaymentPlan, paused, catchUp bool) error
+	// Moves the next payment of the plan count payments ahead without paying the skipped payments.
+	SkipPaymentPlanPayments(paymentPlan *PaymentPlan, count int) error
+	GetPaymentPlanExecutions(paymentPlan *PaymentPlan, page, pageSize int, oldestFirst bool) ([]PaymentPlanExecution, error)
+	PaymentPlanExecutionCount(paymentPlan *PaymentPlan) (int64, error)
+
+	// Acquires or renews the lease with the given name for holder. Returns false if another holder owns an unexpired lease.
+	AcquireLease(name, holder string, duration time.Duration) (bool, error)
+	ReleaseLease(name, holder string) error
 
 	GetTotalMoney(group *Group) (int, error)
+	// Compares the stored balances with the transaction log (all groups if group is nil).
+	GetBalanceDrifts(group *Group) ([]BalanceDrift, error)
 
 	AreInSameGroup(userId1, userId2 string) (bool, error)
 }
@@ -74,11 +193,42 @@ type Group struct {
 	Description    string
 	GroupPicture   *GroupPicture `gorm:"constraint:OnDelete:CASCADE"`
 	GroupPictureId string
+	Archived       bool
+	Currency       string `gorm:"not null;default:'EUR'"`
+	// IANA time zone of new payment plans
+	TimeZone string `gorm:"not null;default:'UTC'"`
+
+	// Transactions of members with an amount of at least ApprovalThreshold (0 = disabled)
+	// need to be approved by an admin.
+	ApprovalThreshold    int  `gorm:"not null;default:0"`
+	ApproveBankTransfers bool `gorm:"not null;default:false"`
+
+	Interest Interest `gorm:"embedded;embeddedPrefix:interest_"`
 
 	Memberships []GroupMembership
 	Invitations []GroupInvitation
 }
 
+// Returns the location of TimeZone or UTC if it is invalid.
+func (g *Group) Location() *time.Location {
+	location, err := time.LoadLocation(g.TimeZone)
+	if err != nil {
+		log.Printf("Error: invalid time zone '%s' of group '%s': %s", g.TimeZone, g.Id, err)
+		return time.UTC
+	}
+	return location
+}
+
+// Interest is paid by the bank to all members at the end of every period.
+type Interest struct {
+	// Annual rate in basis points (1/100 %), 0 disables interest.
+	Rate int `gorm:"not null;default:0"`
+	// ScheduleUnitDay, ScheduleUnitWeek, ScheduleUnitMonth or ScheduleUnitYear
+	Period     string `gorm:"not null;default:''"`
+	MinBalance int    `gorm:"not null;default:0"`
+	NextPayout int64  `gorm:"not null;default:0"`
+}
+
 type GroupPicture struct {
 	Base
 
@@ -99,6 +249,30 @@ type GroupMembership struct {
 	UserName  string
 	IsMember  bool
 	IsAdmin   bool
+	Balance   int
+	// Sum of the pending transactions of the user which cannot be spent until they are approved or rejected.
+	Reserved int `gorm:"not null;default:0"`
+	// Interest which was earned but not paid yet because it is smaller than 1 cent, in cents/(10000 * interest payouts per year).
+	InterestRemainder int `gorm:"not null;default:0"`
+
+	SpendingLimits SpendingLimits `gorm:"embedded"`
+}
+
+// Limits of 0 are disabled.
+type SpendingLimits struct {
+	MaxPerTransaction int `gorm:"not null;default:0"`
+	MaxPerDay         int `gorm:"not null;default:0"`
+	MaxPerWeek        int `gorm:"not null;default:0"`
+	MaxPerMonth       int `gorm:"not null;default:0"`
+	// The balance of the member may go down to -Overdraft.
+	Overdraft int `gorm:"not null;default:0"`
+}
+
+type BalanceDrift struct {
+	GroupId    string
+	UserId     string
+	Balance    int
+	LogBalance int
 }
 
 type GroupInvitation struct {
@@ -109,6 +283,47 @@ type GroupInvitation struct {
 	UserId    string
 }
 
+// A savings goal sets money aside from the main balance of a member.
+type SavingsGoal struct {
+	Base
+	Name   string
+	Target int
+	// 0 for goals without a deadline
+	Deadline int64
+	Balance  int
+
+	GroupId string
+	UserId  string
+}
+
+type PaymentRequest struct {
+	Base
+	Title       string
+	Description string
+	Amount      int
+
+	GroupId string
+
+	RequesterId string
+
+	PayerIsBank bool
+	PayerId     string
+}
+
+type PendingTransaction struct {
+	Base
+	Title       string
+	Description string
+	Amount      int
+
+	GroupId string
+
+	SenderId string
+
+	ReceiverIsBank bool
+	ReceiverId     string
+}
+
 type TransactionLogEntry struct {
 	Base
 	Title       string
@@ -128,6 +343,41 @@ type TransactionLogEntry struct {
 	BalanceDifferenceReceiver int
 
 	PaymentPlanId string
+
+	// Set on the counter-transaction created by a reversal.
+	ReversalOfId string
+	// Set on the original transaction once it has been reversed.
+	ReversedById string
+
+	// Set on transfers between the main balance of a member and one of their savings goals.
+	// Sender and receiver are the member, only the side which changed the main balance has a balance difference.
+	SavingsGoalId string
+
+	// Set on cash payouts and deposits to the id of the cash log entry of the member.
+	CashLogEntryId string `gorm:"not null;default:''"`
+
+	// Set on transactions created by h-bank itself, which are stored without a title
+	// so that their title can be translated (TransactionKindAccountDeleted, TransactionKindInterest).
+	Kind string `gorm:"not null;default:''"`
+}
+
+const (
+	// Payout or deposit of the balance of a member who deleted their account
+	TransactionKindAccountDeleted = "accountDeleted"
+	// Interest paid by the bank
+	TransactionKindInterest = "interest"
+)
+
+type TransactionImport struct {
+	Time        int64
+	Title       string
+	Description string
+	Amount      int
+
+	SenderIsBank   bool
+	Sender         *User
+	ReceiverIsBank bool
+	Receiver       *User
 }
 
 const (
@@ -137,6 +387,34 @@ const (
 	ScheduleUnitYear  = "year"
 )
 
+// Day of the month of monthly and yearly payment plans.
+const (
+	// The day of the month of the first payment or the last day of shorter months.
+	ScheduleRuleDate = "date"
+	// The last day of the month.
+	ScheduleRuleLastDay = "lastDay"
+	// The weekday of the first payment in the same week of the month (e.g. every 2nd friday).
+	ScheduleRuleWeekday = "weekday"
+	// The last occurrence of the weekday of the first payment in the month (e.g. every last friday).
+	ScheduleRuleLastWeekday = "lastWeekday"
+)
+
+// What happens to a payment plan when an occurrence cannot be paid.
+const (
+	// The occurrence is retried until it can be paid.
+	PaymentPlanFailureRetry = "retry"
+	// The occurrence is skipped without being paid.
+	PaymentPlanFailureSkip = "skip"
+	// The plan is paused until it is resumed.
+	PaymentPlanFailurePause = "pause"
+)
+
+const (
+	PaymentPlanExecutionSuccess           = "success"
+	PaymentPlanExecutionInsufficientFunds = "insufficientFunds"
+	PaymentPlanExecutionError             = "error"
+)
+
 type PaymentPlan struct {
 	Base
 	Name        string
@@ -158,4 +436,71 @@ type PaymentPlan struct {
 	ReceiverId     string
 
 	GroupId string
+
+	// PaymentPlanFailureRetry, PaymentPlanFailureSkip or PaymentPlanFailurePause
+	FailurePolicy string `gorm:"not null;default:'retry'"`
+	// Paused plans are not executed.
+	Paused bool `gorm:"not null;default:false"`
+
+	// ScheduleRuleDate, ScheduleRuleLastDay, ScheduleRuleWeekday or ScheduleRuleLastWeekday
+	ScheduleRule string `gorm:"not null;default:'date'"`
+	// Payments on saturdays and sundays are moved to the next monday. Daily plans only count weekdays.
+	WeekdaysOnly bool `gorm:"not null;default:false"`
+	// All payments are calculated from the first payment to avoid drift.
+	FirstPayment int64 `gorm:"not null;default:0"`
+	// No payments are made after EndDate, 0 for no end date.
+	EndDate int64 `gorm:"not null;default:0"`
+	// IANA time zone of the group in which the days and the time of day of FirstPayment are kept
+	TimeZone string `gorm:"not null;default:'UTC'"`
+}
+
+func (p *PaymentPlan) PaymentSchedule() services.Schedule {
+	return services.Schedule{
+		Start:        p.FirstPayment,
+		Interval:     p.Schedule,
+		Unit:         p.ScheduleUnit,
+		Rule:         p.ScheduleRule,
+		WeekdaysOnly: p.WeekdaysOnly,
+		Location:     p.Location(),
+	}
+}
+
+// Returns the location of TimeZone or UTC if it is invalid.
+func (p *PaymentPlan) Location() *time.Location {
+	location, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		log.Printf("Error: invalid time zone '%s' of payment plan '%s': %s", p.TimeZone, p.Id, err)
+		return time.UTC
+	}
+	return location
+}
+
+// Reports whether the plan has made all of its payments.
+func (p *PaymentPlan) Finished() bool {
+	return p.PaymentCount == 0 || (p.EndDate > 0 && p.NextExecute > p.EndDate)
+}
+
+// Records the execution of one occurrence of a payment plan.
+// PaymentPlanId and Scheduled are unique so that an occurrence can never be paid twice.
+type PaymentPlanExecution struct {
+	Base
+	PaymentPlanId string `gorm:"uniqueIndex:idx_payment_plan_execution"`
+	// NextExecute of the payment plan when it was executed
+	Scheduled     int64 `gorm:"uniqueIndex:idx_payment_plan_execution"`
+	GroupId       string
+	TransactionId string
+
+	// PaymentPlanExecutionSuccess, PaymentPlanExecutionInsufficientFunds or PaymentPlanExecutionError of the last attempt
+	Status  string `gorm:"not null;default:'success'"`
+	Message string `gorm:"not null;default:''"`
+	// Failed occurrences are attempted again with PaymentPlanFailureRetry.
+	Attempts    int   `gorm:"not null;default:1"`
+	LastAttempt int64 `gorm:"not null;default:0"`
+}
+
+// Only the holder of a lease runs the jobs protected by it until the lease expires.
+type Lease struct {
+	Name    string `gorm:"primaryKey"`
+	Holder  string
+	Expires int64
 }
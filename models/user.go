@@ -1,5 +1,45 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Replaces the id of deleted users in transaction logs.
+const DeletedUserId = "deleted"
+
+// Id of the wallet every user has, which is not stored in the cash_wallets table.
+const DefaultCashWalletId = ""
+
+var (
+	ErrNotEnoughCash    = errors.New("not enough cash")
+	ErrCurrencyMismatch = errors.New("currencies don't match")
+
+	ErrCashLogEntryNotEditable = errors.New("cash log entry is too old to be changed")
+	ErrCashLogEntryLinked      = errors.New("cash log entry is linked to another entry or transaction")
+)
+
+var ErrSoleGroupAdmin = errors.New("user is the only admin of a group with other members")
+
+// Returned when a user who is the only admin of groups with other members is deleted.
+type SoleGroupAdminError struct {
+	GroupIds []string
+}
+
+func (e *SoleGroupAdminError) Error() string {
+	return fmt.Sprintf("user is the only admin of the groups %s", strings.Join(e.GroupIds, ", "))
+}
+
+func (e *SoleGroupAdminError) Unwrap() error {
+	return ErrSoleGroupAdmin
+}
+
+const (
+	CashLogEntryRevisionUpdate = "update"
+	CashLogEntryRevisionDelete = "delete"
+)
+
 type UserStore interface {
 	GetAll(exclude []string, searchInput string, page, pageSize int, descending bool) ([]User, error)
 	Count() (int64, error)
@@ -7,15 +47,37 @@ type UserStore interface {
 	GetByEmail(email string) (*User, error)
 	Create(user *User) error
 	Update(user *User) error
+	// Groups in which the user is the only user are deleted together with the user.
+	// Returns a *SoleGroupAdminError if the user is the only admin of groups with other members.
 	Delete(user *User) error
 	DeleteById(id string) error
 	DeleteByEmail(email string) error
 
-	GetCashLog(user *User, searchInput string, page, pageSize int, oldestFirst bool) ([]CashLogEntry, error)
-	CashLogEntryCount(user *User) (int64, error)
-	GetLastCashLogEntry(user *User) (*CashLogEntry, error)
+	GetCashLog(user *User, walletId, searchInput string, page, pageSize int, oldestFirst bool) ([]CashLogEntry, error)
+	CashLogEntryCount(user *User, walletId string) (int64, error)
+	GetLastCashLogEntry(user *User, walletId string) (*CashLogEntry, error)
 	GetCashLogEntryById(user *User, id string) (*CashLogEntry, error)
+	// Adds the entry to the wallet with the id entry.WalletId.
 	AddCashLogEntry(user *User, entry *CashLogEntry) error
+	// Only the config.Data.EditableCashLogEntries most recent entries of a wallet can be updated or deleted.
+	// The original values are stored as a revision and the differences of the following entries are recomputed.
+	UpdateCashLogEntry(user *User, entry *CashLogEntry, title, description string, denominations []CashDenominationCount) error
+	DeleteCashLogEntry(user *User, entry *CashLogEntry) error
+	// Inserts the entries (with Created set to their time) into the log of the wallet entry.WalletId.
+	ImportCashLog(user *User, walletId string, entries []CashLogEntry) error
+	// Returns the revisions of the entry (which may already be deleted), oldest first.
+	GetCashLogEntryRevisions(user *User, entryId string) ([]CashLogEntryRevision, error)
+
+	GetCashWallets(user *User) ([]CashWallet, error)
+	GetCashWalletById(user *User, id string) (*CashWallet, error)
+	CreateCashWallet(user *User, name, currency string) (*CashWallet, error)
+	UpdateCashWallet(wallet *CashWallet) error
+	// Deletes the wallet and its cash log.
+	DeleteCashWallet(wallet *CashWallet) error
+	// Adds an entry to both wallets which removes the denominations from one wallet and adds them to the other.
+	MoveCash(user *User, fromWalletId, toWalletId, title, description string, denominations []CashDenominationCount) (from *CashLogEntry, to *CashLogEntry, err error)
+	// Returns the sum of the current cash of all wallets by currency.
+	GetCashTotals(user *User) (map[string]int, error)
 }
 
 type User struct {
@@ -27,6 +89,9 @@ type User struct {
 	CashLog                 []CashLogEntry
 	GroupMemberships        []GroupMembership
 	GroupInvitations        []GroupInvitation
+
+	// Currency of new cash log entries
+	CashCurrency string `gorm:"not null;default:'EUR'"`
 }
 
 type CashLogEntry struct {
@@ -36,22 +101,53 @@ type CashLogEntry struct {
 	TotalAmount       int
 	ChangeDifference  int
 
-	Ct1  int
-	Ct2  int
-	Ct5  int
-	Ct10 int
-	Ct20 int
-	Ct50 int
-
-	Eur1   int
-	Eur2   int
-	Eur5   int
-	Eur10  int
-	Eur20  int
-	Eur50  int
-	Eur100 int
-	Eur200 int
-	Eur500 int
+	Currency string `gorm:"not null;default:'EUR'"`
+	// Only contains denominations with a count greater than 0.
+	Denominations []CashDenominationCount `gorm:"constraint:OnDelete:CASCADE"`
 
 	UserId string
+	// DefaultCashWalletId for the default wallet
+	WalletId string `gorm:"not null;default:''"`
+	// Set on both entries of a move between wallets to the id of the other entry.
+	MoveEntryId string `gorm:"not null;default:''"`
+
+	// Set on cash payouts and deposits to the group transaction which changed the balance of the user.
+	TransactionId      string `gorm:"not null;default:''"`
+	TransactionGroupId string `gorm:"not null;default:''"`
+
+	// Set on entries which correct an entry which was too old to be changed.
+	CorrectionOfId string `gorm:"not null;default:''"`
+}
+
+// Values of a cash log entry before it was updated or deleted.
+type CashLogEntryRevision struct {
+	Base
+	CashLogEntryId string
+	UserId         string
+	WalletId       string
+	// CashLogEntryRevisionUpdate or CashLogEntryRevisionDelete
+	Action string
+
+	ChangeTitle       string
+	ChangeDescription string
+	Currency          string
+	TotalAmount       int
+	ChangeDifference  int
+	// JSON object of the denomination counts by key
+	Denominations string
+}
+
+type CashWallet struct {
+	Base
+	Name     string
+	Currency string
+	UserId   string
+}
+
+type CashDenominationCount struct {
+	Base
+	CashLogEntryId string
+	// Key of the denomination in services.Currencies
+	Key   string
+	Count int
 }
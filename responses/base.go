@@ -60,3 +60,37 @@ func NewNotFound(lang string) Base {
 func NewInvalidRequestBody(lang string) Base {
 	return New(false, "Invalid request body", lang)
 }
+
+type ImportRowError struct {
+	// Line number in the CSV file (the header is line 1)
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportResult struct {
+	Base
+	DryRun   bool             `json:"dryRun"`
+	RowCount int              `json:"rowCount"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// The import was only committed if dryRun is false and there are no errors.
+func NewImportResult(dryRun bool, rowCount int, rowErrors []ImportRowError, lang string) interface{} {
+	if rowErrors == nil {
+		rowErrors = []ImportRowError{}
+	}
+
+	message := "Successfully imported all rows"
+	if len(rowErrors) > 0 {
+		message = "Some rows are invalid, nothing was imported"
+	} else if dryRun {
+		message = "All rows are valid"
+	}
+
+	return ImportResult{
+		Base:     New(len(rowErrors) == 0, message, lang),
+		DryRun:   dryRun,
+		RowCount: rowCount,
+		Errors:   rowErrors,
+	}
+}
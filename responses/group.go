@@ -1,7 +1,8 @@
 package responses
 
 import (
-	"github.com/google/uuid"
+	"fmt"
+	"time"
 
 	"github.com/juho05/h-bank/models"
 	"github.com/juho05/h-bank/services"
@@ -9,7 +10,11 @@ import (
 
 type Balance struct {
 	Base
-	Balance int `json:"balance"`
+	Balance  int `json:"balance"`
+	Reserved int `json:"reserved"`
+
+	Currency         string `json:"currency"`
+	FormattedBalance string `json:"formattedBalance"`
 }
 
 type DeleteFailedBecauseOfSoleGroupAdmin struct {
@@ -27,6 +32,8 @@ type group struct {
 	Name           string `json:"name"`
 	Description    string `json:"description"`
 	GroupPictureId string `json:"groupPictureId"`
+	Archived       bool   `json:"archived"`
+	Currency       string `json:"currency"`
 }
 
 type groupDetailed struct {
@@ -34,8 +41,20 @@ type groupDetailed struct {
 	Name           string `json:"name"`
 	Description    string `json:"description"`
 	GroupPictureId string `json:"groupPictureId"`
+	Archived       bool   `json:"archived"`
+	Currency       string `json:"currency"`
+	TimeZone       string `json:"timeZone"`
 	Member         bool   `json:"member"`
 	Admin          bool   `json:"admin"`
+
+	ApprovalThreshold    int  `json:"approvalThreshold"`
+	ApproveBankTransfers bool `json:"approveBankTransfers"`
+
+	// Annual interest rate in percent
+	AnnualInterestRate float64 `json:"annualInterestRate"`
+	InterestPeriod     string  `json:"interestPeriod,omitempty"`
+	InterestMinBalance int     `json:"interestMinBalance"`
+	NextInterestPayout int64   `json:"nextInterestPayout,omitempty"`
 }
 
 type transaction struct {
@@ -49,10 +68,25 @@ type transaction struct {
 	Amount     int `json:"amount"`
 	NewBalance int `json:"newBalance"`
 
+	Currency        string `json:"currency"`
+	FormattedAmount string `json:"formattedAmount"`
+
 	SenderId   string `json:"senderId"`
 	ReceiverId string `json:"receiverId"`
 
 	PaymentPlanId string `json:"paymentPlanId,omitempty"`
+
+	ReversalOfId string `json:"reversalOfId,omitempty"`
+	ReversedById string `json:"reversedById,omitempty"`
+
+	SavingsGoalId string `json:"savingsGoalId,omitempty"`
+	// true if the money was moved to the savings goal, false if it was moved back to the main balance
+	SavingsGoalDeposit bool `json:"savingsGoalDeposit,omitempty"`
+
+	CashLogEntryId string `json:"cashLogEntryId,omitempty"`
+
+	// models.TransactionKind… of transactions created by h-bank itself
+	Kind string `json:"kind,omitempty"`
 }
 
 type bankTransaction struct {
@@ -62,12 +96,41 @@ type bankTransaction struct {
 	Description string `json:"description,omitempty"`
 	Amount      int    `json:"amount"`
 
+	Currency        string `json:"currency"`
+	FormattedAmount string `json:"formattedAmount"`
+
 	GroupId string `json:"groupId"`
 
 	SenderId   string `json:"senderId"`
 	ReceiverId string `json:"receiverId"`
 
 	PaymentPlanId string `json:"paymentPlanId,omitempty"`
+
+	ReversalOfId string `json:"reversalOfId,omitempty"`
+	ReversedById string `json:"reversedById,omitempty"`
+
+	CashLogEntryId string `json:"cashLogEntryId,omitempty"`
+
+	// models.TransactionKind… of transactions created by h-bank itself
+	Kind string `json:"kind,omitempty"`
+}
+
+// Row of a transaction log export. Amount is signed from the perspective of the exported log.
+type TransactionExportRow struct {
+	Id          string `json:"id"`
+	Time        int64  `json:"time"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	SenderId     string `json:"senderId"`
+	SenderName   string `json:"senderName"`
+	ReceiverId   string `json:"receiverId"`
+	ReceiverName string `json:"receiverName"`
+
+	Amount int `json:"amount"`
+	// Only set in the log of a member
+	NewBalance *int   `json:"newBalance,omitempty"`
+	Currency   string `json:"currency"`
 }
 
 type paymentPlan struct {
@@ -80,13 +143,35 @@ type paymentPlan struct {
 
 	Schedule     int    `json:"schedule"`
 	ScheduleUnit string `json:"scheduleUnit"`
+	ScheduleRule string `json:"scheduleRule"`
+	WeekdaysOnly bool   `json:"weekdaysOnly"`
+	EndDate      int64  `json:"endDate,omitempty"`
+	TimeZone     string `json:"timeZone"`
+	// Local time of the payments with format "HH:MM"
+	TimeOfDay string `json:"timeOfDay"`
 
 	GroupId string `json:"groupId"`
 
 	Amount int `json:"amount"`
 
+	Currency        string `json:"currency"`
+	FormattedAmount string `json:"formattedAmount"`
+
 	SenderId   string `json:"senderId,omitempty"`
 	ReceiverId string `json:"receiverId,omitempty"`
+
+	FailurePolicy string `json:"failurePolicy"`
+	Paused        bool   `json:"paused"`
+}
+
+type paymentPlanExecution struct {
+	Id            string `json:"id"`
+	Scheduled     int64  `json:"scheduled"`
+	Status        string `json:"status"`
+	Message       string `json:"message,omitempty"`
+	Attempts      int    `json:"attempts"`
+	LastAttempt   int64  `json:"lastAttempt"`
+	TransactionId string `json:"transactionId,omitempty"`
 }
 
 type invitation struct {
@@ -98,6 +183,42 @@ type invitation struct {
 	UserId            string `json:"userId,omitempty"`
 }
 
+type pendingTransaction struct {
+	Id          string `json:"id"`
+	Time        int64  `json:"time"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Amount      int    `json:"amount"`
+	GroupId     string `json:"groupId"`
+	SenderId    string `json:"senderId"`
+	ReceiverId  string `json:"receiverId"`
+}
+
+type paymentRequest struct {
+	Id          string `json:"id"`
+	Created     int64  `json:"created"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Amount      int    `json:"amount"`
+	GroupId     string `json:"groupId"`
+	RequesterId string `json:"requesterId"`
+	PayerId     string `json:"payerId"`
+}
+
+type savingsGoal struct {
+	Id       string `json:"id"`
+	Created  int64  `json:"created"`
+	Name     string `json:"name"`
+	Target   int    `json:"target"`
+	Deadline int64  `json:"deadline,omitempty"`
+	GroupId  string `json:"groupId"`
+
+	Balance   int `json:"balance"`
+	Remaining int `json:"remaining"`
+	// 0-100
+	Progress int `json:"progress"`
+}
+
 type groupUser struct {
 	Id               string `json:"id"`
 	Name             string `json:"name"`
@@ -132,6 +253,214 @@ func NewInvitations(invitations []models.GroupInvitation, count int64) interface
 	}
 }
 
+func newPendingTransactionDTO(pendingModel *models.PendingTransaction) pendingTransaction {
+	dto := pendingTransaction{
+		Id:          pendingModel.Id,
+		Time:        pendingModel.Created,
+		Title:       pendingModel.Title,
+		Description: pendingModel.Description,
+		Amount:      pendingModel.Amount,
+		GroupId:     pendingModel.GroupId,
+		SenderId:    pendingModel.SenderId,
+		ReceiverId:  pendingModel.ReceiverId,
+	}
+	if pendingModel.ReceiverIsBank {
+		dto.ReceiverId = "bank"
+	}
+	return dto
+}
+
+var spendingLimitMessages = map[string]string{
+	models.SpendingLimitTransaction: "The transfer exceeds the spending limit per transaction (max %s)",
+	models.ScheduleUnitDay:          "The transfer exceeds the daily spending limit (max %s)",
+	models.ScheduleUnitWeek:         "The transfer exceeds the weekly spending limit (max %s)",
+	models.ScheduleUnitMonth:        "The transfer exceeds the monthly spending limit (max %s)",
+}
+
+func NewSpendingLimitExceeded(limitErr *models.SpendingLimitError, currency, lang string) interface{} {
+	type spendingLimitExceededResp struct {
+		Base
+		Limit string `json:"limit"`
+		Max   int    `json:"max"`
+	}
+
+	return spendingLimitExceededResp{
+		Base: Base{
+			Success: false,
+			Message: fmt.Sprintf(services.Tr(spendingLimitMessages[limitErr.Limit], lang), services.FormatMoney(limitErr.Max, currency, lang)),
+		},
+		Limit: limitErr.Limit,
+		Max:   limitErr.Max,
+	}
+}
+
+func NewSpendingLimits(limits *models.SpendingLimits) interface{} {
+	type spendingLimitsResp struct {
+		Base
+		MaxPerTransaction int `json:"maxPerTransaction"`
+		MaxPerDay         int `json:"maxPerDay"`
+		MaxPerWeek        int `json:"maxPerWeek"`
+		MaxPerMonth       int `json:"maxPerMonth"`
+		Overdraft         int `json:"overdraft"`
+	}
+
+	return spendingLimitsResp{
+		Base: Base{
+			Success: true,
+		},
+		MaxPerTransaction: limits.MaxPerTransaction,
+		MaxPerDay:         limits.MaxPerDay,
+		MaxPerWeek:        limits.MaxPerWeek,
+		MaxPerMonth:       limits.MaxPerMonth,
+		Overdraft:         limits.Overdraft,
+	}
+}
+
+func NewPendingTransactions(pendingTransactions []models.PendingTransaction, count int64) interface{} {
+	dtos := make([]pendingTransaction, len(pendingTransactions))
+	for i := range pendingTransactions {
+		dtos[i] = newPendingTransactionDTO(&pendingTransactions[i])
+	}
+
+	type pendingTransactionsResp struct {
+		Base
+		Count               int64                `json:"count"`
+		PendingTransactions []pendingTransaction `json:"pendingTransactions"`
+	}
+
+	return pendingTransactionsResp{
+		Base: Base{
+			Success: true,
+		},
+		Count:               count,
+		PendingTransactions: dtos,
+	}
+}
+
+// The transaction is only written to the transaction log once an admin approves it.
+func NewPendingTransaction(pendingModel *models.PendingTransaction, lang string) interface{} {
+	type pendingTransactionResp struct {
+		Base
+		pendingTransaction
+		Pending bool `json:"pending"`
+	}
+
+	return pendingTransactionResp{
+		Base: Base{
+			Success: true,
+			Message: services.Tr("The transaction needs to be approved by an admin", lang),
+		},
+		pendingTransaction: newPendingTransactionDTO(pendingModel),
+		Pending:            true,
+	}
+}
+
+func newPaymentRequestDTO(requestModel *models.PaymentRequest) paymentRequest {
+	dto := paymentRequest{
+		Id:          requestModel.Id,
+		Created:     requestModel.Created,
+		Title:       requestModel.Title,
+		Description: requestModel.Description,
+		Amount:      requestModel.Amount,
+		GroupId:     requestModel.GroupId,
+		RequesterId: requestModel.RequesterId,
+		PayerId:     requestModel.PayerId,
+	}
+	if requestModel.PayerIsBank {
+		dto.PayerId = "bank"
+	}
+	return dto
+}
+
+func NewPaymentRequests(requests []models.PaymentRequest, count int64) interface{} {
+	dtos := make([]paymentRequest, len(requests))
+	for i := range requests {
+		dtos[i] = newPaymentRequestDTO(&requests[i])
+	}
+
+	type paymentRequestsResp struct {
+		Base
+		Count           int64            `json:"count"`
+		PaymentRequests []paymentRequest `json:"paymentRequests"`
+	}
+
+	return paymentRequestsResp{
+		Base: Base{
+			Success: true,
+		},
+		Count:           count,
+		PaymentRequests: dtos,
+	}
+}
+
+func NewPaymentRequest(requestModel *models.PaymentRequest) interface{} {
+	type paymentRequestResp struct {
+		Base
+		paymentRequest
+	}
+
+	return paymentRequestResp{
+		Base: Base{
+			Success: true,
+		},
+		paymentRequest: newPaymentRequestDTO(requestModel),
+	}
+}
+
+func newSavingsGoalDTO(goalModel *models.SavingsGoal) savingsGoal {
+	dto := savingsGoal{
+		Id:       goalModel.Id,
+		Created:  goalModel.Created,
+		Name:     goalModel.Name,
+		Target:   goalModel.Target,
+		Deadline: goalModel.Deadline,
+		GroupId:  goalModel.GroupId,
+		Balance:  goalModel.Balance,
+	}
+	if goalModel.Balance < goalModel.Target {
+		dto.Remaining = goalModel.Target - goalModel.Balance
+		dto.Progress = goalModel.Balance * 100 / goalModel.Target
+	} else {
+		dto.Progress = 100
+	}
+	return dto
+}
+
+func NewSavingsGoals(goals []models.SavingsGoal, count int64) interface{} {
+	dtos := make([]savingsGoal, len(goals))
+	for i := range goals {
+		dtos[i] = newSavingsGoalDTO(&goals[i])
+	}
+
+	type savingsGoalsResp struct {
+		Base
+		Count        int64         `json:"count"`
+		SavingsGoals []savingsGoal `json:"savingsGoals"`
+	}
+
+	return savingsGoalsResp{
+		Base: Base{
+			Success: true,
+		},
+		Count:        count,
+		SavingsGoals: dtos,
+	}
+}
+
+func NewSavingsGoal(goalModel *models.SavingsGoal) interface{} {
+	type savingsGoalResp struct {
+		Base
+		savingsGoal
+	}
+
+	return savingsGoalResp{
+		Base: Base{
+			Success: true,
+		},
+		savingsGoal: newSavingsGoalDTO(goalModel),
+	}
+}
+
 func NewInvitation(invitationModel *models.GroupInvitation) interface{} {
 	type invitationResp struct {
 		Base
@@ -160,6 +489,8 @@ func NewGroups(groups []models.Group, count int64) interface{} {
 		groupDTOs[i].Name = g.Name
 		groupDTOs[i].Description = g.Description
 		groupDTOs[i].GroupPictureId = g.GroupPictureId
+		groupDTOs[i].Archived = g.Archived
+		groupDTOs[i].Currency = g.Currency
 	}
 
 	type groupsResp struct {
@@ -192,18 +523,42 @@ func NewGroup(group *models.Group, isMember, isAdmin bool) interface{} {
 			Name:           group.Name,
 			Description:    group.Description,
 			GroupPictureId: group.GroupPictureId,
+			Archived:       group.Archived,
+			Currency:       group.Currency,
+			TimeZone:       group.TimeZone,
 			Member:         isMember,
 			Admin:          isAdmin,
+
+			ApprovalThreshold:    group.ApprovalThreshold,
+			ApproveBankTransfers: group.ApproveBankTransfers,
+
+			AnnualInterestRate: float64(group.Interest.Rate) / 100,
+			InterestPeriod:     group.Interest.Period,
+			InterestMinBalance: group.Interest.MinBalance,
+			NextInterestPayout: group.Interest.NextPayout,
 		},
 	}
 }
 
-func NewTransaction(transactionModel *models.TransactionLogEntry, user *models.User) interface{} {
-	type transactionResp struct {
-		Base
-		transaction
+// Titles of transactions created by h-bank itself by models.TransactionKind…
+var transactionKindTitles = map[string]string{
+	models.TransactionKindAccountDeleted: "Account deleted",
+	models.TransactionKindInterest:       "Interest",
+}
+
+// Returns the title of the entry or the translated title of its kind if it was created by h-bank itself.
+func TransactionTitle(entry *models.TransactionLogEntry, lang string) string {
+	if entry.Title != "" || entry.Kind == "" {
+		return entry.Title
 	}
+	title, ok := transactionKindTitles[entry.Kind]
+	if !ok {
+		return entry.Title
+	}
+	return services.Tr(title, lang)
+}
 
+func newTransactionDTO(transactionModel *models.TransactionLogEntry, user *models.User, currency, lang string) transaction {
 	isSender := user.Id == transactionModel.SenderId
 
 	newBalance := transactionModel.NewBalanceReceiver
@@ -214,11 +569,14 @@ func NewTransaction(transactionModel *models.TransactionLogEntry, user *models.U
 	transactionDTO := transaction{
 		Id:          transactionModel.Id,
 		Time:        transactionModel.Created,
-		Title:       transactionModel.Title,
+		Title:       TransactionTitle(transactionModel, lang),
 		Description: transactionModel.Description,
 		Amount:      transactionModel.Amount,
 		NewBalance:  newBalance,
 		GroupId:     transactionModel.GroupId,
+
+		Currency:        currency,
+		FormattedAmount: services.FormatMoney(transactionModel.Amount, currency, lang),
 	}
 
 	if transactionModel.ReceiverIsBank {
@@ -234,16 +592,48 @@ func NewTransaction(transactionModel *models.TransactionLogEntry, user *models.U
 	}
 
 	transactionDTO.PaymentPlanId = transactionModel.PaymentPlanId
+	transactionDTO.ReversalOfId = transactionModel.ReversalOfId
+	transactionDTO.ReversedById = transactionModel.ReversedById
+	transactionDTO.SavingsGoalId = transactionModel.SavingsGoalId
+	transactionDTO.SavingsGoalDeposit = transactionModel.SavingsGoalId != "" && transactionModel.BalanceDifferenceSender != 0
+	transactionDTO.CashLogEntryId = transactionModel.CashLogEntryId
+	transactionDTO.Kind = transactionModel.Kind
+
+	return transactionDTO
+}
+
+func NewTransaction(transactionModel *models.TransactionLogEntry, user *models.User, currency, lang string) interface{} {
+	type transactionResp struct {
+		Base
+		transaction
+	}
 
 	return transactionResp{
 		Base: Base{
 			Success: true,
 		},
-		transaction: transactionDTO,
+		transaction: newTransactionDTO(transactionModel, user, currency, lang),
+	}
+}
+
+// Response of a cash payout or deposit with the linked cash log entry.
+func NewCashTransaction(transactionModel *models.TransactionLogEntry, cashEntry *models.CashLogEntry, user *models.User, currency, lang string) interface{} {
+	type cashTransactionResp struct {
+		Base
+		Transaction  transaction            `json:"transaction"`
+		CashLogEntry CashLogEntryDetailedV2 `json:"cashLogEntry"`
+	}
+
+	return cashTransactionResp{
+		Base: Base{
+			Success: true,
+		},
+		Transaction:  newTransactionDTO(transactionModel, user, currency, lang),
+		CashLogEntry: newCashLogEntryV2DTO(cashEntry, lang),
 	}
 }
 
-func NewBankTransaction(transactionModel *models.TransactionLogEntry) interface{} {
+func NewBankTransaction(transactionModel *models.TransactionLogEntry, currency, lang string) interface{} {
 	type transactionResp struct {
 		Base
 		bankTransaction
@@ -252,10 +642,13 @@ func NewBankTransaction(transactionModel *models.TransactionLogEntry) interface{
 	transactionDTO := bankTransaction{
 		Id:          transactionModel.Id,
 		Time:        transactionModel.Created,
-		Title:       transactionModel.Title,
+		Title:       TransactionTitle(transactionModel, lang),
 		Description: transactionModel.Description,
 		Amount:      transactionModel.Amount,
 		GroupId:     transactionModel.GroupId,
+
+		Currency:        currency,
+		FormattedAmount: services.FormatMoney(transactionModel.Amount, currency, lang),
 	}
 
 	if transactionModel.ReceiverIsBank {
@@ -271,6 +664,10 @@ func NewBankTransaction(transactionModel *models.TransactionLogEntry) interface{
 	}
 
 	transactionDTO.PaymentPlanId = transactionModel.PaymentPlanId
+	transactionDTO.ReversalOfId = transactionModel.ReversalOfId
+	transactionDTO.ReversedById = transactionModel.ReversedById
+	transactionDTO.CashLogEntryId = transactionModel.CashLogEntryId
+	transactionDTO.Kind = transactionModel.Kind
 
 	return transactionResp{
 		Base: Base{
@@ -280,7 +677,7 @@ func NewBankTransaction(transactionModel *models.TransactionLogEntry) interface{
 	}
 }
 
-func NewTransactionLog(log []models.TransactionLogEntry, user *models.User, count int64) interface{} {
+func NewTransactionLog(log []models.TransactionLogEntry, user *models.User, count int64, currency, lang string) interface{} {
 	type transactionsResp struct {
 		Base
 		Count        int64         `json:"count"`
@@ -300,10 +697,13 @@ func NewTransactionLog(log []models.TransactionLogEntry, user *models.User, coun
 		transactionDTO := transaction{
 			Id:         entry.Id,
 			Time:       entry.Created,
-			Title:      entry.Title,
+			Title:      TransactionTitle(&entry, lang),
 			Amount:     entry.Amount,
 			NewBalance: newBalance,
 			GroupId:    entry.GroupId,
+
+			Currency:        currency,
+			FormattedAmount: services.FormatMoney(entry.Amount, currency, lang),
 		}
 
 		if entry.ReceiverIsBank {
@@ -319,6 +719,12 @@ func NewTransactionLog(log []models.TransactionLogEntry, user *models.User, coun
 		}
 
 		transactionDTO.PaymentPlanId = entry.PaymentPlanId
+		transactionDTO.ReversalOfId = entry.ReversalOfId
+		transactionDTO.ReversedById = entry.ReversedById
+		transactionDTO.SavingsGoalId = entry.SavingsGoalId
+		transactionDTO.SavingsGoalDeposit = entry.SavingsGoalId != "" && entry.BalanceDifferenceSender != 0
+		transactionDTO.CashLogEntryId = entry.CashLogEntryId
+		transactionDTO.Kind = entry.Kind
 
 		transactionDTOs[i] = transactionDTO
 	}
@@ -332,7 +738,7 @@ func NewTransactionLog(log []models.TransactionLogEntry, user *models.User, coun
 	}
 }
 
-func NewBankTransactionLog(log []models.TransactionLogEntry, count int64) interface{} {
+func NewBankTransactionLog(log []models.TransactionLogEntry, count int64, currency, lang string) interface{} {
 	type transactionsResp struct {
 		Base
 		Count        int64             `json:"count"`
@@ -345,9 +751,12 @@ func NewBankTransactionLog(log []models.TransactionLogEntry, count int64) interf
 		transactionDTO := bankTransaction{
 			Id:      entry.Id,
 			Time:    entry.Created,
-			Title:   entry.Title,
+			Title:   TransactionTitle(&entry, lang),
 			Amount:  entry.Amount,
 			GroupId: entry.GroupId,
+
+			Currency:        currency,
+			FormattedAmount: services.FormatMoney(entry.Amount, currency, lang),
 		}
 
 		if entry.ReceiverIsBank {
@@ -363,6 +772,10 @@ func NewBankTransactionLog(log []models.TransactionLogEntry, count int64) interf
 		}
 
 		transactionDTO.PaymentPlanId = entry.PaymentPlanId
+		transactionDTO.ReversalOfId = entry.ReversalOfId
+		transactionDTO.ReversedById = entry.ReversedById
+		transactionDTO.CashLogEntryId = entry.CashLogEntryId
+		transactionDTO.Kind = entry.Kind
 
 		transactionDTOs[i] = transactionDTO
 	}
@@ -376,36 +789,41 @@ func NewBankTransactionLog(log []models.TransactionLogEntry, count int64) interf
 	}
 }
 
-func NewDeleteFailedBecauseOfSoleGroupAdmin(groupIds []uuid.UUID, lang string) interface{} {
-	ids := make([]string, len(groupIds))
-	for i := range groupIds {
-		ids[i] = groupIds[i].String()
-	}
-
+func NewDeleteFailedBecauseOfSoleGroupAdmin(groupIds []string, lang string) interface{} {
 	return &DeleteFailedBecauseOfSoleGroupAdmin{
 		Base: Base{
 			Success: false,
 			Message: services.Tr("Failed to delete user because he is the only admin of one or more groups", lang),
 		},
-		GroupIds: ids,
+		GroupIds: groupIds,
 	}
 }
 
-func NewPaymentPlan(paymentPlanModel *models.PaymentPlan) interface{} {
+func NewPaymentPlan(paymentPlanModel *models.PaymentPlan, currency, lang string) interface{} {
 	type paymentPlanResp struct {
 		Base
 		paymentPlan
 	}
 
 	paymentPlanDTO := paymentPlan{
-		Id:           paymentPlanModel.Id,
-		NextExecute:  paymentPlanModel.NextExecute,
-		Name:         paymentPlanModel.Name,
-		Description:  paymentPlanModel.Description,
-		Schedule:     paymentPlanModel.Schedule,
-		ScheduleUnit: paymentPlanModel.ScheduleUnit,
-		Amount:       paymentPlanModel.Amount,
-		GroupId:      paymentPlanModel.GroupId,
+		Id:            paymentPlanModel.Id,
+		NextExecute:   paymentPlanModel.NextExecute,
+		Name:          paymentPlanModel.Name,
+		Description:   paymentPlanModel.Description,
+		Schedule:      paymentPlanModel.Schedule,
+		ScheduleUnit:  paymentPlanModel.ScheduleUnit,
+		ScheduleRule:  paymentPlanModel.ScheduleRule,
+		WeekdaysOnly:  paymentPlanModel.WeekdaysOnly,
+		EndDate:       paymentPlanModel.EndDate,
+		TimeZone:      paymentPlanModel.TimeZone,
+		TimeOfDay:     time.Unix(paymentPlanModel.FirstPayment, 0).In(paymentPlanModel.Location()).Format("15:04"),
+		Amount:        paymentPlanModel.Amount,
+		GroupId:       paymentPlanModel.GroupId,
+		FailurePolicy: paymentPlanModel.FailurePolicy,
+		Paused:        paymentPlanModel.Paused,
+
+		Currency:        currency,
+		FormattedAmount: services.FormatMoney(paymentPlanModel.Amount, currency, lang),
 	}
 
 	if paymentPlanModel.ReceiverIsBank {
@@ -428,7 +846,7 @@ func NewPaymentPlan(paymentPlanModel *models.PaymentPlan) interface{} {
 	}
 }
 
-func NewPaymentPlans(paymentPlans []models.PaymentPlan, count int64) interface{} {
+func NewPaymentPlans(paymentPlans []models.PaymentPlan, count int64, currency, lang string) interface{} {
 	type paymentPlansResp struct {
 		Base
 		Count        int64         `json:"count"`
@@ -440,13 +858,23 @@ func NewPaymentPlans(paymentPlans []models.PaymentPlan, count int64) interface{}
 	for i, plan := range paymentPlans {
 
 		paymentPlanDTO := paymentPlan{
-			Id:           plan.Id,
-			NextExecute:  plan.NextExecute,
-			Name:         plan.Name,
-			Schedule:     plan.Schedule,
-			ScheduleUnit: plan.ScheduleUnit,
-			Amount:       plan.Amount,
-			GroupId:      plan.GroupId,
+			Id:            plan.Id,
+			NextExecute:   plan.NextExecute,
+			Name:          plan.Name,
+			Schedule:      plan.Schedule,
+			ScheduleUnit:  plan.ScheduleUnit,
+			ScheduleRule:  plan.ScheduleRule,
+			WeekdaysOnly:  plan.WeekdaysOnly,
+			EndDate:       plan.EndDate,
+			TimeZone:      plan.TimeZone,
+			TimeOfDay:     time.Unix(plan.FirstPayment, 0).In(plan.Location()).Format("15:04"),
+			Amount:        plan.Amount,
+			GroupId:       plan.GroupId,
+			FailurePolicy: plan.FailurePolicy,
+			Paused:        plan.Paused,
+
+			Currency:        currency,
+			FormattedAmount: services.FormatMoney(plan.Amount, currency, lang),
 		}
 
 		if plan.ReceiverIsBank {
@@ -473,6 +901,35 @@ func NewPaymentPlans(paymentPlans []models.PaymentPlan, count int64) interface{}
 	}
 }
 
+func NewPaymentPlanExecutions(executions []models.PaymentPlanExecution, count int64) interface{} {
+	type paymentPlanExecutionsResp struct {
+		Base
+		Count      int64                  `json:"count"`
+		Executions []paymentPlanExecution `json:"executions"`
+	}
+
+	executionDTOs := make([]paymentPlanExecution, len(executions))
+	for i, e := range executions {
+		executionDTOs[i] = paymentPlanExecution{
+			Id:            e.Id,
+			Scheduled:     e.Scheduled,
+			Status:        e.Status,
+			Message:       e.Message,
+			Attempts:      e.Attempts,
+			LastAttempt:   e.LastAttempt,
+			TransactionId: e.TransactionId,
+		}
+	}
+
+	return paymentPlanExecutionsResp{
+		Base: Base{
+			Success: true,
+		},
+		Count:      count,
+		Executions: executionDTOs,
+	}
+}
+
 func NewTotalMoney(total int) interface{} {
 	type totalMoney struct {
 		Base
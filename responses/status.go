@@ -10,6 +10,8 @@ type Config struct {
 	MaxDescriptionLength      int    `json:"maxDescriptionLength"`
 	MaxProfilePictureFileSize int64  `json:"maxProfilePictureFileSize"`
 	MaxPageSize               int    `json:"maxPageSize"`
+	EditableCashLogEntries    int    `json:"editableCashLogEntries"`
+	MaxImportFileSize         int64  `json:"maxImportFileSize"`
 	IDProvider                string `json:"idProvider"`
 }
 
@@ -32,6 +34,8 @@ func NewStatus() interface{} {
 			MaxDescriptionLength:      config.Data.MaxDescriptionLength,
 			MaxProfilePictureFileSize: config.Data.MaxProfilePictureFileSize,
 			MaxPageSize:               config.Data.MaxPageSize,
+			EditableCashLogEntries:    config.Data.EditableCashLogEntries,
+			MaxImportFileSize:         config.Data.MaxImportFileSize,
 			IDProvider:                config.Data.IDProvider,
 		},
 	}
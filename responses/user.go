@@ -1,6 +1,12 @@
 package responses
 
-import "github.com/juho05/h-bank/models"
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/juho05/h-bank/models"
+	"github.com/juho05/h-bank/services"
+)
 
 type AuthUser struct {
 	Id                      string `json:"id"`
@@ -8,6 +14,7 @@ type AuthUser struct {
 	Email                   string `json:"email"`
 	PubliclyVisible         bool   `json:"publiclyVisible"`
 	DontSendInvitationEmail bool   `json:"dontSendInvitationEmail"`
+	CashCurrency            string `json:"cashCurrency"`
 }
 
 type User struct {
@@ -15,6 +22,7 @@ type User struct {
 	Name string `json:"name"`
 }
 
+// Shape of the v1 API which only supports the EUR denominations.
 type CashLogEntryDetailed struct {
 	Id          string `json:"id"`
 	Time        int64  `json:"time"`
@@ -39,6 +47,47 @@ type CashLogEntryDetailed struct {
 
 	Amount     int `json:"amount"`
 	Difference int `json:"difference"`
+
+	Currency            string `json:"currency"`
+	FormattedAmount     string `json:"formattedAmount"`
+	FormattedDifference string `json:"formattedDifference"`
+
+	WalletId    string `json:"walletId"`
+	MoveEntryId string `json:"moveEntryId,omitempty"`
+
+	TransactionId      string `json:"transactionId,omitempty"`
+	TransactionGroupId string `json:"transactionGroupId,omitempty"`
+
+	CorrectionOfId string `json:"correctionOfId,omitempty"`
+}
+
+type CashLogEntryDetailedV2 struct {
+	Id          string `json:"id"`
+	Time        int64  `json:"time"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	Currency      string              `json:"currency"`
+	Denominations []DenominationCount `json:"denominations"`
+
+	Amount              int    `json:"amount"`
+	Difference          int    `json:"difference"`
+	FormattedAmount     string `json:"formattedAmount"`
+	FormattedDifference string `json:"formattedDifference"`
+
+	WalletId    string `json:"walletId"`
+	MoveEntryId string `json:"moveEntryId,omitempty"`
+
+	TransactionId      string `json:"transactionId,omitempty"`
+	TransactionGroupId string `json:"transactionGroupId,omitempty"`
+
+	CorrectionOfId string `json:"correctionOfId,omitempty"`
+}
+
+type DenominationCount struct {
+	Key   string `json:"key"`
+	Value int    `json:"value"`
+	Count int    `json:"count"`
 }
 
 type CashLogEntry struct {
@@ -47,46 +96,200 @@ type CashLogEntry struct {
 	Title      string `json:"title"`
 	Amount     int    `json:"amount"`
 	Difference int    `json:"difference"`
+
+	Currency            string `json:"currency"`
+	FormattedAmount     string `json:"formattedAmount"`
+	FormattedDifference string `json:"formattedDifference"`
+
+	WalletId    string `json:"walletId"`
+	MoveEntryId string `json:"moveEntryId,omitempty"`
+
+	TransactionId      string `json:"transactionId,omitempty"`
+	TransactionGroupId string `json:"transactionGroupId,omitempty"`
+
+	CorrectionOfId string `json:"correctionOfId,omitempty"`
+}
+
+type CashLogEntryRevision struct {
+	Id     string `json:"id"`
+	Time   int64  `json:"time"`
+	Action string `json:"action"`
+
+	Title       string `json:"title"`
+	Description string `json:"description"`
+
+	Currency      string              `json:"currency"`
+	Denominations []DenominationCount `json:"denominations"`
+
+	Amount              int    `json:"amount"`
+	Difference          int    `json:"difference"`
+	FormattedAmount     string `json:"formattedAmount"`
+	FormattedDifference string `json:"formattedDifference"`
+}
+
+type CashWallet struct {
+	Id              string `json:"id"`
+	Name            string `json:"name"`
+	Currency        string `json:"currency"`
+	Amount          int    `json:"amount"`
+	FormattedAmount string `json:"formattedAmount"`
 }
 
-func NewCashLogEntry(entry *models.CashLogEntry) interface{} {
+type CashTotal struct {
+	Currency        string `json:"currency"`
+	Amount          int    `json:"amount"`
+	FormattedAmount string `json:"formattedAmount"`
+}
+
+// Returns the counts of all denominations of the currency of the entry.
+func newDenominationCounts(entry *models.CashLogEntry) []DenominationCount {
+	counts := make(map[string]int, len(entry.Denominations))
+	for _, d := range entry.Denominations {
+		counts[d.Key] = d.Count
+	}
+
+	denominations := services.Currencies[entry.Currency].Denominations
+	dtos := make([]DenominationCount, len(denominations))
+	for i, d := range denominations {
+		dtos[i] = DenominationCount{
+			Key:   d.Key,
+			Value: d.Value,
+			Count: counts[d.Key],
+		}
+	}
+	return dtos
+}
+
+func NewCashLogEntry(entry *models.CashLogEntry, lang string) interface{} {
 	type cashLogEntryResp struct {
 		Base
 		CashLogEntryDetailed
 	}
+
+	dto := CashLogEntryDetailed{
+		Id:          entry.Id,
+		Time:        entry.Created,
+		Title:       entry.ChangeTitle,
+		Description: entry.ChangeDescription,
+
+		Amount:     entry.TotalAmount,
+		Difference: entry.ChangeDifference,
+
+		Currency:            entry.Currency,
+		FormattedAmount:     services.FormatMoney(entry.TotalAmount, entry.Currency, lang),
+		FormattedDifference: services.FormatMoney(entry.ChangeDifference, entry.Currency, lang),
+
+		WalletId:    entry.WalletId,
+		MoveEntryId: entry.MoveEntryId,
+
+		TransactionId:      entry.TransactionId,
+		TransactionGroupId: entry.TransactionGroupId,
+
+		CorrectionOfId: entry.CorrectionOfId,
+	}
+
+	if entry.Currency == services.DefaultCurrency {
+		eurCounts := map[string]*int{
+			"ct1": &dto.Ct1, "ct2": &dto.Ct2, "ct5": &dto.Ct5, "ct10": &dto.Ct10, "ct20": &dto.Ct20, "ct50": &dto.Ct50,
+			"eur1": &dto.Eur1, "eur2": &dto.Eur2, "eur5": &dto.Eur5, "eur10": &dto.Eur10, "eur20": &dto.Eur20,
+			"eur50": &dto.Eur50, "eur100": &dto.Eur100, "eur200": &dto.Eur200, "eur500": &dto.Eur500,
+		}
+		for _, d := range entry.Denominations {
+			if count, ok := eurCounts[d.Key]; ok {
+				*count = d.Count
+			}
+		}
+	}
+
 	return cashLogEntryResp{
 		Base: Base{
 			Success: true,
 		},
-		CashLogEntryDetailed: CashLogEntryDetailed{
-			Id:          entry.Id,
-			Time:        entry.Created,
-			Title:       entry.ChangeTitle,
-			Description: entry.ChangeDescription,
-
-			Ct1:    entry.Ct1,
-			Ct2:    entry.Ct2,
-			Ct5:    entry.Ct5,
-			Ct10:   entry.Ct10,
-			Ct20:   entry.Ct20,
-			Ct50:   entry.Ct50,
-			Eur1:   entry.Eur1,
-			Eur2:   entry.Eur2,
-			Eur5:   entry.Eur5,
-			Eur10:  entry.Eur10,
-			Eur20:  entry.Eur20,
-			Eur50:  entry.Eur50,
-			Eur100: entry.Eur100,
-			Eur200: entry.Eur200,
-			Eur500: entry.Eur500,
+		CashLogEntryDetailed: dto,
+	}
+}
 
-			Amount:     entry.TotalAmount,
-			Difference: entry.ChangeDifference,
+func newCashLogEntryV2DTO(entry *models.CashLogEntry, lang string) CashLogEntryDetailedV2 {
+	return CashLogEntryDetailedV2{
+		Id:          entry.Id,
+		Time:        entry.Created,
+		Title:       entry.ChangeTitle,
+		Description: entry.ChangeDescription,
+
+		Currency:      entry.Currency,
+		Denominations: newDenominationCounts(entry),
+
+		Amount:              entry.TotalAmount,
+		Difference:          entry.ChangeDifference,
+		FormattedAmount:     services.FormatMoney(entry.TotalAmount, entry.Currency, lang),
+		FormattedDifference: services.FormatMoney(entry.ChangeDifference, entry.Currency, lang),
+
+		WalletId:    entry.WalletId,
+		MoveEntryId: entry.MoveEntryId,
+
+		TransactionId:      entry.TransactionId,
+		TransactionGroupId: entry.TransactionGroupId,
+
+		CorrectionOfId: entry.CorrectionOfId,
+	}
+}
+
+func NewCashLogEntryV2(entry *models.CashLogEntry, lang string) interface{} {
+	type cashLogEntryResp struct {
+		Base
+		CashLogEntryDetailedV2
+	}
+	return cashLogEntryResp{
+		Base: Base{
+			Success: true,
 		},
+		CashLogEntryDetailedV2: newCashLogEntryV2DTO(entry, lang),
 	}
 }
 
-func NewCashLog(log []models.CashLogEntry, count int64) interface{} {
+func NewCashLogEntryRevisions(revisions []models.CashLogEntryRevision, lang string) interface{} {
+	type revisionsResp struct {
+		Base
+		Revisions []CashLogEntryRevision `json:"revisions"`
+	}
+
+	dtos := make([]CashLogEntryRevision, len(revisions))
+	for i, r := range revisions {
+		// the denominations are only used for the response, so broken JSON results in empty counts
+		var counts map[string]int
+		json.Unmarshal([]byte(r.Denominations), &counts)
+		entry := &models.CashLogEntry{Currency: r.Currency}
+		for key, count := range counts {
+			entry.Denominations = append(entry.Denominations, models.CashDenominationCount{Key: key, Count: count})
+		}
+
+		dtos[i] = CashLogEntryRevision{
+			Id:     r.Id,
+			Time:   r.Created,
+			Action: r.Action,
+
+			Title:       r.ChangeTitle,
+			Description: r.ChangeDescription,
+
+			Currency:      r.Currency,
+			Denominations: newDenominationCounts(entry),
+
+			Amount:              r.TotalAmount,
+			Difference:          r.ChangeDifference,
+			FormattedAmount:     services.FormatMoney(r.TotalAmount, r.Currency, lang),
+			FormattedDifference: services.FormatMoney(r.ChangeDifference, r.Currency, lang),
+		}
+	}
+
+	return revisionsResp{
+		Base: Base{
+			Success: true,
+		},
+		Revisions: dtos,
+	}
+}
+
+func NewCashLog(log []models.CashLogEntry, count int64, lang string) interface{} {
 	type cashLogResp struct {
 		Base
 		Count   int64          `json:"count"`
@@ -103,6 +306,18 @@ func NewCashLog(log []models.CashLogEntry, count int64) interface{} {
 
 			Amount:     entry.TotalAmount,
 			Difference: entry.ChangeDifference,
+
+			Currency:            entry.Currency,
+			FormattedAmount:     services.FormatMoney(entry.TotalAmount, entry.Currency, lang),
+			FormattedDifference: services.FormatMoney(entry.ChangeDifference, entry.Currency, lang),
+
+			WalletId:    entry.WalletId,
+			MoveEntryId: entry.MoveEntryId,
+
+			TransactionId:      entry.TransactionId,
+			TransactionGroupId: entry.TransactionGroupId,
+
+			CorrectionOfId: entry.CorrectionOfId,
 		}
 	}
 
@@ -115,6 +330,75 @@ func NewCashLog(log []models.CashLogEntry, count int64) interface{} {
 	}
 }
 
+func newCashWallet(wallet *models.CashWallet, amount int, lang string) CashWallet {
+	return CashWallet{
+		Id:              wallet.Id,
+		Name:            wallet.Name,
+		Currency:        wallet.Currency,
+		Amount:          amount,
+		FormattedAmount: services.FormatMoney(amount, wallet.Currency, lang),
+	}
+}
+
+func NewCashWallet(wallet *models.CashWallet, amount int, lang string) interface{} {
+	type cashWalletResp struct {
+		Base
+		CashWallet
+	}
+	return cashWalletResp{
+		Base: Base{
+			Success: true,
+		},
+		CashWallet: newCashWallet(wallet, amount, lang),
+	}
+}
+
+// amounts[i] is the current amount of wallets[i].
+func NewCashWallets(wallets []models.CashWallet, amounts []int, lang string) interface{} {
+	type cashWalletsResp struct {
+		Base
+		Wallets []CashWallet `json:"wallets"`
+	}
+
+	dtos := make([]CashWallet, len(wallets))
+	for i := range wallets {
+		dtos[i] = newCashWallet(&wallets[i], amounts[i], lang)
+	}
+
+	return cashWalletsResp{
+		Base: Base{
+			Success: true,
+		},
+		Wallets: dtos,
+	}
+}
+
+func NewCashTotals(totals map[string]int, lang string) interface{} {
+	type cashTotalsResp struct {
+		Base
+		Totals []CashTotal `json:"totals"`
+	}
+
+	dtos := make([]CashTotal, 0, len(totals))
+	for currency, amount := range totals {
+		dtos = append(dtos, CashTotal{
+			Currency:        currency,
+			Amount:          amount,
+			FormattedAmount: services.FormatMoney(amount, currency, lang),
+		})
+	}
+	sort.Slice(dtos, func(i, j int) bool {
+		return dtos[i].Currency < dtos[j].Currency
+	})
+
+	return cashTotalsResp{
+		Base: Base{
+			Success: true,
+		},
+		Totals: dtos,
+	}
+}
+
 func NewAuthUser(user *models.User) interface{} {
 	type authUserResp struct {
 		Base
@@ -130,6 +414,7 @@ func NewAuthUser(user *models.User) interface{} {
 			Email:                   user.Email,
 			PubliclyVisible:         user.PubliclyVisible,
 			DontSendInvitationEmail: user.DontSendInvitationEmail,
+			CashCurrency:            user.CashCurrency,
 		},
 	}
 }
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const DefaultCurrency = "EUR"
+
+type Denomination struct {
+	// Used as the JSON key of the count in cash log entries
+	Key string
+	// In the minor unit of the currency (e.g. cents)
+	Value int
+}
+
+type Currency struct {
+	Code string
+	// Placed in front of the amount in English and behind it in German
+	Symbol        string
+	Denominations []Denomination
+}
+
+var Currencies = map[string]Currency{
+	"EUR": {
+		Code:   "EUR",
+		Symbol: "€",
+		Denominations: []Denomination{
+			{Key: "ct1", Value: 1}, {Key: "ct2", Value: 2}, {Key: "ct5", Value: 5}, {Key: "ct10", Value: 10}, {Key: "ct20", Value: 20}, {Key: "ct50", Value: 50},
+			{Key: "eur1", Value: 100}, {Key: "eur2", Value: 200}, {Key: "eur5", Value: 500}, {Key: "eur10", Value: 1000}, {Key: "eur20", Value: 2000},
+			{Key: "eur50", Value: 5000}, {Key: "eur100", Value: 10000}, {Key: "eur200", Value: 20000}, {Key: "eur500", Value: 50000},
+		},
+	},
+	"CHF": {
+		Code:   "CHF",
+		Symbol: "CHF",
+		Denominations: []Denomination{
+			{Key: "rp5", Value: 5}, {Key: "rp10", Value: 10}, {Key: "rp20", Value: 20}, {Key: "rp50", Value: 50},
+			{Key: "chf1", Value: 100}, {Key: "chf2", Value: 200}, {Key: "chf5", Value: 500}, {Key: "chf10", Value: 1000}, {Key: "chf20", Value: 2000},
+			{Key: "chf50", Value: 5000}, {Key: "chf100", Value: 10000}, {Key: "chf200", Value: 20000}, {Key: "chf1000", Value: 100000},
+		},
+	},
+	"GBP": {
+		Code:   "GBP",
+		Symbol: "£",
+		Denominations: []Denomination{
+			{Key: "p1", Value: 1}, {Key: "p2", Value: 2}, {Key: "p5", Value: 5}, {Key: "p10", Value: 10}, {Key: "p20", Value: 20}, {Key: "p50", Value: 50},
+			{Key: "gbp1", Value: 100}, {Key: "gbp2", Value: 200}, {Key: "gbp5", Value: 500}, {Key: "gbp10", Value: 1000}, {Key: "gbp20", Value: 2000}, {Key: "gbp50", Value: 5000},
+		},
+	},
+	"USD": {
+		Code:   "USD",
+		Symbol: "$",
+		Denominations: []Denomination{
+			{Key: "ct1", Value: 1}, {Key: "ct5", Value: 5}, {Key: "ct10", Value: 10}, {Key: "ct25", Value: 25}, {Key: "ct50", Value: 50},
+			{Key: "usd1", Value: 100}, {Key: "usd2", Value: 200}, {Key: "usd5", Value: 500}, {Key: "usd10", Value: 1000}, {Key: "usd20", Value: 2000},
+			{Key: "usd50", Value: 5000}, {Key: "usd100", Value: 10000},
+		},
+	},
+}
+
+func IsSupportedCurrency(code string) bool {
+	_, ok := Currencies[code]
+	return ok
+}
+
+// Returns the denomination of the currency with the given key.
+func GetDenomination(code, key string) (Denomination, bool) {
+	for _, d := range Currencies[code].Denominations {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return Denomination{}, false
+}
+
+// Formats an amount in the minor unit of the currency, e.g. "€1,234.50" (en) or "1.234,50 €" (de).
+func FormatMoney(amount int, code, lang string) string {
+	symbol := code
+	if currency, ok := Currencies[code]; ok {
+		symbol = currency.Symbol
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	thousandsSep, decimalSep := ",", "."
+	if lang == "de" {
+		thousandsSep, decimalSep = ".", ","
+	}
+
+	major := fmt.Sprintf("%d", amount/100)
+	var builder strings.Builder
+	for i, r := range major {
+		if i > 0 && (len(major)-i)%3 == 0 {
+			builder.WriteString(thousandsSep)
+		}
+		builder.WriteRune(r)
+	}
+	number := fmt.Sprintf("%s%s%02d", builder.String(), decimalSep, amount%100)
+
+	if lang == "de" {
+		return fmt.Sprintf("%s%s %s", sign, number, symbol)
+	}
+	if utf8.RuneCountInString(symbol) > 1 {
+		return fmt.Sprintf("%s%s %s", sign, symbol, number)
+	}
+	return fmt.Sprintf("%s%s%s", sign, symbol, number)
+}
+
+// Formats the amount in minor units as a plain decimal number without symbol and grouping
+// (e.g. "-1234.50" or "-1234,50" for German), which spreadsheet applications can parse.
+func FormatDecimal(amount int, lang string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	decimalSep := "."
+	if lang == "de" {
+		decimalSep = ","
+	}
+
+	return fmt.Sprintf("%s%d%s%02d", sign, amount/100, decimalSep, amount%100)
+}
+
+// Parses a decimal number like "12.5", "-12,50" or "1234" into minor units.
+// Grouping separators are not supported because they are ambiguous.
+func ParseDecimal(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	sign := 1
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+		value = value[1:]
+	}
+
+	major, minor, hasMinor := strings.Cut(strings.Replace(value, ",", ".", 1), ".")
+	if major == "" || (hasMinor && (minor == "" || len(minor) > 2)) {
+		return 0, fmt.Errorf("invalid decimal number '%s'", value)
+	}
+	if len(minor) == 1 {
+		minor += "0"
+	}
+	if minor == "" {
+		minor = "00"
+	}
+
+	majorValue, err := strconv.ParseUint(major, 10, 31)
+	if err != nil {
+		return 0, fmt.Errorf("invalid decimal number '%s'", value)
+	}
+	minorValue, err := strconv.ParseUint(minor, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid decimal number '%s'", value)
+	}
+	return sign * (int(majorValue)*100 + int(minorValue)), nil
+}
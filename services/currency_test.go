@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   int
+		currency string
+		lang     string
+		want     string
+	}{
+		{amount: 0, currency: "EUR", lang: "en", want: "€0.00"},
+		{amount: 5, currency: "EUR", lang: "en", want: "€0.05"},
+		{amount: 123450, currency: "EUR", lang: "en", want: "€1,234.50"},
+		{amount: 123450, currency: "EUR", lang: "de", want: "1.234,50 €"},
+		{amount: -250, currency: "GBP", lang: "en", want: "-£2.50"},
+		{amount: 100000000, currency: "USD", lang: "en", want: "$1,000,000.00"},
+		{amount: 1990, currency: "CHF", lang: "en", want: "CHF 19.90"},
+		{amount: 1990, currency: "CHF", lang: "de", want: "19,90 CHF"},
+		{amount: 100, currency: "XYZ", lang: "en", want: "XYZ 1.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatMoney(tt.amount, tt.currency, tt.lang))
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		amount int
+		lang   string
+		want   string
+	}{
+		{amount: 0, lang: "en", want: "0.00"},
+		{amount: 123450, lang: "en", want: "1234.50"},
+		{amount: 123450, lang: "de", want: "1234,50"},
+		{amount: -5, lang: "en", want: "-0.05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatDecimal(tt.amount, tt.lang))
+		})
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "12", want: 1200},
+		{value: "12.5", want: 1250},
+		{value: "12,50", want: 1250},
+		{value: "-0.05", want: -5},
+		{value: " 3.00 ", want: 300},
+		{value: "1.234,50", wantErr: true},
+		{value: "1.234", wantErr: true},
+		{value: "12.", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDecimal(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	for code, currency := range Currencies {
+		assert.Equal(t, code, currency.Code)
+		keys := make(map[string]bool, len(currency.Denominations))
+		for i, d := range currency.Denominations {
+			assert.False(t, keys[d.Key], "duplicate denomination key %s in %s", d.Key, code)
+			keys[d.Key] = true
+			if i > 0 {
+				assert.Greater(t, d.Value, currency.Denominations[i-1].Value, "denominations of %s must be sorted", code)
+			}
+		}
+	}
+}
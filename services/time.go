@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"log"
 	"time"
 )
@@ -21,3 +22,195 @@ func AddTime(unixTime int64, value int, unit string) int64 {
 		return 0
 	}
 }
+
+// Describes when the payments of a payment plan are due.
+// All payments are calculated from Start, so monthly payments on the 31st don't drift to the 28th after February.
+type Schedule struct {
+	// first payment
+	Start    int64
+	Interval int
+	// "day", "week", "month" or "year"
+	Unit string
+	// "date", "lastDay", "weekday" or "lastWeekday", only used by monthly and yearly schedules
+	Rule string
+	// Payments on saturdays and sundays are moved to the next monday. Daily schedules only count weekdays.
+	WeekdaysOnly bool
+	// Days, months and the time of day of Start are calculated in this location, nil for UTC.
+	// Payments keep their local time of day across DST transitions.
+	Location *time.Location
+}
+
+// Returns the time of the n-th payment (starting at 0) or 0 if the schedule is invalid.
+func (s Schedule) Occurrence(n int) int64 {
+	if s.Interval < 1 || n < 0 {
+		return 0
+	}
+	location := s.Location
+	if location == nil {
+		location = time.UTC
+	}
+	t := time.Unix(s.Start, 0).In(location)
+	switch s.Unit {
+	case "day":
+		if s.WeekdaysOnly {
+			return addWeekdays(nextWeekday(t), n*s.Interval).Unix()
+		}
+		t = t.AddDate(0, 0, n*s.Interval)
+	case "week":
+		t = t.AddDate(0, 0, n*s.Interval*7)
+	case "month":
+		t = monthOccurrence(t, n*s.Interval, s.Rule)
+	case "year":
+		t = monthOccurrence(t, n*s.Interval*12, s.Rule)
+	default:
+		log.Println("Error: unknown time unit:", s.Unit)
+		return 0
+	}
+	if s.WeekdaysOnly {
+		t = nextWeekday(t)
+	}
+	return t.Unix()
+}
+
+// Returns the time of the first payment after unixTime or 0 if the schedule is invalid.
+func (s Schedule) Next(unixTime int64) int64 {
+	if s.Occurrence(0) == 0 {
+		return 0
+	}
+	if s.Occurrence(0) > unixTime {
+		return s.Occurrence(0)
+	}
+
+	// occurrences are increasing, so the first one after unixTime can be found with a binary search
+	low, high := 0, 1
+	for s.Occurrence(high) <= unixTime {
+		low = high
+		high *= 2
+	}
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		if s.Occurrence(mid) <= unixTime {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return s.Occurrence(high)
+}
+
+// Returns the day of the month which is months after start according to rule:
+//   - date: the day of the month of start or the last day of shorter months
+//   - lastDay: the last day of the month
+//   - weekday: the weekday of start in the same week of the month as start (e.g. the 2nd friday)
+//   - lastWeekday: the last occurrence of the weekday of start in the month
+func monthOccurrence(start time.Time, months int, rule string) time.Time {
+	first := time.Date(start.Year(), start.Month()+time.Month(months), 1, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	days := first.AddDate(0, 1, -1).Day()
+	switch rule {
+	case "lastDay":
+		return first.AddDate(0, 0, days-1)
+	case "weekday":
+		day := 1 + (int(start.Weekday())-int(first.Weekday())+7)%7 + (start.Day()-1)/7*7
+		if day > days {
+			day -= 7
+		}
+		return first.AddDate(0, 0, day-1)
+	case "lastWeekday":
+		last := first.AddDate(0, 0, days-1)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(start.Weekday()) + 7) % 7))
+	default:
+		day := start.Day()
+		if day > days {
+			day = days
+		}
+		return first.AddDate(0, 0, day-1)
+	}
+}
+
+// Moves saturdays and sundays to the next monday.
+func nextWeekday(t time.Time) time.Time {
+	switch t.Weekday() {
+	case time.Saturday:
+		return t.AddDate(0, 0, 2)
+	case time.Sunday:
+		return t.AddDate(0, 0, 1)
+	default:
+		return t
+	}
+}
+
+// Adds n days to t skipping saturdays and sundays. t must be a weekday.
+func addWeekdays(t time.Time, n int) time.Time {
+	t = t.AddDate(0, 0, n/5*7)
+	for i := 0; i < n%5; i++ {
+		t = nextWeekday(t.AddDate(0, 0, 1))
+	}
+	return t
+}
+
+// Loads an IANA time zone like "Europe/Berlin".
+// "Local" is rejected because it depends on the server.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("invalid time zone '%s'", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// Returns the time with the same date and time of day as unixTime in from in the location to.
+func MoveToLocation(unixTime int64, from, to *time.Location) int64 {
+	t := time.Unix(unixTime, 0).In(from)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, to).Unix()
+}
+
+// Returns the start of the day, week (starting on monday), month or year in location which contains unixTime.
+func StartOfPeriod(unixTime int64, unit string, location *time.Location) (int64, error) {
+	t := time.Unix(unixTime, 0).In(location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+	switch unit {
+	case "day":
+		return day.Unix(), nil
+	case "week":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7).Unix(), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, location).Unix(), nil
+	case "year":
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, location).Unix(), nil
+	default:
+		return 0, fmt.Errorf("unknown time unit '%s'", unit)
+	}
+}
+
+// Returns the start of the period in location which follows the period containing unixTime.
+func StartOfNextPeriod(unixTime int64, unit string, location *time.Location) (int64, error) {
+	start, err := StartOfPeriod(unixTime, unit, location)
+	if err != nil {
+		return 0, err
+	}
+	t := time.Unix(start, 0).In(location)
+	switch unit {
+	case "day":
+		t = t.AddDate(0, 0, 1)
+	case "week":
+		t = t.AddDate(0, 0, 7)
+	case "month":
+		t = t.AddDate(0, 1, 0)
+	case "year":
+		t = t.AddDate(1, 0, 0)
+	}
+	return t.Unix(), nil
+}
+
+// Layouts accepted by ParseDate. Dates without a time are interpreted as midnight UTC.
+var dateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02", "02.01.2006", "01/02/2006"}
+
+// Parses dates in the formats of exports and common spreadsheet applications.
+func ParseDate(value string) (int64, error) {
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid date '%s'", value)
+}
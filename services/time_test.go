@@ -0,0 +1,211 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartOfPeriod(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Couldn't load time zone")
+	}
+
+	tests := []struct {
+		name     string
+		time     time.Time
+		unit     string
+		location *time.Location
+		want     time.Time
+	}{
+		{name: "Day", time: time.Date(2022, 3, 16, 14, 30, 0, 0, time.UTC), unit: "day", want: time.Date(2022, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "Week", time: time.Date(2022, 3, 16, 14, 30, 0, 0, time.UTC), unit: "week", want: time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{name: "Week on monday", time: time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC), unit: "week", want: time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{name: "Week on sunday", time: time.Date(2022, 3, 20, 23, 59, 59, 0, time.UTC), unit: "week", want: time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{name: "Week across months", time: time.Date(2022, 3, 2, 8, 0, 0, 0, time.UTC), unit: "week", want: time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{name: "Month", time: time.Date(2022, 3, 31, 23, 59, 59, 0, time.UTC), unit: "month", want: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Day in location", time: time.Date(2022, 3, 16, 23, 30, 0, 0, time.UTC), unit: "day", location: berlin, want: time.Date(2022, 3, 17, 0, 0, 0, 0, berlin)},
+		{name: "Month in location", time: time.Date(2022, 3, 31, 22, 30, 0, 0, time.UTC), unit: "month", location: berlin, want: time.Date(2022, 4, 1, 0, 0, 0, 0, berlin)},
+		{name: "Year", time: time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC), unit: "year", want: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Year in location", time: time.Date(2022, 12, 31, 23, 30, 0, 0, time.UTC), unit: "year", location: berlin, want: time.Date(2023, 1, 1, 0, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := tt.location
+			if location == nil {
+				location = time.UTC
+			}
+			start, err := StartOfPeriod(tt.time.Unix(), tt.unit, location)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Unix(), start)
+		})
+	}
+
+	_, err = StartOfPeriod(time.Now().Unix(), "decade", time.UTC)
+	assert.Error(t, err, "unknown units should be rejected")
+}
+
+func TestStartOfNextPeriod(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Couldn't load time zone")
+	}
+
+	tests := []struct {
+		name     string
+		time     time.Time
+		unit     string
+		location *time.Location
+		want     time.Time
+	}{
+		{name: "Day", time: time.Date(2022, 3, 16, 14, 30, 0, 0, time.UTC), unit: "day", want: time.Date(2022, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "Week", time: time.Date(2022, 3, 16, 14, 30, 0, 0, time.UTC), unit: "week", want: time.Date(2022, 3, 21, 0, 0, 0, 0, time.UTC)},
+		{name: "Month", time: time.Date(2022, 1, 31, 8, 0, 0, 0, time.UTC), unit: "month", want: time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Year", time: time.Date(2022, 3, 16, 14, 30, 0, 0, time.UTC), unit: "year", want: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Month across DST in location", time: time.Date(2022, 3, 1, 0, 0, 0, 0, berlin), unit: "month", location: berlin, want: time.Date(2022, 4, 1, 0, 0, 0, 0, berlin)},
+		{name: "Year in location", time: time.Date(2022, 12, 31, 23, 30, 0, 0, time.UTC), unit: "year", location: berlin, want: time.Date(2024, 1, 1, 0, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := tt.location
+			if location == nil {
+				location = time.UTC
+			}
+			next, err := StartOfNextPeriod(tt.time.Unix(), tt.unit, location)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Unix(), next)
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2022-03-16T14:30:00Z", want: time.Date(2022, 3, 16, 14, 30, 0, 0, time.UTC)},
+		{value: "2022-03-16", want: time.Date(2022, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{value: "16.03.2022", want: time.Date(2022, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{value: "03/16/2022", want: time.Date(2022, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDate(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Unix(), got)
+		})
+	}
+}
+
+func TestSchedule_Occurrence(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     []time.Time
+	}{
+		{name: "Weekly", schedule: Schedule{Start: date(2023, 3, 1).Unix(), Interval: 1, Unit: "week"}, want: []time.Time{date(2023, 3, 1), date(2023, 3, 8), date(2023, 3, 15), date(2023, 3, 22)}},
+		{name: "Monthly on the 31st", schedule: Schedule{Start: date(2023, 1, 31).Unix(), Interval: 1, Unit: "month", Rule: "date"}, want: []time.Time{date(2023, 1, 31), date(2023, 2, 28), date(2023, 3, 31), date(2023, 4, 30)}},
+		{name: "Yearly on february 29th", schedule: Schedule{Start: date(2024, 2, 29).Unix(), Interval: 1, Unit: "year", Rule: "date"}, want: []time.Time{date(2024, 2, 29), date(2025, 2, 28), date(2026, 2, 28), date(2027, 2, 28), date(2028, 2, 29)}},
+		{name: "Last day of the month", schedule: Schedule{Start: date(2023, 1, 15).Unix(), Interval: 1, Unit: "month", Rule: "lastDay"}, want: []time.Time{date(2023, 1, 31), date(2023, 2, 28), date(2023, 3, 31), date(2023, 4, 30)}},
+		{name: "Every 2nd friday", schedule: Schedule{Start: date(2023, 1, 13).Unix(), Interval: 1, Unit: "month", Rule: "weekday"}, want: []time.Time{date(2023, 1, 13), date(2023, 2, 10), date(2023, 3, 10), date(2023, 4, 14)}},
+		{name: "Every last friday", schedule: Schedule{Start: date(2023, 1, 27).Unix(), Interval: 1, Unit: "month", Rule: "lastWeekday"}, want: []time.Time{date(2023, 1, 27), date(2023, 2, 24), date(2023, 3, 31), date(2023, 4, 28)}},
+		{name: "Monthly on weekdays", schedule: Schedule{Start: date(2023, 4, 15).Unix(), Interval: 1, Unit: "month", Rule: "date", WeekdaysOnly: true}, want: []time.Time{date(2023, 4, 17), date(2023, 5, 15), date(2023, 6, 15), date(2023, 7, 17)}},
+		{name: "Every weekday", schedule: Schedule{Start: date(2023, 3, 2).Unix(), Interval: 1, Unit: "day", WeekdaysOnly: true}, want: []time.Time{date(2023, 3, 2), date(2023, 3, 3), date(2023, 3, 6), date(2023, 3, 7)}},
+		{name: "Every 2nd weekday", schedule: Schedule{Start: date(2023, 3, 3).Unix(), Interval: 2, Unit: "day", WeekdaysOnly: true}, want: []time.Time{date(2023, 3, 3), date(2023, 3, 7), date(2023, 3, 9), date(2023, 3, 13)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				assert.Equal(t, want.Format(time.RFC3339), time.Unix(tt.schedule.Occurrence(i), 0).UTC().Format(time.RFC3339), "occurrence %d", i)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	schedule := Schedule{Start: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC).Unix(), Interval: 1, Unit: "month", Rule: "date"}
+
+	assert.Equal(t, schedule.Start, schedule.Next(schedule.Start-1))
+	assert.Equal(t, time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC).Unix(), schedule.Next(schedule.Start))
+	assert.Equal(t, time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC).Unix(), schedule.Next(time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC).Unix()))
+	assert.Equal(t, time.Date(2033, 1, 31, 0, 0, 0, 0, time.UTC).Unix(), schedule.Next(time.Date(2032, 12, 31, 0, 0, 0, 0, time.UTC).Unix()))
+
+	assert.Zero(t, Schedule{Start: schedule.Start, Interval: 1, Unit: "decade"}.Next(schedule.Start))
+}
+
+func TestSchedule_Occurrence_TimeZone(t *testing.T) {
+	berlin, err := LoadTimeZone("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Couldn't load time zone: %s", err)
+	}
+	newYork, err := LoadTimeZone("America/New_York")
+	if err != nil {
+		t.Fatalf("Couldn't load time zone: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     []time.Time
+	}{
+		{
+			name:     "Monthly at midnight across DST",
+			schedule: Schedule{Start: time.Date(2023, 2, 1, 0, 0, 0, 0, berlin).Unix(), Interval: 1, Unit: "month", Rule: "date", Location: berlin},
+			want:     []time.Time{time.Date(2023, 2, 1, 0, 0, 0, 0, berlin), time.Date(2023, 3, 1, 0, 0, 0, 0, berlin), time.Date(2023, 4, 1, 0, 0, 0, 0, berlin), time.Date(2023, 5, 1, 0, 0, 0, 0, berlin)},
+		},
+		{
+			name:     "Last day of the month at midnight",
+			schedule: Schedule{Start: time.Date(2023, 3, 1, 0, 0, 0, 0, berlin).Unix(), Interval: 1, Unit: "month", Rule: "lastDay", Location: berlin},
+			want:     []time.Time{time.Date(2023, 3, 31, 0, 0, 0, 0, berlin), time.Date(2023, 4, 30, 0, 0, 0, 0, berlin)},
+		},
+		{
+			name:     "Daily in the skipped hour",
+			schedule: Schedule{Start: time.Date(2023, 3, 25, 2, 30, 0, 0, berlin).Unix(), Interval: 1, Unit: "day", Location: berlin},
+			want:     []time.Time{time.Date(2023, 3, 25, 2, 30, 0, 0, berlin), time.Date(2023, 3, 26, 3, 30, 0, 0, berlin), time.Date(2023, 3, 27, 2, 30, 0, 0, berlin)},
+		},
+		{
+			name:     "Daily after the repeated hour",
+			schedule: Schedule{Start: time.Date(2023, 10, 28, 8, 0, 0, 0, berlin).Unix(), Interval: 1, Unit: "day", Location: berlin},
+			want:     []time.Time{time.Date(2023, 10, 28, 8, 0, 0, 0, berlin), time.Date(2023, 10, 29, 8, 0, 0, 0, berlin), time.Date(2023, 10, 30, 8, 0, 0, 0, berlin)},
+		},
+		{
+			name:     "Weekly across DST",
+			schedule: Schedule{Start: time.Date(2023, 3, 6, 9, 0, 0, 0, newYork).Unix(), Interval: 1, Unit: "week", Location: newYork},
+			want:     []time.Time{time.Date(2023, 3, 6, 9, 0, 0, 0, newYork), time.Date(2023, 3, 13, 9, 0, 0, 0, newYork), time.Date(2023, 3, 20, 9, 0, 0, 0, newYork)},
+		},
+		{
+			name:     "Weekdays in the local time zone",
+			schedule: Schedule{Start: time.Date(2023, 4, 1, 0, 30, 0, 0, berlin).Unix(), Interval: 1, Unit: "month", Rule: "date", WeekdaysOnly: true, Location: berlin},
+			want:     []time.Time{time.Date(2023, 4, 3, 0, 30, 0, 0, berlin), time.Date(2023, 5, 1, 0, 30, 0, 0, berlin)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				assert.Equal(t, want.Format(time.RFC3339), time.Unix(tt.schedule.Occurrence(i), 0).In(tt.schedule.Location).Format(time.RFC3339), "occurrence %d", i)
+			}
+		})
+	}
+}
+
+func TestLoadTimeZone(t *testing.T) {
+	_, err := LoadTimeZone("Europe/Berlin")
+	assert.NoError(t, err)
+	_, err = LoadTimeZone("Local")
+	assert.Error(t, err)
+	_, err = LoadTimeZone("")
+	assert.Error(t, err)
+	_, err = LoadTimeZone("Mars/Olympus_Mons")
+	assert.Error(t, err)
+}